@@ -0,0 +1,254 @@
+package notion
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// ANSI SGR codes used by PrintAsANSI.
+const (
+	ansiBold      = "1"
+	ansiDim       = "2"
+	ansiItalic    = "3"
+	ansiUnderline = "4"
+	ansiStrike    = "9"
+	ansiReset     = "\x1b[0m"
+)
+
+// ansiColors maps notion colors to ANSI foreground and background codes.
+var ansiColors = map[string][2]string{
+	"gray":   {"90", "100"},
+	"brown":  {"33", "43"},
+	"orange": {"33", "43"},
+	"yellow": {"93", "103"},
+	"teal":   {"32", "42"},
+	"green":  {"32", "42"},
+	"blue":   {"34", "44"},
+	"purple": {"35", "45"},
+	"pink":   {"95", "105"},
+	"red":    {"31", "41"},
+}
+
+// ansiColor returns codes for a notion color like "red" or
+// "red_background".
+func ansiColor(color string) []string {
+	if c, ok := ansiColors[strings.TrimSuffix(color, "_background")]; ok {
+		if strings.HasSuffix(color, "_background") {
+			return []string{c[1]}
+		}
+		return []string{c[0]}
+	}
+	return nil
+}
+
+// headerStyles are the styles of headers of level 1 to 3.
+var headerStyles = [][]string{
+	{ansiBold, "35"},
+	{ansiBold, "34"},
+	{ansiBold, "36"},
+}
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// textWidth returns the number of runes of s, excluding ANSI escapes.
+func textWidth(s string) int {
+	if strings.IndexByte(s, '\x1b') >= 0 {
+		s = ansiEscape.ReplaceAllString(s, "")
+	}
+	return utf8.RuneCountInString(s)
+}
+
+// style wraps s in ANSI escapes for codes if ANSI output is enabled. Each run
+// of non-space characters is styled separately, so that wrapping text and
+// prefixing lines doesn't spread styles.
+func (p *textPrinter) style(s string, codes ...string) string {
+	if !p.ansi || len(codes) == 0 || s == "" {
+		return s
+	}
+	start := "\x1b[" + strings.Join(codes, ";") + "m"
+	var sb strings.Builder
+	word := false
+	for _, r := range s {
+		space := unicode.IsSpace(r)
+		if space && word {
+			sb.WriteString(ansiReset)
+		} else if !space && !word {
+			sb.WriteString(start)
+		}
+		word = !space
+		sb.WriteRune(r)
+	}
+	if word {
+		sb.WriteString(ansiReset)
+	}
+	return sb.String()
+}
+
+// blockStyle returns the styles of the text of a block.
+func blockStyle(b *notiontypes.Block) []string {
+	var codes []string
+	if l := b.HeaderLevel(); l > 0 {
+		codes = append(codes, headerStyles[l-1]...)
+	}
+	return append(codes, ansiColor(blockColor(b))...)
+}
+
+// inlineStyle returns the styles of an inline block.
+func inlineStyle(b *notiontypes.InlineBlock) []string {
+	var codes []string
+	add := func(attr notiontypes.AttrFlag, code string) {
+		if b.AttrFlags&attr != 0 {
+			codes = append(codes, code)
+		}
+	}
+	add(notiontypes.AttrBold, ansiBold)
+	add(notiontypes.AttrItalic, ansiItalic)
+	add(notiontypes.AttrStrikeThrought, ansiStrike)
+	add(notiontypes.AttrUnderline, ansiUnderline)
+	if b.AttrFlags&notiontypes.AttrCode != 0 {
+		codes = append(codes, "36")
+	}
+	if b.Link != "" || b.IsPageMention() {
+		codes = append(codes, ansiUnderline, "34")
+	}
+	if b.UserID != "" {
+		codes = append(codes, ansiBold)
+	}
+	if b.Color != "" {
+		codes = append(codes, ansiColor(b.Color)...)
+	}
+	if b.BackgroundColor != "" {
+		codes = append(codes, ansiColor(b.BackgroundColor+"_background")...)
+	}
+	return codes
+}
+
+// codeSyntax describes the lexical syntax of a programming language, enough
+// for highlighting keywords, strings, numbers and comments.
+type codeSyntax struct {
+	keywords     string
+	lineComment  string
+	blockComment bool
+	backquotes   bool
+}
+
+var cKeywords = "auto break case char const continue default do double else enum extern float for goto if inline int long register return short signed sizeof static struct switch typedef union unsigned void volatile while"
+
+var codeSyntaxes = map[string]*codeSyntax{
+	"go": {
+		keywords:    "break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var nil true false",
+		lineComment: "//", blockComment: true, backquotes: true,
+	},
+	"python": {
+		keywords:    "and as assert async await break class continue def del elif else except finally for from global if import in is lambda nonlocal not or pass raise return try while with yield None True False",
+		lineComment: "#",
+	},
+	"javascript": {
+		keywords:    "async await break case catch class const continue debugger default delete do else export extends finally for function if import in instanceof let new of return super switch this throw try typeof var void while with yield null undefined true false",
+		lineComment: "//", blockComment: true, backquotes: true,
+	},
+	"typescript": {
+		keywords:    "abstract any as async await boolean break case catch class const continue declare default do else enum export extends finally for from function if implements import in instanceof interface let new number of private protected public readonly return string super switch this throw try type typeof var void while null undefined true false",
+		lineComment: "//", blockComment: true, backquotes: true,
+	},
+	"rust": {
+		keywords:    "as async await break const continue crate else enum extern fn for if impl in let loop match mod move mut pub ref return self Self static struct super trait type unsafe use where while true false",
+		lineComment: "//", blockComment: true,
+	},
+	"java": {
+		keywords:    "abstract boolean break byte case catch char class const continue default do double else enum extends final finally float for if implements import instanceof int interface long new package private protected public return short static super switch this throw throws try void volatile while null true false",
+		lineComment: "//", blockComment: true,
+	},
+	"c":   {keywords: cKeywords, lineComment: "//", blockComment: true},
+	"c++": {keywords: cKeywords + " bool catch class delete namespace new nullptr private protected public template this throw try using virtual true false", lineComment: "//", blockComment: true},
+	"c#":  {keywords: cKeywords + " bool catch class namespace new null private protected public string this throw try using var virtual true false", lineComment: "//", blockComment: true},
+	"ruby": {
+		keywords:    "alias and begin break case class def defined do else elsif end ensure false for if in module next nil not or redo rescue retry return self super then true undef unless until when while yield",
+		lineComment: "#",
+	},
+	"shell": {
+		keywords:    "case do done elif else esac export fi for function if in local return then until while",
+		lineComment: "#",
+	},
+	"sql": {
+		keywords:    "select from where and or not insert into values update set delete create table drop alter join left right inner outer on group by order having limit as null is in like distinct union SELECT FROM WHERE AND OR NOT INSERT INTO VALUES UPDATE SET DELETE CREATE TABLE DROP ALTER JOIN LEFT RIGHT INNER OUTER ON GROUP BY ORDER HAVING LIMIT AS NULL IS IN LIKE DISTINCT UNION",
+		lineComment: "--",
+	},
+}
+
+func init() {
+	codeSyntaxes["bash"] = codeSyntaxes["shell"]
+	codeSyntaxes["jsx"] = codeSyntaxes["javascript"]
+	codeSyntaxes["tsx"] = codeSyntaxes["typescript"]
+	codeSyntaxes["cpp"] = codeSyntaxes["c++"]
+	codeSyntaxes["csharp"] = codeSyntaxes["c#"]
+}
+
+// tokenizer returns a regexp matching comments, strings, numbers and words,
+// in submatches 1 to 4.
+func (s *codeSyntax) tokenizer() *regexp.Regexp {
+	var comment []string
+	if s.lineComment != "" {
+		comment = append(comment, regexp.QuoteMeta(s.lineComment)+`[^\n]*`)
+	}
+	if s.blockComment {
+		comment = append(comment, `/\*[\s\S]*?(?:\*/|$)`)
+	}
+	if len(comment) == 0 {
+		comment = append(comment, `$^`)
+	}
+	str := `"(?:[^"\\\n]|\\.)*"?|'(?:[^'\\\n]|\\.)*'?`
+	if s.backquotes {
+		str += "|`[^`]*`?"
+	}
+	return regexp.MustCompile(`(` + strings.Join(comment, "|") + `)|(` + str + `)|(\b\d[\w.]*)|([\pL_][\pL\pN_]*)`)
+}
+
+// highlight returns code with keywords, strings, numbers and comments
+// styled, according to the syntax of language. Code of unknown languages is
+// returned unchanged.
+func (p *textPrinter) highlight(code string, language string) string {
+	syntax := codeSyntaxes[strings.ToLower(language)]
+	if !p.ansi || syntax == nil {
+		return code
+	}
+	keywords := map[string]bool{}
+	for _, k := range strings.Fields(syntax.keywords) {
+		keywords[k] = true
+	}
+	var buf bytes.Buffer
+	last := 0
+	for _, m := range syntax.tokenizer().FindAllStringSubmatchIndex(code, -1) {
+		buf.WriteString(code[last:m[0]])
+		tok := code[m[0]:m[1]]
+		switch {
+		case m[2] >= 0:
+			tok = p.style(tok, ansiItalic, "90")
+		case m[4] >= 0:
+			tok = p.style(tok, "32")
+		case m[6] >= 0:
+			tok = p.style(tok, "33")
+		case keywords[tok]:
+			tok = p.style(tok, "35")
+		}
+		buf.WriteString(tok)
+		last = m[1]
+	}
+	buf.WriteString(code[last:])
+	return buf.String()
+}
+
+// PrintAsANSI renders a notion page as text for terminals, with ANSI escapes
+// for text styles, colors of blocks and text, and syntax highlighting of
+// code. Callouts and quotes are drawn with a gutter. Otherwise it is like
+// PrintAsText.
+func PrintAsANSI(page *notiontypes.Block, opts ...PrintOption) ([]byte, error) {
+	p := &textPrinter{buf: new(bytes.Buffer), cfg: newPrintConfig(opts), root: page, ansi: true}
+	p.printPage(page)
+	return p.buf.Bytes(), nil
+}
@@ -0,0 +1,66 @@
+package notion
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestPrintAsANSI(t *testing.T) {
+	page := testPage(t, `[
+	{"id":"p","type":"page","properties":{"title":[["Notes"]]},"content":["h","t","q","c"]},
+	{"id":"h","type":"header","properties":{"title":[["Intro"]]}},
+	{"id":"t","type":"text","properties":{"title":[["some "],["bold",[["b"]]],[" words that wrap around"]]},"format":{"block_color":"red"}},
+	{"id":"q","type":"quote","properties":{"title":[["quoted"]]}},
+	{"id":"c","type":"code","properties":{"title":[["return \"x\" // done"]],"language":[["Go"]]}}
+	]`)
+	got, err := PrintAsANSI(page.Block, WithWidth(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"\x1b[1;35mIntro\x1b[0m",
+		"\x1b[31;1mbold\x1b[0m",
+		"\x1b[2m│\x1b[0m quoted",
+		"\x1b[35mreturn\x1b[0m \x1b[32m\"x\"\x1b[0m \x1b[3;90m//\x1b[0m \x1b[3;90mdone\x1b[0m",
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("output doesn't contain %q:\n%q", want, got)
+		}
+	}
+
+	// without escapes, lines are wrapped like plain text
+	plain, _ := PrintAsText(page.Block, WithWidth(20))
+	stripped := ansiEscape.ReplaceAllString(string(got), "")
+	stripped = strings.Replace(stripped, "│", ">", -1)
+	if stripped != string(plain) {
+		t.Errorf("stripped output:\n%s\nwant:\n%s", stripped, plain)
+	}
+}
+
+func TestPrintAsANSIStripsControls(t *testing.T) {
+	page := testPage(t, `[
+	{"id":"p","type":"page","properties":{"title":[["Notes\u001b]0;title\u0007"]]},"content":["t","c","b"]},
+	{"id":"t","type":"text","properties":{"title":[["clear\u001b[2J\r"],["link",[["a","https://example.com/\u001b[8m"]]]]}},
+	{"id":"c","type":"code","properties":{"title":[["x := 1\u009b31m\n\ty++"]],"language":[["Go"]]}},
+	{"id":"b","type":"bookmark","properties":{"link":[["https://example.com/\u001bP"]]}}
+	]`)
+	for name, print := range map[string]func(*notiontypes.Block, ...PrintOption) ([]byte, error){
+		"text": PrintAsText,
+		"ansi": PrintAsANSI,
+	} {
+		got, err := print(page.Block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := ansiEscape.ReplaceAllString(string(got), "")
+		if i := strings.IndexFunc(s, func(r rune) bool { return r != '\n' && r != '\t' && unicode.IsControl(r) }); i >= 0 {
+			t.Errorf("%s: control character %q in:\n%q", name, s[i], got)
+		}
+		if !strings.Contains(s, "\ty++") {
+			t.Errorf("%s: tab of code removed:\n%q", name, got)
+		}
+	}
+}
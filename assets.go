@@ -0,0 +1,306 @@
+package notion
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// FileRef refers to a file stored by notion.so and the block it belongs to.
+// The block is needed to check permissions to the file.
+type FileRef struct {
+	URL     string
+	BlockID string
+}
+
+type signedFileURLRequest struct {
+	URL              string `json:"url"`
+	PermissionRecord Record `json:"permissionRecord"`
+}
+
+type getSignedFileURLsRequest struct {
+	URLs []signedFileURLRequest `json:"urls"`
+}
+
+type getSignedFileURLsResponse struct {
+	SignedURLs []string `json:"signedUrls"`
+}
+
+// GetSignedFileURLs returns temporary, publicly accessible urls for files
+// stored by notion.so, in the same order as files.
+func (c *Client) GetSignedFileURLs(files ...FileRef) ([]string, error) {
+	req := getSignedFileURLsRequest{}
+	for _, f := range files {
+		req.URLs = append(req.URLs, signedFileURLRequest{
+			URL:              f.URL,
+			PermissionRecord: Record{Table: notiontypes.TableBlock, ID: f.BlockID},
+		})
+	}
+	b, err := c.post(req, "getSignedFileUrls")
+	if err != nil {
+		return nil, err
+	}
+	r := &getSignedFileURLsResponse{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling getSignedFileUrlsResponse")
+	}
+	if len(r.SignedURLs) != len(files) {
+		return nil, fmt.Errorf("notion: got %d signed urls for %d files", len(r.SignedURLs), len(files))
+	}
+	return r.SignedURLs, nil
+}
+
+// isNotionFile returns true if u refers to a private file uploaded to notion.so.
+func isNotionFile(u string) bool {
+	return strings.Contains(u, "secure.notion-static.com") ||
+		strings.Contains(u, "prod-files-secure")
+}
+
+// AssetDownloader downloads images, files, page covers and icons referred to
+// by pages so they can be viewed offline. Files are stored in Dir under names
+// derived from their content.
+//
+// Use it with WithAssetURLs(d.URL) to render pages that refer to the
+// downloaded files.
+type AssetDownloader struct {
+	// Dir is the directory files are stored in.
+	Dir string
+	// Prefix is prepended to file names to build urls returned by URL,
+	// e.g. "assets/" for files referred to from the parent of Dir.
+	Prefix string
+	// Concurrency is the number of concurrent downloads, 4 if not set.
+	Concurrency int
+
+	client *Client
+	mu     sync.Mutex
+	local  map[string]string
+}
+
+// NewAssetDownloader initializes a new AssetDownloader that stores files in dir.
+func NewAssetDownloader(c *Client, dir string) *AssetDownloader {
+	return &AssetDownloader{
+		Dir:    dir,
+		client: c,
+		local:  make(map[string]string),
+	}
+}
+
+// URL returns the local url of a downloaded asset, or u if it was not downloaded.
+func (d *AssetDownloader) URL(u string) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if l, ok := d.local[u]; ok {
+		return l
+	}
+	return u
+}
+
+// Files returns the names of downloaded files by original url.
+func (d *AssetDownloader) Files() map[string]string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	files := make(map[string]string, len(d.local))
+	for k, v := range d.local {
+		files[k] = strings.TrimPrefix(v, d.Prefix)
+	}
+	return files
+}
+
+// add records name as the file of the asset u, which is already in Dir.
+func (d *AssetDownloader) add(u string, name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.local[u] = d.Prefix + name
+}
+
+// assetRefs returns assets referred to by blocks and their descendants.
+func assetRefs(blocks ...*notiontypes.Block) []FileRef {
+	var refs []FileRef
+	add := func(u string, b *notiontypes.Block) {
+		if u != "" {
+			refs = append(refs, FileRef{URL: u, BlockID: b.ID})
+		}
+	}
+	visit := func(b *notiontypes.Block) bool {
+		switch b.Type {
+		case notiontypes.BlockImage:
+			add(b.Source, b)
+		case notiontypes.BlockFile, notiontypes.BlockPDF, notiontypes.BlockAudio, notiontypes.BlockVideo:
+			// other sources are embedded from external sites, like youtube
+			if isNotionFile(b.Source) {
+				add(b.Source, b)
+			}
+		}
+		if b.FormatPage != nil {
+			add(b.FormatPage.PageCover, b)
+		}
+		if icon := pageIcon(b); isURLIcon(icon) {
+			add(icon, b)
+		}
+		return true
+	}
+	for _, b := range blocks {
+		notiontypes.InspectSynced(b, visit)
+	}
+	return refs
+}
+
+// Download downloads assets of blocks and their descendants. Assets that were
+// already downloaded are skipped. If some downloads fail, the others are still
+// completed and the first error is returned.
+func (d *AssetDownloader) Download(blocks ...*notiontypes.Block) error {
+	if err := os.MkdirAll(d.Dir, 0755); err != nil {
+		return err
+	}
+	var todo, private []FileRef
+	seen := map[string]bool{}
+	d.mu.Lock()
+	for _, ref := range assetRefs(blocks...) {
+		if _, ok := d.local[ref.URL]; ok || seen[ref.URL] {
+			continue
+		}
+		seen[ref.URL] = true
+		if isNotionFile(ref.URL) {
+			private = append(private, ref)
+		} else {
+			todo = append(todo, ref)
+		}
+	}
+	d.mu.Unlock()
+
+	// download urls by original url
+	sources := make(map[string]string, len(todo)+len(private))
+	for _, ref := range todo {
+		u := ref.URL
+		if strings.HasPrefix(u, "/") {
+			u = "https://www.notion.so" + u
+		}
+		sources[ref.URL] = u
+	}
+	if len(private) > 0 {
+		signed, err := d.client.GetSignedFileURLs(private...)
+		if err != nil {
+			return errors.Wrap(err, "signing file urls")
+		}
+		for i, ref := range private {
+			sources[ref.URL] = signed[i]
+		}
+	}
+
+	concurrency := d.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	type job struct{ orig, src string }
+	jobs := make(chan job)
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+	)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				name, err := d.download(j.src)
+				if err != nil {
+					d.client.logger.WithField("url", j.orig).WithError(err).Warnln("asset download failed")
+					errMu.Lock()
+					if firstErr == nil {
+						firstErr = errors.Wrapf(err, "downloading %v", j.orig)
+					}
+					errMu.Unlock()
+					continue
+				}
+				d.mu.Lock()
+				d.local[j.orig] = d.Prefix + name
+				d.mu.Unlock()
+			}
+		}()
+	}
+	for orig, src := range sources {
+		jobs <- job{orig, src}
+	}
+	close(jobs)
+	wg.Wait()
+	return firstErr
+}
+
+// isNotionURL reports whether u is an https url of notion.so or one of its
+// subdomains, which may receive the session token.
+func isNotionURL(u *url.URL) bool {
+	host := u.Hostname()
+	return u.Scheme == "https" && (host == "notion.so" || strings.HasSuffix(host, ".notion.so"))
+}
+
+// download fetches u into Dir and returns the name of the created file.
+func (d *AssetDownloader) download(u string) (string, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return "", err
+	}
+	if pu, err := url.Parse(u); err == nil && isNotionURL(pu) {
+		req.Header.Set("cookie", fmt.Sprintf("token=%v", d.client.token))
+	}
+	resp, err := d.client.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("notion: %v %v", resp.StatusCode, u)
+	}
+
+	tmp, err := ioutil.TempFile(d.Dir, ".download-")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), resp.Body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+	name := hex.EncodeToString(h.Sum(nil))[:16] + assetExt(u, resp.Header.Get("Content-Type"))
+	if err := os.Rename(tmp.Name(), filepath.Join(d.Dir, name)); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// assetExt returns the file extension for an asset, based on its url
+// or its content type.
+func assetExt(u string, contentType string) string {
+	if pu, err := url.Parse(u); err == nil {
+		p := pu.Path
+		// images proxied by notion.so have the original url in the path
+		if unescaped, err := url.PathUnescape(p); err == nil {
+			p = unescaped
+		}
+		if ext := path.Ext(p); ext != "" && len(ext) <= 6 {
+			return strings.ToLower(ext)
+		}
+	}
+	if exts, err := mime.ExtensionsByType(contentType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
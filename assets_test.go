@@ -0,0 +1,82 @@
+package notion
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAssetDownloader(t *testing.T) {
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("content of " + r.URL.Path))
+	}))
+	defer files.Close()
+	private := "https://s3-us-west-2.amazonaws.com/secure.notion-static.com/abc/report.pdf"
+	c, requests := newTestClient(t, map[string]string{
+		"getSignedFileUrls": `{"signedUrls":["` + files.URL + `/signed/report.pdf?sig=1"]}`,
+	})
+	page := testPage(t, `[
+	{"id":"p","type":"page","properties":{"title":[["Assets"]]},"content":["i","f"],"format":{"page_icon":"`+files.URL+`/icon.png"}},
+	{"id":"i","type":"image","properties":{"source":[["`+files.URL+`/photo.JPG"]]}},
+	{"id":"f","type":"pdf","properties":{"source":[["`+private+`"]]}}
+	]`)
+
+	dir := t.TempDir()
+	d := NewAssetDownloader(c, dir)
+	d.Prefix = "assets/"
+	if err := d.Download(page.Block); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(requests["getSignedFileUrls"][0], `"id":"f"`) {
+		t.Errorf("signed url request lacks permission record: %s", requests["getSignedFileUrls"][0])
+	}
+	for _, u := range []string{files.URL + "/icon.png", files.URL + "/photo.JPG", private} {
+		local := d.URL(u)
+		if !strings.HasPrefix(local, "assets/") {
+			t.Fatalf("%s was not downloaded", u)
+		}
+		if _, err := ioutil.ReadFile(filepath.Join(dir, strings.TrimPrefix(local, "assets/"))); err != nil {
+			t.Error(err)
+		}
+	}
+	if ext := filepath.Ext(d.URL(files.URL + "/photo.JPG")); ext != ".jpg" {
+		t.Errorf("unexpected extension %q", ext)
+	}
+	html, _ := PrintAsHTML(page.Block, WithAssetURLs(d.URL))
+	if !strings.Contains(string(html), `src="`+d.URL(private)+`"`) {
+		t.Errorf("pdf url not rewritten:\n%s", html)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestAssetDownloaderCookie(t *testing.T) {
+	cookies := map[string]string{}
+	c, err := NewClient(WithToken("secret"), WithHTTPClient(&http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		cookies[r.URL.String()] = r.Header.Get("cookie")
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("image")), Header: http.Header{}}, nil
+	})}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewAssetDownloader(c, t.TempDir())
+	for u, want := range map[string]string{
+		"https://www.notion.so/image.png":  "token=secret",
+		"https://notion.so/image.png":      "token=secret",
+		"http://www.notion.so/image.png":   "",
+		"https://evilnotion.so/image.png":  "",
+		"https://notion.so.evil/image.png": "",
+	} {
+		if _, err := d.download(u); err != nil {
+			t.Fatal(err)
+		}
+		if got := cookies[u]; got != want {
+			t.Errorf("cookie for %s = %q, want %q", u, got, want)
+		}
+	}
+}
@@ -0,0 +1,567 @@
+package notion
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// backupFormat identifies archives written by BackupWriter.
+const backupFormat = "notion-backup"
+
+// BackupVersion is the version of the archive format written by
+// BackupWriter. OpenBackup reads archives of this version and older ones.
+const BackupVersion = 1
+
+// BackupManifest describes a backup archive. It is stored as manifest.json,
+// the first file of the archive. Records are stored unchanged as returned by
+// notion.so, as records/<table>/<id>.json, and assets as assets/<name>.
+type BackupManifest struct {
+	// Format is always "notion-backup".
+	Format  string    `json:"format"`
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	// Previous is the creation time of the backup this backup was made
+	// incrementally to, if any.
+	Previous *time.Time `json:"previous,omitempty"`
+	// Spaces that were backed up.
+	Spaces []*BackupSpace `json:"spaces"`
+	// Pages that were backed up, including rows of databases, by id.
+	Pages map[string]*BackupPage `json:"pages"`
+	// Assets are names of downloaded files in assets/, by url.
+	Assets map[string]string `json:"assets"`
+}
+
+// BackupSpace is a space in a backup.
+type BackupSpace struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Pages are the ids of top-level pages of the space.
+	Pages []string `json:"pages"`
+}
+
+// BackupPage is a page in a backup.
+type BackupPage struct {
+	Title          string `json:"title"`
+	LastEditedTime int64  `json:"last_edited_time"`
+	// Children are ids of sub-pages and rows of databases in the page.
+	Children []string `json:"children,omitempty"`
+	// Records of the page, its blocks, databases, discussions and users, as
+	// "<table>/<id>".
+	Records []string `json:"records"`
+	// Assets are urls of files of the page in Assets of the manifest.
+	Assets []string `json:"assets,omitempty"`
+	// Collections is set for pages that show databases, their rows can change
+	// without changing the page.
+	Collections bool `json:"collections,omitempty"`
+}
+
+// rawRecordMap is a record map with unparsed values, by table and id.
+type rawRecordMap map[string]map[string]*recordValue
+
+// BackupWriter writes backups of all spaces of the authenticated user: the
+// records of all pages, databases and their rows, discussions and users,
+// and optionally files uploaded to notion.so and images. Backups are zip
+// archives described by a BackupManifest, which can be restored with
+// Client.Restore.
+type BackupWriter struct {
+	// Previous is an earlier backup. Pages that were not edited or commented
+	// on since are copied from it instead of being loaded again.
+	Previous *BackupReader
+	// Assets downloads files into the backup, if set. Files of the previous
+	// backup are extracted into its directory.
+	Assets *AssetDownloader
+	// OnPage, if set, is called after each page is loaded or copied.
+	OnPage func(t *PageTree)
+
+	client *Client
+	// records by "<table>/<id>"
+	records map[string]json.RawMessage
+	// keys of records by page id
+	pageRecords map[string][]string
+	// pages copied from Previous
+	cached map[string]bool
+	// edit times of pages in the previous backup, as currently on notion.so
+	editTimes map[string]int64
+	// pages of the previous backup with new discussions or comments
+	discussed map[string]bool
+}
+
+// NewBackupWriter initializes a new BackupWriter that loads records using c.
+func NewBackupWriter(c *Client) *BackupWriter {
+	return &BackupWriter{client: c}
+}
+
+// Write writes a backup to w and returns its manifest.
+func (bw *BackupWriter) Write(w io.Writer) (*BackupManifest, error) {
+	bw.records = map[string]json.RawMessage{}
+	bw.pageRecords = map[string][]string{}
+	bw.cached = map[string]bool{}
+	m := &BackupManifest{
+		Format:  backupFormat,
+		Version: BackupVersion,
+		Created: time.Now().UTC().Truncate(time.Second),
+		Pages:   map[string]*BackupPage{},
+		Assets:  map[string]string{},
+	}
+	spaces, err := bw.loadSpaces()
+	if err != nil {
+		return nil, err
+	}
+	m.Spaces = spaces
+	if bw.Previous != nil {
+		prev := bw.Previous.Manifest.Created
+		m.Previous = &prev
+		if err := bw.loadEditTimes(); err != nil {
+			return nil, err
+		}
+		if err := bw.extractAssets(); err != nil {
+			return nil, err
+		}
+	}
+
+	crawler := NewCrawler(bw.client)
+	crawler.Rows = true
+	crawler.LoadPage = bw.loadPage
+	crawler.OnPage = bw.OnPage
+	for _, s := range spaces {
+		for _, id := range s.Pages {
+			tree, err := crawler.Crawl(id)
+			if err != nil {
+				return nil, err
+			}
+			for _, t := range tree.Pages() {
+				p, err := bw.backupPage(t)
+				if err != nil {
+					return nil, err
+				}
+				m.Pages[t.Page.ID] = p
+			}
+		}
+	}
+	if bw.Assets != nil {
+		files := bw.Assets.Files()
+		for _, p := range m.Pages {
+			for _, u := range p.Assets {
+				m.Assets[u] = files[u]
+			}
+		}
+	}
+	return m, bw.writeArchive(w, m)
+}
+
+// loadSpaces stores records of the spaces and users from loadUserContent.
+func (bw *BackupWriter) loadSpaces() ([]*BackupSpace, error) {
+	b, err := bw.client.post(struct{}{}, "loadUserContent")
+	if err != nil {
+		return nil, err
+	}
+	var r struct {
+		RecordMap rawRecordMap `json:"recordMap"`
+	}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling loadUserContentResponse")
+	}
+	bw.addRecords(rawRecordMap{
+		notiontypes.TableSpace: r.RecordMap[notiontypes.TableSpace],
+		tableUser:              r.RecordMap[tableUser],
+	})
+	var spaces []*BackupSpace
+	for _, v := range r.RecordMap[notiontypes.TableSpace] {
+		if v == nil || len(v.Value) == 0 || string(v.Value) == "null" {
+			continue
+		}
+		s := &notiontypes.Space{}
+		if err := json.Unmarshal(v.Value, s); err != nil {
+			return nil, errors.Wrap(err, "unmarshaling space")
+		}
+		spaces = append(spaces, &BackupSpace{ID: s.ID, Name: s.Name, Pages: s.Pages})
+	}
+	sort.Slice(spaces, func(i, j int) bool { return spaces[i].Name < spaces[j].Name })
+	return spaces, nil
+}
+
+// addRecords stores records and returns their keys.
+func (bw *BackupWriter) addRecords(rm rawRecordMap) []string {
+	var keys []string
+	for table, records := range rm {
+		for id, v := range records {
+			if v == nil || len(v.Value) == 0 || string(v.Value) == "null" {
+				continue
+			}
+			key := table + "/" + id
+			bw.records[key] = v.Value
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// backupRefs holds the fields of block and discussion records that change
+// when comments are added, which doesn't change the edit time of pages.
+type backupRefs struct {
+	ID            string   `json:"id"`
+	DiscussionIDs []string `json:"discussion"`
+	CommentIDs    []string `json:"comments"`
+}
+
+// loadEditTimes fetches the current edit times of pages of the previous
+// backup, and finds pages that weren't edited but got new discussions or
+// comments.
+func (bw *BackupWriter) loadEditTimes() error {
+	bw.discussed = map[string]bool{}
+	var ids []string
+	for id := range bw.Previous.Manifest.Pages {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	editTimes, err := bw.client.LastEditedTimes(ids)
+	if err != nil {
+		return err
+	}
+	bw.editTimes = editTimes
+
+	// blocks and discussions of pages that weren't edited, by table
+	unedited := map[string][]string{}
+	pageOf := map[string]string{}
+	for _, id := range ids {
+		p := bw.Previous.Manifest.Pages[id]
+		if t, ok := bw.editTimes[id]; !ok || t != p.LastEditedTime || p.Collections {
+			continue
+		}
+		for _, key := range p.Records {
+			if table := path.Dir(key); table == notiontypes.TableBlock || table == tableDiscussion {
+				unedited[table] = append(unedited[table], path.Base(key))
+				pageOf[key] = id
+			}
+		}
+	}
+	for _, table := range []string{notiontypes.TableBlock, tableDiscussion} {
+		err := bw.client.getRecordsOf(table, unedited[table], func(v json.RawMessage) error {
+			var cur, prev backupRefs
+			if err := json.Unmarshal(v, &cur); err != nil {
+				return err
+			}
+			key := table + "/" + cur.ID
+			b, err := bw.Previous.read("records/" + key + ".json")
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal(b, &prev); err != nil {
+				return err
+			}
+			if !reflect.DeepEqual(cur.DiscussionIDs, prev.DiscussionIDs) || !reflect.DeepEqual(cur.CommentIDs, prev.CommentIDs) {
+				bw.discussed[pageOf[key]] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractAssets copies files of the previous backup into the directory of
+// Assets, so that they are not downloaded again.
+func (bw *BackupWriter) extractAssets() error {
+	if bw.Assets == nil || len(bw.Previous.Manifest.Assets) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(bw.Assets.Dir, 0755); err != nil {
+		return err
+	}
+	for u, name := range bw.Previous.Manifest.Assets {
+		if !validAssetName(name) {
+			return errors.Errorf("notion: invalid asset name %q in backup", name)
+		}
+		content, err := bw.Previous.read("assets/" + name)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(bw.Assets.Dir, name), content, 0644); err != nil {
+			return err
+		}
+		bw.Assets.add(u, name)
+	}
+	return nil
+}
+
+// validAssetName returns true if name is a file name without directories,
+// as written by AssetDownloader, and can be joined to a directory safely.
+func validAssetName(name string) bool {
+	return name != "" && name != "." && !strings.Contains(name, "..") && !strings.ContainsAny(name, `/\`)
+}
+
+// loadPage returns a stub of a page from the previous backup if the page
+// wasn't edited or commented on since, and loads the page from notion.so
+// otherwise.
+func (bw *BackupWriter) loadPage(id string) (*Page, error) {
+	if bw.Previous != nil {
+		p, ok := bw.Previous.Manifest.Pages[id]
+		if t, edited := bw.editTimes[id]; ok && edited && t == p.LastEditedTime && !p.Collections && !bw.discussed[id] {
+			for _, key := range p.Records {
+				v, err := bw.Previous.read("records/" + key + ".json")
+				if err != nil {
+					return nil, err
+				}
+				bw.records[key] = v
+			}
+			bw.cached[id] = true
+			block := &notiontypes.Block{ID: id, Type: notiontypes.BlockPage, Title: p.Title, LastEditedTime: t}
+			for _, child := range p.Children {
+				block.Content = append(block.Content, &notiontypes.Block{ID: child, Type: notiontypes.BlockPage, ParentID: id})
+			}
+			return &Page{Block: block}, nil
+		}
+	}
+
+	chunks, err := bw.client.loadPageChunks(id)
+	if err != nil {
+		return nil, err
+	}
+	page, err := bw.client.parsePageChunks(id, chunks)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, b := range chunks {
+		var r struct {
+			RecordMap rawRecordMap `json:"recordMap"`
+		}
+		if err := json.Unmarshal(b, &r); err != nil {
+			return nil, errors.Wrap(err, "unmarshaling loadPageChunkResponse")
+		}
+		keys = append(keys, bw.addRecords(r.RecordMap)...)
+	}
+	missing, err := bw.loadMissingRecords(page)
+	if err != nil {
+		return nil, err
+	}
+	bw.pageRecords[id] = append(keys, missing...)
+	return page, nil
+}
+
+// loadMissingRecords stores records that a page refers to but that were not
+// part of its chunks, like discussions and databases loaded separately, and
+// returns their keys.
+func (bw *BackupWriter) loadMissingRecords(page *Page) ([]string, error) {
+	ids := map[string][]string{}
+	need := func(table string, id string) {
+		if _, ok := bw.records[table+"/"+id]; !ok && id != "" {
+			ids[table] = append(ids[table], id)
+		}
+	}
+	notiontypes.Inspect(page.Block, func(b *notiontypes.Block) bool {
+		need(notiontypes.TableCollection, b.CollectionID)
+		for _, id := range b.ViewIDs {
+			need(notiontypes.TableCollectionView, id)
+		}
+		for _, id := range b.DiscussionIDs {
+			need(tableDiscussion, id)
+		}
+		for _, d := range b.Discussions {
+			for _, id := range d.CommentIDs {
+				need(tableComment, id)
+			}
+		}
+		return true
+	})
+	for id := range page.Users {
+		need(tableUser, id)
+	}
+	var keys []string
+	for table, list := range ids {
+		err := bw.client.getRecordsOf(table, list, func(v json.RawMessage) error {
+			var r struct {
+				ID string `json:"id"`
+			}
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			bw.records[table+"/"+r.ID] = v
+			keys = append(keys, table+"/"+r.ID)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// backupPage returns the manifest entry of a page and downloads its assets.
+func (bw *BackupWriter) backupPage(t *PageTree) (*BackupPage, error) {
+	id := t.Page.ID
+	if bw.cached[id] {
+		p := *bw.Previous.Manifest.Pages[id]
+		if bw.Assets == nil {
+			p.Assets = nil
+		}
+		return &p, nil
+	}
+	p := &BackupPage{
+		Title:          t.Page.Title,
+		LastEditedTime: t.Page.LastEditedTime,
+		Records:        bw.pageRecords[id],
+	}
+	for _, c := range t.Children {
+		p.Children = append(p.Children, c.Page.ID)
+	}
+	notiontypes.Inspect(t.Page.Block, func(b *notiontypes.Block) bool {
+		if b.CollectionID != "" {
+			p.Collections = true
+		}
+		return !p.Collections
+	})
+	if bw.Assets != nil {
+		if err := bw.Assets.Download(t.Page.Block); err != nil {
+			bw.client.logger.WithError(err).Warnln("downloading assets failed")
+		}
+		files := bw.Assets.Files()
+		seen := map[string]bool{}
+		for _, ref := range assetRefs(t.Page.Block) {
+			if _, ok := files[ref.URL]; ok && !seen[ref.URL] {
+				seen[ref.URL] = true
+				p.Assets = append(p.Assets, ref.URL)
+			}
+		}
+	}
+	return p, nil
+}
+
+func (bw *BackupWriter) writeArchive(w io.Writer, m *BackupManifest) error {
+	z := zip.NewWriter(w)
+	add := func(name string, content []byte) error {
+		f, err := z.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: m.Created})
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(content)
+		return err
+	}
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := add("manifest.json", append(manifest, '\n')); err != nil {
+		return err
+	}
+	var keys []string
+	for key := range bw.records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := add("records/"+key+".json", bw.records[key]); err != nil {
+			return err
+		}
+	}
+	var names []string
+	seen := map[string]bool{}
+	for _, name := range m.Assets {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		content, err := ioutil.ReadFile(filepath.Join(bw.Assets.Dir, name))
+		if err != nil {
+			return errors.Wrap(err, "adding asset")
+		}
+		if err := add("assets/"+name, content); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+// BackupReader reads a backup archive written by BackupWriter.
+type BackupReader struct {
+	Manifest *BackupManifest
+
+	files map[string]*zip.File
+}
+
+// OpenBackup opens a backup archive of the given size.
+func OpenBackup(r io.ReaderAt, size int64) (*BackupReader, error) {
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening backup")
+	}
+	b := &BackupReader{files: map[string]*zip.File{}}
+	for _, f := range z.File {
+		b.files[f.Name] = f
+	}
+	content, err := b.read("manifest.json")
+	if err != nil {
+		return nil, errors.New("notion: not a backup, manifest.json is missing")
+	}
+	m := &BackupManifest{}
+	if err := json.Unmarshal(content, m); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling backup manifest")
+	}
+	if m.Format != backupFormat {
+		return nil, errors.Errorf("notion: not a backup, format is %q", m.Format)
+	}
+	if m.Version > BackupVersion {
+		return nil, errors.Errorf("notion: backup version %d is not supported", m.Version)
+	}
+	for _, name := range m.Assets {
+		if !validAssetName(name) {
+			return nil, errors.Errorf("notion: invalid asset name %q in backup", name)
+		}
+	}
+	b.Manifest = m
+	return b, nil
+}
+
+func (b *BackupReader) read(name string) ([]byte, error) {
+	f, ok := b.files[name]
+	if !ok {
+		return nil, errors.Errorf("notion: %s is missing in backup", name)
+	}
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// Record returns the JSON of a record, or nil if the record is not in the
+// backup.
+func (b *BackupReader) Record(table string, id string) (json.RawMessage, error) {
+	if _, ok := b.files["records/"+table+"/"+id+".json"]; !ok {
+		return nil, nil
+	}
+	return b.read("records/" + table + "/" + id + ".json")
+}
+
+// RecordIDs returns the ids of records of a table in the backup, sorted.
+func (b *BackupReader) RecordIDs(table string) []string {
+	prefix := "records/" + table + "/"
+	var ids []string
+	for name := range b.files {
+		if strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ".json") {
+			ids = append(ids, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".json"))
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
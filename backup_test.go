@@ -0,0 +1,167 @@
+package notion
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	p, tb := "11111111-1111-4111-8111-111111111111", "22222222-2222-4222-8222-222222222222"
+	responses := map[string]string{
+		"loadUserContent": `{"recordMap":{
+			"space":{"s":{"role":"editor","value":{"id":"s","name":"Team","pages":["11111111-1111-4111-8111-111111111111"]}}},
+			"notion_user":{"u":{"role":"reader","value":{"id":"u","given_name":"Ann"}}}}}`,
+		"loadPageChunk": `{"cursor":{"stack":[]},"recordMap":{"block":{
+			"11111111-1111-4111-8111-111111111111":{"role":"editor","value":{"id":"11111111-1111-4111-8111-111111111111","type":"page","alive":true,"space_id":"s","parent_id":"s","parent_table":"space","last_edited_time":100,"content":["22222222-2222-4222-8222-222222222222"],"properties":{"title":[["Home"]]}}},
+			"22222222-2222-4222-8222-222222222222":{"role":"editor","value":{"id":"22222222-2222-4222-8222-222222222222","type":"text","alive":true,"space_id":"s","parent_id":"11111111-1111-4111-8111-111111111111","parent_table":"block","properties":{"title":[["hello"]]}}}}}}`,
+		"getRecordValues":   `{"results":[{"role":"editor","value":{"id":"11111111-1111-4111-8111-111111111111","type":"page","alive":true,"last_edited_time":100}}]}`,
+		"submitTransaction": `{}`,
+	}
+	c, requests := newTestClient(t, responses)
+
+	var buf bytes.Buffer
+	m, err := NewBackupWriter(c).Write(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Spaces) != 1 || m.Spaces[0].Name != "Team" || m.Pages[p] == nil || m.Pages[p].Title != "Home" {
+		t.Fatalf("unexpected manifest: %+v", m)
+	}
+	b, err := OpenBackup(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b.RecordIDs("block"), []string{p, tb}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RecordIDs(block) = %v, want %v", got, want)
+	}
+	if v, _ := b.Record("notion_user", "u"); v == nil {
+		t.Error("user missing in backup")
+	}
+
+	// nothing changed, the page is copied from the previous backup
+	bw := NewBackupWriter(c)
+	bw.Previous = b
+	var incremental bytes.Buffer
+	if _, err := bw.Write(&incremental); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(requests["loadPageChunk"]); n != 1 {
+		t.Errorf("page loaded %d times", n)
+	}
+	b2, err := OpenBackup(bytes.NewReader(incremental.Bytes()), int64(incremental.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := b2.RecordIDs("block"); len(got) != 2 || b2.Manifest.Previous == nil {
+		t.Errorf("incremental backup has blocks %v, previous %v", got, b2.Manifest.Previous)
+	}
+
+	// a comment was added, which doesn't change the edit time of the page
+	responses["getRecordValues"] = `{"results":[{"role":"editor","value":{"id":"11111111-1111-4111-8111-111111111111","type":"page","alive":true,"last_edited_time":100,"discussion":["d"]}}]}`
+	bw = NewBackupWriter(c)
+	bw.Previous = b2
+	if _, err := bw.Write(ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(requests["loadPageChunk"]); n != 2 {
+		t.Errorf("page with a new discussion loaded %d times in total, want 2", n)
+	}
+	responses["getRecordValues"] = `{"results":[]}`
+
+	ids, err := c.Restore(b2, "s2", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tx submitTransactionRequest
+	if err := json.Unmarshal([]byte(requests["submitTransaction"][0]), &tx); err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.Operations) != 3 {
+		t.Fatalf("got %d operations: %s", len(tx.Operations), requests["submitTransaction"][0])
+	}
+	page := tx.Operations[0].Args.(map[string]interface{})
+	if page["id"] != ids[p] || page["parent_id"] != "s2" || page["space_id"] != "s2" {
+		t.Errorf("restored page: %v", page)
+	}
+	if content := page["content"].([]interface{}); len(content) != 1 || content[0] != ids[tb] {
+		t.Errorf("content of restored page: %v", content)
+	}
+	if op := tx.Operations[1]; op.ID != "s2" || op.Command != CommandListAfter {
+		t.Errorf("page not added to space: %+v", op)
+	}
+}
+
+func TestGetRecordsOfBatches(t *testing.T) {
+	c, requests := newTestClient(t, map[string]string{
+		"getRecordValues": `{"results":[]}`,
+	})
+	var ids []string
+	for i := 0; i < 2*maxRecordsPerRequest+1; i++ {
+		ids = append(ids, fmt.Sprint(i))
+	}
+	if err := c.getRecordsOf(tableComment, ids, func(json.RawMessage) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(requests["getRecordValues"]); n != 3 {
+		t.Errorf("%d records fetched in %d requests, want 3", len(ids), n)
+	}
+}
+
+func TestLastEditedTimes(t *testing.T) {
+	c, requests := newTestClient(t, map[string]string{
+		"getRecordValues": `{"results":[{"value":{"id":"a","alive":true,"last_edited_time":2}},{"value":{"id":"b","alive":false,"last_edited_time":3}},{}]}`,
+	})
+	ids := make([]string, maxRecordsPerRequest+1)
+	for i := range ids {
+		ids[i] = fmt.Sprint(i)
+	}
+	times, err := c.LastEditedTimes(ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int64{"a": 2}; !reflect.DeepEqual(times, want) {
+		t.Errorf("times = %v, want %v", times, want)
+	}
+	if n := len(requests["getRecordValues"]); n != 2 {
+		t.Errorf("%d requests, want 2", n)
+	}
+}
+
+func TestBackupAssetNames(t *testing.T) {
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+	w, _ := z.Create("manifest.json")
+	w.Write([]byte(`{"format":"notion-backup","version":1,"assets":{"https://example.com/a.png":"../../a.png"}}`))
+	z.Close()
+	if _, err := OpenBackup(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err == nil {
+		t.Error("no error opening a backup with an asset outside of assets/")
+	}
+
+	c, _ := newTestClient(t, nil)
+	dir := filepath.Join(t.TempDir(), "a", "b")
+	bw := NewBackupWriter(c)
+	bw.Assets = NewAssetDownloader(c, dir)
+	bw.Previous = &BackupReader{
+		Manifest: &BackupManifest{Assets: map[string]string{"https://example.com/a.png": "../../a.png"}},
+		files:    map[string]*zip.File{},
+	}
+	if err := bw.extractAssets(); err == nil {
+		t.Error("no error extracting an asset outside of the assets directory")
+	}
+	for _, name := range []string{"0123456789abcdef.png", "a.png"} {
+		if !validAssetName(name) {
+			t.Errorf("%q is invalid", name)
+		}
+	}
+	for _, name := range []string{"", ".", "..", "../a.png", "a/b.png", `a\b.png`, "a..png"} {
+		if validAssetName(name) {
+			t.Errorf("%q is valid", name)
+		}
+	}
+}
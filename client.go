@@ -121,6 +121,55 @@ func (c *Client) GetRecordValues(records ...Record) ([]*notiontypes.BlockWithRol
 	return r.Results, nil
 }
 
+type recordValue struct {
+	Role  string          `json:"role"`
+	Value json.RawMessage `json:"value"`
+}
+
+type getRawRecordValuesResponse struct {
+	Results []*recordValue `json:"results"`
+}
+
+// getRawRecordValues is like GetRecordValues but works for records of any table.
+// Records that don't exist or are not accessible have empty Value.
+func (c *Client) getRawRecordValues(records ...Record) ([]*recordValue, error) {
+	gr := getRecordValuesRequest{
+		Requests: records,
+	}
+	r := &getRawRecordValuesResponse{}
+	b, err := c.post(gr, "getRecordValues")
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling getRecordValuesResponse")
+	}
+	for _, v := range r.Results {
+		if string(v.Value) == "null" {
+			v.Value = nil
+		}
+	}
+	return r.Results, nil
+}
+
+type loadUserContentResponse struct {
+	RecordMap notiontypes.RecordMap `json:"recordMap"`
+}
+
+// LoadUserContent returns the records visible to the authenticated user: the user
+// itself, its spaces and their top-level pages.
+func (c *Client) LoadUserContent() (*notiontypes.RecordMap, error) {
+	r := &loadUserContentResponse{}
+	b, err := c.post(struct{}{}, "loadUserContent")
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling loadUserContentResponse")
+	}
+	return &r.RecordMap, nil
+}
+
 type loadPageChunkRequest struct {
 	PageID          string `json:"pageId"`
 	Limit           int64  `json:"limit,omitempty"`
@@ -135,6 +184,16 @@ type loadPageChunkResponse struct {
 
 // GetPage returns a Page given an id.
 func (c *Client) GetPage(pageID string) (*Page, error) {
+	chunks, err := c.loadPageChunks(pageID)
+	if err != nil {
+		return nil, err
+	}
+	return c.parsePageChunks(pageID, chunks)
+}
+
+// loadPageChunks returns the responses of loadPageChunk for all chunks of a
+// page, unparsed.
+func (c *Client) loadPageChunks(pageID string) ([][]byte, error) {
 	lp := loadPageChunkRequest{
 		PageID: pageID,
 		Limit:  50,
@@ -142,24 +201,38 @@ func (c *Client) GetPage(pageID string) (*Page, error) {
 			Stack: [][]StackPosition{},
 		},
 	}
-	results := []notiontypes.RecordMap{}
+	var chunks [][]byte
 	for {
-		r := &loadPageChunkResponse{}
+		var r struct {
+			Cursor Cursor `json:"cursor"`
+		}
 		b, err := c.post(lp, "loadPageChunk")
 		if err != nil {
 			return nil, err
 		}
 		c.logger.WithField("pageID", pageID).Debugln(string(b))
-		if err := json.Unmarshal(b, r); err != nil {
+		if err := json.Unmarshal(b, &r); err != nil {
 			return nil, errors.Wrap(err, "unmarshaling loadPageChunkResponse")
 		}
-
-		results = append(results, r.RecordMap)
+		chunks = append(chunks, b)
 		lp.Cursor = r.Cursor
 		if len(r.Cursor.Stack) == 0 {
 			break
 		}
 	}
+	return chunks, nil
+}
+
+// parsePageChunks returns a Page given responses of loadPageChunk.
+func (c *Client) parsePageChunks(pageID string, chunks [][]byte) (*Page, error) {
+	results := []notiontypes.RecordMap{}
+	for _, b := range chunks {
+		r := &loadPageChunkResponse{}
+		if err := json.Unmarshal(b, r); err != nil {
+			return nil, errors.Wrap(err, "unmarshaling loadPageChunkResponse")
+		}
+		results = append(results, r.RecordMap)
+	}
 	return c.parsePageFromRecordMaps(pageID, results)
 }
 
@@ -170,6 +243,8 @@ func mergeRecordMaps(rms ...notiontypes.RecordMap) (notiontypes.RecordMap, error
 		Users:           make(map[string]*notiontypes.UserWithRole, 0),
 		Collections:     make(map[string]*notiontypes.CollectionWithRole, 0),
 		CollectionViews: make(map[string]*notiontypes.CollectionViewWithRole, 0),
+		Discussions:     make(map[string]*notiontypes.DiscussionWithRole, 0),
+		Comments:        make(map[string]*notiontypes.CommentWithRole, 0),
 	}
 	// TODO: consider merging into first recordmap as a heap optimization.
 
@@ -189,6 +264,12 @@ func mergeRecordMaps(rms ...notiontypes.RecordMap) (notiontypes.RecordMap, error
 		for k, v := range rm.CollectionViews {
 			result.CollectionViews[k] = v
 		}
+		for k, v := range rm.Discussions {
+			result.Discussions[k] = v
+		}
+		for k, v := range rm.Comments {
+			result.Comments[k] = v
+		}
 	}
 	return result, nil
 }
@@ -210,5 +291,15 @@ func (c *Client) parsePageFromRecordMaps(pageID string, responses []notiontypes.
 	if err := notiontypes.ResolveBlock(page.Block, blocks); err != nil {
 		return nil, errors.Wrap(err, "resolveBlock failed")
 	}
+	if err := c.loadMissingDiscussions(&rm); err != nil {
+		c.logger.WithError(err).Warnln("loading discussions failed")
+	}
+	if err := resolveDiscussions(blocks, &rm); err != nil {
+		return nil, errors.Wrap(err, "resolveDiscussions failed")
+	}
+	if err := c.resolveCollections(page, &rm); err != nil {
+		c.logger.WithError(err).Warnln("loading collections failed")
+	}
+	c.resolveUsers(page, &rm)
 	return page, nil
 }
@@ -1 +1,123 @@
 package notion
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// newTestClient returns a client talking to a server that replies to each
+// api endpoint with the given response and records request bodies.
+func newTestClient(t *testing.T, responses map[string]string) (*Client, map[string][]string) {
+	t.Helper()
+	requests := map[string][]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpoint := r.URL.Path[len("/api/v3/"):]
+		body, _ := ioutil.ReadAll(r.Body)
+		requests[endpoint] = append(requests[endpoint], string(body))
+		resp, ok := responses[endpoint]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(resp))
+	}))
+	t.Cleanup(srv.Close)
+	c, err := NewClient(WithBaseURL(srv.URL + "/api/v3/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, requests
+}
+
+func TestReplyToDiscussion(t *testing.T) {
+	c, requests := newTestClient(t, map[string]string{
+		"loadUserContent":   `{"recordMap":{"notion_user":{"u1":{"role":"editor","value":{"id":"u1"}}}}}`,
+		"submitTransaction": `{}`,
+	})
+	comment, err := c.ReplyToDiscussion("d1", "sounds good")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comment.CreatedBy != "u1" || comment.ParentID != "d1" {
+		t.Errorf("unexpected comment: %+v", comment)
+	}
+	var tx submitTransactionRequest
+	if err := json.Unmarshal([]byte(requests["submitTransaction"][0]), &tx); err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.Operations) != 2 || tx.Operations[1].Command != CommandListAfter || tx.Operations[1].ID != "d1" {
+		t.Errorf("unexpected operations: %s", requests["submitTransaction"][0])
+	}
+}
+
+func TestCurrentUserID(t *testing.T) {
+	tests := []struct {
+		users   string
+		want    string
+		wantErr bool
+	}{
+		{`{"u1":{"role":"reader","value":{"id":"u1"}}}`, "u1", false},
+		{`{"u1":{"role":"reader","value":{"id":"u1"}},"u2":{"role":"editor","value":{"id":"u2"}},"u3":{"role":"reader","value":{"id":"u3"}}}`, "u2", false},
+		{`{"u1":{"role":"editor","value":{"id":"u1"}},"u2":{"role":"editor","value":{"id":"u2"}}}`, "", true},
+		{`{}`, "", true},
+	}
+	for _, tt := range tests {
+		c, _ := newTestClient(t, map[string]string{
+			"loadUserContent": `{"recordMap":{"notion_user":` + tt.users + `}}`,
+		})
+		got, err := c.currentUserID()
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("users %s: got %q, %v, want %q", tt.users, got, err, tt.want)
+		}
+	}
+}
+
+func TestGetPageResolvesUsers(t *testing.T) {
+	c, requests := newTestClient(t, map[string]string{
+		"loadPageChunk": `{"recordMap":{
+			"block":{
+				"p":{"value":{"id":"p","type":"page","created_by":"u1","last_edited_by":"u2","content":["t"],"properties":{"title":[["Page"]]}}},
+				"t":{"value":{"id":"t","type":"text","created_by":"u1","properties":{"title":[["‣",[["u","u2"]]]]}}}
+			},
+			"notion_user":{"u1":{"value":{"id":"u1","given_name":"Ada","family_name":"Lovelace"}}}
+		},"cursor":{"stack":[]}}`,
+		"getRecordValues": `{"results":[{"role":"reader","value":{"id":"u2","given_name":"Alan","family_name":"Turing"}}]}`,
+	})
+	page, err := c.GetPage("p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests["getRecordValues"]) != 1 {
+		t.Fatalf("expected one getRecordValues call for missing user, got %d", len(requests["getRecordValues"]))
+	}
+	if page.CreatedByUser == nil || page.CreatedByUser.Name() != "Ada Lovelace" {
+		t.Errorf("CreatedByUser: %+v", page.CreatedByUser)
+	}
+	if got := mention(page.Content[0].InlineContent[0]); got != "@Alan Turing" {
+		t.Errorf("mention: %q", got)
+	}
+}
+
+// testPage resolves a page from a JSON list of blocks, the first of which is the page.
+func testPage(t *testing.T, blocksJSON string) *Page {
+	t.Helper()
+	var blocks []*notiontypes.Block
+	if err := json.Unmarshal([]byte(blocksJSON), &blocks); err != nil {
+		t.Fatal(err)
+	}
+	rm := notiontypes.RecordMap{Blocks: map[string]*notiontypes.BlockWithRole{}}
+	for _, b := range blocks {
+		rm.Blocks[b.ID] = &notiontypes.BlockWithRole{Value: b}
+	}
+	c, _ := newTestClient(t, nil)
+	page, err := c.parsePageFromRecordMaps(blocks[0].ID, []notiontypes.RecordMap{rm})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return page
+}
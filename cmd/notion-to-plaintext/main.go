@@ -10,6 +10,8 @@ import (
 
 var (
 	flagVerbose = flag.Bool("v", false, "verbose")
+	flagFormat  = flag.String("format", "text", "output format: text, or vim for folds with block types and ids")
+	flagWidth   = flag.Int("width", 80, "wrap text to width characters, 0 to disable wrapping")
 )
 
 func main() {
@@ -47,10 +49,21 @@ func run(id string) error {
 	if err != nil {
 		return err
 	}
-	r, err := notion.PrintAsVim(p.Block, "  ")
-	if err != nil {
+	switch *flagFormat {
+	case "text":
+		r, err := notion.PrintAsText(p.Block, notion.WithWidth(*flagWidth))
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(r)
 		return err
+	case "vim":
+		r, err := notion.PrintAsVim(p.Block, "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(r))
+		return nil
 	}
-	fmt.Println(string(r))
-	return nil
+	return fmt.Errorf("unknown format %q", *flagFormat)
 }
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tmc/notion"
+)
+
+func runBackup(c *notion.Client, args []string) error {
+	fs := newFlagSet("backup", "[flags]")
+	out := fs.String("o", "", "archive to write, by default notion-backup-<date>.zip")
+	previous := fs.String("previous", "", "previous backup to make an incremental backup to")
+	assets := fs.Bool("assets", true, "include images and files uploaded to notion.so")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+	if *out == "" {
+		*out = "notion-backup-" + time.Now().Format("2006-01-02-150405") + ".zip"
+	}
+
+	bw := notion.NewBackupWriter(c)
+	bw.OnPage = func(t *notion.PageTree) {
+		fmt.Fprintf(os.Stderr, "%s%s\n", strings.Repeat("  ", t.Depth()), t.Page.Title)
+	}
+	if *previous != "" {
+		f, err := os.Open(*previous)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if bw.Previous, err = notion.OpenBackup(f, info.Size()); err != nil {
+			return err
+		}
+	}
+	if *assets {
+		dir, err := ioutil.TempDir("", "notion-backup-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		bw.Assets = notion.NewAssetDownloader(c, dir)
+	}
+
+	// write to a temporary file, to not leave a partial archive behind
+	tmp, err := ioutil.TempFile(filepath.Dir(*out), ".notion-backup-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	m, err := bw.Write(tmp)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), *out); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "backed up %d pages of %d spaces and %d files to %s\n", len(m.Pages), len(m.Spaces), len(m.Assets), *out)
+	return nil
+}
+
+func runRestore(c *notion.Client, args []string) error {
+	fs := newFlagSet("restore", "[flags] <archive> [page]...")
+	space := fs.String("space", "", "id of the space to restore pages into (required)")
+	parent := fs.String("parent", "", "restore pages as sub-pages of this page instead of top-level pages")
+	list := fs.Bool("list", false, "list the spaces and top-level pages of the backup instead of restoring")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("expected a backup archive")
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	b, err := notion.OpenBackup(f, info.Size())
+	if err != nil {
+		return err
+	}
+	if *list {
+		fmt.Printf("backup of %s, version %d\n", b.Manifest.Created.Local().Format(time.RFC1123), b.Manifest.Version)
+		for _, s := range b.Manifest.Spaces {
+			fmt.Printf("%s\t%s\n", s.ID, s.Name)
+			for _, id := range s.Pages {
+				if p := b.Manifest.Pages[id]; p != nil {
+					fmt.Printf("  %s\t%s\n", id, p.Title)
+				}
+			}
+		}
+		return nil
+	}
+	if *space == "" {
+		fs.Usage()
+		return fmt.Errorf("-space is required")
+	}
+	var parentID string
+	if *parent != "" {
+		if parentID, err = notion.ParsePageID(*parent); err != nil {
+			return err
+		}
+	}
+	var pageIDs []string
+	for _, arg := range fs.Args()[1:] {
+		id, err := notion.ParsePageID(arg)
+		if err != nil {
+			return err
+		}
+		pageIDs = append(pageIDs, id)
+	}
+	if len(pageIDs) == 0 {
+		for _, s := range b.Manifest.Spaces {
+			pageIDs = append(pageIDs, s.Pages...)
+		}
+	}
+	newIDs, err := c.Restore(b, *space, parentID, pageIDs...)
+	if err != nil {
+		return err
+	}
+	for _, id := range pageIDs {
+		if p := b.Manifest.Pages[id]; p != nil {
+			fmt.Printf("%s\t%s\n", notion.PageURL(newIDs[id]), p.Title)
+		}
+	}
+	return nil
+}
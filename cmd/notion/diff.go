@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func runDiff(c *notion.Client, args []string) error {
+	fs := newFlagSet("diff", "[flags] <old> <new>")
+	format := fs.String("format", "text", "output format: text or html")
+	sideBySide := fs.Bool("side-by-side", false, "show old and new versions in two columns")
+	width := fs.Int("width", 60, "width of a column of side by side text output")
+	all := fs.Bool("all", false, "show unchanged blocks, not only the ancestors of changed ones")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *width < 1 {
+		return fmt.Errorf("-width must be positive, got %d", *width)
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("expected two snapshot files or pages, got %d arguments", fs.NArg())
+	}
+	old, err := loadSnapshot(c, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	cur, err := loadSnapshot(c, fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	d := notion.DiffPages(old, cur)
+	switch {
+	case *format == "html":
+		return d.WriteHTML(os.Stdout, *sideBySide, *all)
+	case *format != "text":
+		return fmt.Errorf("unknown format %q", *format)
+	case *sideBySide:
+		return d.WriteSideBySide(os.Stdout, *width, *all)
+	}
+	return d.WriteUnified(os.Stdout, *all)
+}
+
+// loadSnapshot reads a page tree saved by 'notion get -format json', or loads
+// the current version of a page if arg is not a file.
+func loadSnapshot(c *notion.Client, arg string) (*notiontypes.Block, error) {
+	data, err := ioutil.ReadFile(arg)
+	if os.IsNotExist(err) {
+		pageID, err := notion.ParsePageID(arg)
+		if err != nil {
+			return nil, err
+		}
+		page, err := c.GetPage(pageID)
+		if err != nil {
+			return nil, err
+		}
+		return page.Block, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var b notiontypes.Block
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("reading snapshot %s: %v", arg, err)
+	}
+	return &b, nil
+}
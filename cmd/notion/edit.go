@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/tmc/notion"
+)
+
+func runEdit(c *notion.Client, args []string) error {
+	fs := newFlagSet("edit", "[flags] <page>")
+	yes := fs.Bool("y", false, "apply changes without asking")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	pageID, err := pageArg(fs)
+	if err != nil {
+		return err
+	}
+	page, err := c.GetPage(pageID)
+	if err != nil {
+		return err
+	}
+	e := notion.NewPageEdit(page.Block)
+
+	f, err := ioutil.TempFile("", "notion-edit-*.md")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	if _, err := f.Write(e.Text()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := runEditor(name); err != nil {
+		return err
+	}
+	text, err := ioutil.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	changes, err := e.Diff(text)
+	if err != nil {
+		return fmt.Errorf("%v (your edit is kept in %s)", err, name)
+	}
+	if len(changes) == 0 {
+		os.Remove(name)
+		fmt.Fprintln(os.Stderr, "no changes")
+		return nil
+	}
+	for _, ch := range changes {
+		fmt.Fprintln(os.Stderr, ch.Description)
+	}
+	if !*yes && !confirm(fmt.Sprintf("apply %d changes?", len(changes))) {
+		return fmt.Errorf("aborted (your edit is kept in %s)", name)
+	}
+	if err := c.ApplyEdit(e, changes); err != nil {
+		return fmt.Errorf("%v (your edit is kept in %s)", err, name)
+	}
+	return os.Remove(name)
+}
+
+// runEditor opens a file in $EDITOR, vi by default.
+func runEditor(name string) error {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	// $EDITOR may include arguments, e.g. "code --wait"
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", name)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// confirm asks a yes or no question on the terminal.
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tmc/notion"
+)
+
+// epubExporter writes a page tree as an EPUB book into a directory, named
+// after the title of the root page.
+type epubExporter struct {
+	client *notion.Client
+	dir    string
+	assets bool
+}
+
+func (e *epubExporter) export(tree *notion.PageTree) error {
+	w := notion.NewEPUBWriter(nil)
+	if e.assets {
+		tmp, err := ioutil.TempDir("", "notion-epub")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+		w.Assets = notion.NewAssetDownloader(e.client, tmp)
+		for _, t := range tree.Pages() {
+			if err := w.Assets.Download(t.Page.Block); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			}
+		}
+	}
+
+	name := notion.Slugify(tree.Page.Title)
+	if name == "" {
+		name = strings.Replace(tree.Page.ID, "-", "", -1)
+	}
+	if err := os.MkdirAll(e.dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(filepath.Join(e.dir, name+".epub"))
+	if err != nil {
+		return err
+	}
+	if err := w.Write(f, tree); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
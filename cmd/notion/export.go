@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tmc/notion"
+)
+
+func runExport(c *notion.Client, args []string) error {
+	fs := newFlagSet("export", "[flags] <root-page>")
+	format := fs.String("format", "hugo", "output format: hugo, jekyll, obsidian, latex or epub")
+	frontMatter := fs.String("front-matter", "", "front matter format: yaml or toml (default toml for hugo, yaml for jekyll)")
+	out := fs.String("o", ".", "root directory of the site, vault, LaTeX documents or books")
+	depth := fs.Int("depth", 0, "maximum depth of sub-pages, 0 means no limit")
+	rows := fs.Bool("rows", true, "export rows of databases as pages")
+	assets := fs.Bool("assets", true, "download images and files into the site")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	rootID, err := pageArg(fs)
+	if err != nil {
+		return err
+	}
+	var e *contentExporter
+	var x exporter
+	switch *format {
+	case "hugo":
+		e = &contentExporter{hugo: true, dir: filepath.Join(*out, "content"), fm: "toml"}
+		if *assets {
+			e.assets = notion.NewAssetDownloader(c, filepath.Join(*out, "static", "notion"))
+			e.assets.Prefix = "/notion/"
+		}
+	case "jekyll":
+		e = &contentExporter{dir: *out, fm: "yaml"}
+		if *assets {
+			e.assets = notion.NewAssetDownloader(c, filepath.Join(*out, "assets", "notion"))
+			e.assets.Prefix = "/assets/notion/"
+		}
+	case "obsidian", "vault":
+		v := &vaultExporter{dir: *out}
+		if *assets {
+			v.assets = notion.NewAssetDownloader(c, filepath.Join(*out, "attachments"))
+			v.assets.Prefix = "attachments/"
+		}
+		x = v
+	case "latex":
+		l := &latexExporter{dir: *out}
+		if *assets {
+			l.assets = notion.NewAssetDownloader(c, filepath.Join(*out, "images"))
+			l.assets.Prefix = "images/"
+		}
+		x = l
+	case "epub":
+		x = &epubExporter{client: c, dir: *out, assets: *assets}
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if e != nil {
+		x = e
+	}
+	if *frontMatter != "" && e != nil {
+		if *frontMatter != "yaml" && *frontMatter != "toml" {
+			return fmt.Errorf("unknown front matter format %q", *frontMatter)
+		}
+		e.fm = *frontMatter
+	}
+
+	crawler := notion.NewCrawler(c)
+	crawler.MaxDepth = *depth
+	crawler.Rows = *rows
+	crawler.OnPage = func(t *notion.PageTree) {
+		fmt.Fprintf(os.Stderr, "%s%s\n", strings.Repeat("  ", t.Depth()), t.Page.Title)
+	}
+	tree, err := crawler.Crawl(rootID)
+	if err != nil {
+		return err
+	}
+	return x.export(tree)
+}
+
+// exporter writes a crawled page tree.
+type exporter interface {
+	export(tree *notion.PageTree) error
+}
+
+// contentExporter writes pages as Markdown content files of a Hugo or Jekyll
+// site.
+type contentExporter struct {
+	hugo   bool
+	dir    string
+	fm     string
+	assets *notion.AssetDownloader
+
+	// paths of content files relative to dir, by page id
+	paths map[string]string
+}
+
+func (e *contentExporter) export(tree *notion.PageTree) error {
+	e.paths = map[string]string{}
+	e.assignPaths(tree, "")
+	for _, t := range tree.Pages() {
+		if err := e.writePage(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isPost returns true for database rows exported as Jekyll posts.
+func (e *contentExporter) isPost(t *notion.PageTree) bool {
+	return !e.hugo && t.Page.Collection != nil && t.Parent != nil
+}
+
+// assignPaths assigns content file paths to pages, mirroring the page
+// hierarchy. Pages with sub-pages are Hugo branch bundles (_index.md), other
+// pages are leaf bundles (index.md). Jekyll posts go to _posts.
+func (e *contentExporter) assignPaths(t *notion.PageTree, dir string) {
+	name := "index.md"
+	if e.hugo && len(t.Children) > 0 {
+		name = "_index.md"
+	}
+	e.paths[t.Page.ID] = dir + name
+	used := map[string]bool{}
+	for _, c := range t.Children {
+		slug := notion.Slugify(c.Page.Title)
+		if slug == "" {
+			slug = strings.Replace(c.Page.ID, "-", "", -1)
+		}
+		if e.isPost(c) {
+			slug = postDate(c.Page).Format("2006-01-02-") + slug
+		}
+		unique := slug
+		for i := 2; used[unique]; i++ {
+			unique = fmt.Sprintf("%s-%d", slug, i)
+		}
+		used[unique] = true
+		if e.isPost(c) {
+			e.paths[c.Page.ID] = "_posts/" + unique + ".md"
+			e.assignChildPaths(c, dir+unique+"/")
+			continue
+		}
+		e.assignPaths(c, dir+unique+"/")
+	}
+}
+
+// assignChildPaths assigns paths to sub-pages of a Jekyll post.
+func (e *contentExporter) assignChildPaths(t *notion.PageTree, dir string) {
+	self := e.paths[t.Page.ID]
+	e.assignPaths(t, dir)
+	e.paths[t.Page.ID] = self
+}
+
+// postDate returns the date of a database row: the value of its first date
+// property or its creation time.
+func postDate(page *notion.Page) time.Time {
+	if d, ok := notion.PageFrontMatter(page).Get("date").(time.Time); ok {
+		return d
+	}
+	return page.CreatedOn()
+}
+
+// link returns the link to the content file of a page in the syntax of the
+// site generator.
+func (e *contentExporter) link(pageID string) string {
+	p, ok := e.paths[pageID]
+	if !ok {
+		return notion.PageURL(pageID)
+	}
+	if e.hugo {
+		return fmt.Sprintf(`{{< ref "/%s" >}}`, p)
+	}
+	if strings.HasPrefix(p, "_posts/") {
+		return fmt.Sprintf("{%% post_url %s %%}", strings.TrimSuffix(path.Base(p), ".md"))
+	}
+	return fmt.Sprintf("{%% link %s %%}", p)
+}
+
+func (e *contentExporter) writePage(t *notion.PageTree) error {
+	opts := []notion.PrintOption{notion.WithoutTitle(), notion.WithPageURLs(e.link)}
+	if e.assets != nil {
+		if err := e.assets.Download(t.Page.Block); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+		opts = append(opts, notion.WithAssetURLs(e.assets.URL))
+	}
+	content, err := notion.PrintAsMarkdown(t.Page.Block, opts...)
+	if err != nil {
+		return err
+	}
+	fm := notion.PageFrontMatter(t.Page, opts...)
+	if e.hugo && t.Parent != nil && t.Page.Collection == nil {
+		// keep the order of sub-pages in Hugo's section listings, rows are
+		// listed by date
+		for i, c := range t.Parent.Children {
+			if c == t {
+				fm.Set("weight", float64(i+1))
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if e.fm == "toml" {
+		err = fm.WriteTOML(&buf)
+	} else {
+		err = fm.WriteYAML(&buf)
+	}
+	if err != nil {
+		return err
+	}
+	buf.WriteString("\n")
+	buf.Write(content)
+
+	dst := filepath.Join(e.dir, filepath.FromSlash(e.paths[t.Page.ID]))
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dst, buf.Bytes(), 0644)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func runExportDB(c *notion.Client, args []string) error {
+	fs := newFlagSet("export-db", "[flags] <database-page>")
+	format := fs.String("format", "csv", "output format: csv, tsv or jsonl")
+	viewName := fs.String("view", "", "name of the view to export (default: first view)")
+	out := fs.String("o", "", "output file (default: stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	pageID, err := pageArg(fs)
+	if err != nil {
+		return err
+	}
+	page, err := c.GetPage(pageID)
+	if err != nil {
+		return err
+	}
+	db := findDatabase(page.Block)
+	if db == nil {
+		return fmt.Errorf("page %v contains no database", pageID)
+	}
+	var view *notiontypes.CollectionViewInfo
+	for _, v := range db.CollectionViews {
+		if *viewName == "" || v.CollectionView.Name == *viewName {
+			view = v
+			break
+		}
+	}
+	if view == nil {
+		return fmt.Errorf("database %q has no view %q", db.Title, *viewName)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return c.ExportCollection(view.Collection, view.CollectionView, w, notion.ExportFormat(*format))
+}
+
+// findDatabase returns the first block of a page that displays a collection.
+func findDatabase(page *notiontypes.Block) *notiontypes.Block {
+	var db *notiontypes.Block
+	notiontypes.InspectSynced(page, func(b *notiontypes.Block) bool {
+		if db != nil || b != page && b.IsPage() {
+			return false
+		}
+		if len(b.CollectionViews) > 0 {
+			db = b
+		}
+		return db == nil
+	})
+	return db
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/tmc/notion"
+)
+
+func runGet(c *notion.Client, args []string) error {
+	fs := newFlagSet("get", "[flags] <page>")
+	format := fs.String("format", "markdown", "output format: markdown, html, text, org, pandoc-json or json")
+	width := fs.Int("width", 80, "wrap text output to width characters, 0 to disable wrapping")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	pageID, err := pageArg(fs)
+	if err != nil {
+		return err
+	}
+	page, err := c.GetPage(pageID)
+	if err != nil {
+		return err
+	}
+	var out []byte
+	switch *format {
+	case "markdown", "md":
+		out, err = notion.PrintAsMarkdown(page.Block)
+	case "html":
+		out, err = notion.PrintAsHTML(page.Block)
+	case "org":
+		out, err = notion.PrintAsOrg(page)
+	case "text":
+		out, err = notion.PrintAsText(page.Block, notion.WithWidth(*width))
+	case "pandoc-json":
+		out, err = notion.PrintAsPandocJSON(page.Block)
+	case "json":
+		out, err = json.MarshalIndent(page.Block, "", "  ")
+		out = append(out, '\n')
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
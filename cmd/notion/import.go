@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+func runImport(c *notion.Client, args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "md":
+			return importMarkdown(c, args[1:])
+		case "csv":
+			return importCSV(c, args[1:])
+		}
+	}
+	return fmt.Errorf("usage: notion import md|csv [flags] <page> <file>...")
+}
+
+func importMarkdown(c *notion.Client, args []string) error {
+	fs := newFlagSet("import md", "[flags] <parent-page> <file.md>...")
+	title := fs.String("title", "", "title of the page (default: first heading or file name)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return fmt.Errorf("expected a parent page and at least one file")
+	}
+	parentID, err := notion.ParsePageID(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	for _, name := range fs.Args()[1:] {
+		src, err := ioutil.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		blocks := notion.ParseMarkdown(src)
+		t := *title
+		if t == "" {
+			t, blocks = markdownTitle(name, blocks)
+		}
+		warnLocalImages(name, blocks)
+		id, err := c.CreatePage(parentID, t, blocks)
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		fmt.Println(notion.PageURL(id))
+	}
+	return nil
+}
+
+func importCSV(c *notion.Client, args []string) error {
+	fs := newFlagSet("import csv", "[flags] <page> <file.csv>")
+	title := fs.String("title", "", "title of the new database (default: file name)")
+	update := fs.Bool("update", false, "import into the existing database of the page instead of creating a new one")
+	key := fs.String("key", "", "with -update, column matching records to existing rows (default: title)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("expected a page and a file")
+	}
+	pageID, err := notion.ParsePageID(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if !*update {
+		t := *title
+		if t == "" {
+			base := filepath.Base(fs.Arg(1))
+			t = strings.TrimSuffix(base, filepath.Ext(base))
+		}
+		_, err := c.CreateCollectionFromCSV(pageID, t, f)
+		return err
+	}
+	page, err := c.GetPage(pageID)
+	if err != nil {
+		return err
+	}
+	db := findDatabase(page.Block)
+	if db == nil {
+		return fmt.Errorf("page %v contains no database", pageID)
+	}
+	view := db.CollectionViews[0]
+	return c.ImportCSV(view.Collection, view.CollectionView, f, *key)
+}
+
+// markdownTitle returns the title of a document: the text of its first block
+// if it's a top-level heading, which is then removed, or the file name.
+func markdownTitle(name string, blocks []*notiontypes.Block) (string, []*notiontypes.Block) {
+	if len(blocks) > 0 && blocks[0].Type == notiontypes.BlockHeader {
+		var sb strings.Builder
+		for _, ib := range blocks[0].InlineContent {
+			sb.WriteString(ib.Text)
+		}
+		return sb.String(), blocks[1:]
+	}
+	base := filepath.Base(name)
+	return strings.TrimSuffix(base, filepath.Ext(base)), blocks
+}
+
+// warnLocalImages warns about images that refer to local files, which are
+// not uploaded.
+func warnLocalImages(name string, blocks []*notiontypes.Block) {
+	for _, b := range blocks {
+		if b.Type == notiontypes.BlockImage {
+			if u, err := url.Parse(b.Source); err != nil || u.Scheme == "" {
+				fmt.Fprintf(os.Stderr, "warning: %s: image %s is not a url and won't be displayed\n", name, b.Source)
+			}
+		}
+		warnLocalImages(name, b.Content)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tmc/notion"
+)
+
+// latexExporter writes pages as LaTeX documents into a directory, one per
+// page, named after the page title. Links between exported pages refer to
+// the PDF files compiled from them.
+type latexExporter struct {
+	dir    string
+	assets *notion.AssetDownloader
+
+	// file names without extension, by page id
+	names map[string]string
+}
+
+func (e *latexExporter) export(tree *notion.PageTree) error {
+	e.names = map[string]string{}
+	used := map[string]bool{}
+	for _, t := range tree.Pages() {
+		slug := notion.Slugify(t.Page.Title)
+		if slug == "" {
+			slug = strings.Replace(t.Page.ID, "-", "", -1)
+		}
+		unique := slug
+		for i := 2; used[unique]; i++ {
+			unique = fmt.Sprintf("%s-%d", slug, i)
+		}
+		used[unique] = true
+		e.names[t.Page.ID] = unique
+	}
+	if err := os.MkdirAll(e.dir, 0755); err != nil {
+		return err
+	}
+	for _, t := range tree.Pages() {
+		if err := e.writePage(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// link returns the PDF file of an exported page, or its notion.so url.
+func (e *latexExporter) link(pageID string) string {
+	if name, ok := e.names[pageID]; ok {
+		return name + ".pdf"
+	}
+	return notion.PageURL(pageID)
+}
+
+func (e *latexExporter) writePage(t *notion.PageTree) error {
+	opts := []notion.PrintOption{notion.WithPageURLs(e.link)}
+	if e.assets != nil {
+		if err := e.assets.Download(t.Page.Block); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+		opts = append(opts, notion.WithAssetURLs(e.assets.URL))
+	}
+	content, err := notion.PrintAsLaTeX(t.Page.Block, opts...)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(e.dir, e.names[t.Page.ID]+".tex"), content, 0644)
+}
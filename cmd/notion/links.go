@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tmc/notion"
+)
+
+func runLinks(c *notion.Client, args []string) error {
+	fs := newFlagSet("links", "[flags] <root-page>")
+	format := fs.String("format", "dot", "output format of the graph: dot or json")
+	backlinks := fs.String("backlinks", "", "list pages linking to this page instead of printing the graph")
+	orphans := fs.Bool("orphans", false, "list pages that are not linked to from other pages instead of printing the graph")
+	depth := fs.Int("depth", 0, "maximum depth of sub-pages, 0 means no limit")
+	rows := fs.Bool("rows", true, "include rows of databases")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	rootID, err := pageArg(fs)
+	if err != nil {
+		return err
+	}
+	if *format != "dot" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	var target string
+	if *backlinks != "" {
+		if target, err = notion.ParsePageID(*backlinks); err != nil {
+			return err
+		}
+	}
+
+	crawler := notion.NewCrawler(c)
+	crawler.MaxDepth = *depth
+	crawler.Rows = *rows
+	crawler.OnPage = func(t *notion.PageTree) {
+		fmt.Fprintf(os.Stderr, "%s%s\n", strings.Repeat("  ", t.Depth()), t.Page.Title)
+	}
+	tree, err := crawler.Crawl(rootID)
+	if err != nil {
+		return err
+	}
+	g := notion.NewLinkGraph()
+	g.AddTree(tree)
+
+	switch {
+	case target != "":
+		for _, l := range g.Backlinks(target) {
+			fmt.Printf("%s\t%s\t%s\n", l.From, l.Kind, g.Titles[l.From])
+		}
+		return nil
+	case *orphans:
+		for _, id := range g.Orphans() {
+			fmt.Printf("%s\t%s\n", id, g.Titles[id])
+		}
+		return nil
+	case *format == "json":
+		return g.WriteJSON(os.Stdout)
+	}
+	return g.WriteDOT(os.Stdout)
+}
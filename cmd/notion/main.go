@@ -0,0 +1,103 @@
+// Command notion is a command line client for notion.so.
+//
+// The authentication token is read from the NOTION_TOKEN environment variable.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/tmc/notion"
+)
+
+var (
+	flagVerbose = flag.Bool("v", false, "verbose")
+)
+
+type command struct {
+	name  string
+	args  string
+	short string
+	run   func(c *notion.Client, args []string) error
+}
+
+var commands = []*command{
+	{"backup", "[flags]", "back up all spaces into an archive", runBackup},
+	{"diff", "[flags] <old> <new>", "compare two snapshots or versions of a page", runDiff},
+	{"edit", "[flags] <page>", "edit a page in $EDITOR", runEdit},
+	{"export", "[flags] <root-page>", "export a page tree as Hugo or Jekyll content, an Obsidian vault, LaTeX or EPUB", runExport},
+	{"export-db", "[flags] <database-page>", "export rows of a database as CSV, TSV or JSON Lines", runExportDB},
+	{"get", "[flags] <page>", "print a page as Markdown, HTML, plain text, Org, Pandoc JSON or JSON", runGet},
+	{"import", "md|csv [flags] <page> <file>...", "import Markdown files as pages or CSV files as databases", runImport},
+	{"links", "[flags] <root-page>", "print the link graph of a page tree, backlinks or orphaned pages", runLinks},
+	{"mirror", "-git <dir> [flags] <root-page>", "mirror a page tree as Markdown and JSON into a git repository", runMirror},
+	{"restore", "[flags] <archive> [page]...", "restore pages from a backup", runRestore},
+	{"show", "[flags] <page>", "show a page in the terminal", runShow},
+	{"site", "build [flags] <root-page>", "build a static site from a page tree", runSite},
+	{"tree", "[flags] [root-page]", "print the page hierarchy of a page or of all spaces", runTree},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: notion [-v] <command> [arguments]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.short)
+	}
+	fmt.Fprintf(os.Stderr, "\nrun 'notion <command> -h' for help on a command\n")
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	name, args := flag.Arg(0), flag.Args()[1:]
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		c, err := newClient()
+		if err == nil {
+			err = cmd.run(c, args)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "notion %s: %v\n", name, err)
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "notion: unknown command %q\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func newClient() (*notion.Client, error) {
+	opts := []notion.ClientOption{
+		notion.WithToken(os.Getenv("NOTION_TOKEN")),
+	}
+	if *flagVerbose {
+		opts = append(opts, notion.WithDebugLogging())
+	}
+	return notion.NewClient(opts...)
+}
+
+// newFlagSet returns a flag set for a command with usage derived from it.
+func newFlagSet(cmd string, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: notion %s %s\n", cmd, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// pageArg parses the single page id or url argument of a command.
+func pageArg(fs *flag.FlagSet) (string, error) {
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return "", fmt.Errorf("expected one page id or url, got %d arguments", fs.NArg())
+	}
+	return notion.ParsePageID(fs.Arg(0))
+}
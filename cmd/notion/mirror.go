@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tmc/notion"
+)
+
+func runMirror(c *notion.Client, args []string) error {
+	fs := newFlagSet("mirror", "-git <dir> [flags] <root-page>")
+	dir := fs.String("git", "", "git working tree to mirror pages into, created if needed (required)")
+	depth := fs.Int("depth", 0, "maximum depth of sub-pages, 0 means no limit")
+	rows := fs.Bool("rows", true, "mirror rows of databases as pages")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dir == "" {
+		fs.Usage()
+		return fmt.Errorf("-git is required")
+	}
+	rootID, err := pageArg(fs)
+	if err != nil {
+		return err
+	}
+	crawler := notion.NewCrawler(c)
+	crawler.MaxDepth = *depth
+	crawler.Rows = *rows
+	crawler.OnPage = func(t *notion.PageTree) {
+		fmt.Fprintf(os.Stderr, "%s%s\n", strings.Repeat("  ", t.Depth()), t.Page.Title)
+	}
+	tree, err := crawler.Crawl(rootID)
+	if err != nil {
+		return err
+	}
+	changes, err := notion.NewGitMirror(*dir).Sync(tree)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Fprintln(os.Stderr, "no changes")
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "committed %d changes\n", len(changes))
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/tmc/notion"
+)
+
+func runShow(c *notion.Client, args []string) error {
+	fs := newFlagSet("show", "[flags] <page>")
+	width := fs.Int("width", 0, "wrap text to width characters, by default $COLUMNS or 80")
+	color := fs.String("color", "auto", "use colors: auto, always or never")
+	noPager := fs.Bool("no-pager", false, "don't pipe output through $PAGER")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	pageID, err := pageArg(fs)
+	if err != nil {
+		return err
+	}
+	page, err := c.GetPage(pageID)
+	if err != nil {
+		return err
+	}
+	tty := isTerminal(os.Stdout)
+	if *width == 0 {
+		*width = 80
+		if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+			*width = n
+		}
+	}
+
+	var out []byte
+	switch *color {
+	case "always":
+	case "auto":
+		if !tty || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+			*color = "never"
+		}
+	case "never":
+	default:
+		return fmt.Errorf("invalid -color %q", *color)
+	}
+	if *color == "never" {
+		out, err = notion.PrintAsText(page.Block, notion.WithWidth(*width))
+	} else {
+		out, err = notion.PrintAsANSI(page.Block, notion.WithWidth(*width))
+	}
+	if err != nil {
+		return err
+	}
+	if !tty || *noPager {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	return runPager(out)
+}
+
+// isTerminal returns true if f is a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// runPager shows text with $PAGER, less by default.
+func runPager(text []byte) error {
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less"
+	}
+	cmd := exec.Command("sh", "-c", pager)
+	if os.Getenv("LESS") == "" {
+		// keep colors and exit if the text fits on the screen
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = bytes.NewReader(text), os.Stdout, os.Stderr
+	return cmd.Run()
+}
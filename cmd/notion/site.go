@@ -0,0 +1,488 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tmc/notion"
+	"github.com/tmc/notion/notiontypes"
+)
+
+const siteManifestName = ".notion-site.json"
+
+func runSite(c *notion.Client, args []string) error {
+	if len(args) == 0 || args[0] != "build" {
+		return fmt.Errorf("usage: notion site build [flags] <root-page>")
+	}
+	fs := newFlagSet("site build", "[flags] <root-page>")
+	out := fs.String("o", "site", "output directory")
+	baseURL := fs.String("base-url", "", "absolute url the site is served from, used in the sitemap")
+	assets := fs.Bool("assets", true, "download images and files into the site")
+	full := fs.Bool("full", false, "rebuild all pages, even unchanged ones")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	rootID, err := pageArg(fs)
+	if err != nil {
+		return err
+	}
+	s := &siteBuilder{
+		client:  c,
+		out:     *out,
+		baseURL: strings.TrimRight(*baseURL, "/"),
+		cached:  map[string]bool{},
+	}
+	if *assets {
+		s.assets = notion.NewAssetDownloader(c, filepath.Join(*out, "assets"))
+		s.assets.Prefix = "assets/"
+	}
+	if !*full {
+		s.prev = readSiteManifest(*out)
+	}
+	return s.build(rootID)
+}
+
+// siteManifest records the state of a built site to allow incremental builds.
+type siteManifest struct {
+	NavHash string               `json:"nav_hash"`
+	Pages   map[string]*sitePage `json:"pages"`
+}
+
+type sitePage struct {
+	Title          string   `json:"title"`
+	Path           string   `json:"path"`
+	LastEditedTime int64    `json:"last_edited_time"`
+	Children       []string `json:"children,omitempty"`
+	Text           string   `json:"text"`
+}
+
+func readSiteManifest(dir string) *siteManifest {
+	b, err := ioutil.ReadFile(filepath.Join(dir, siteManifestName))
+	if err != nil {
+		return nil
+	}
+	m := &siteManifest{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil
+	}
+	return m
+}
+
+type siteBuilder struct {
+	client  *notion.Client
+	out     string
+	baseURL string
+	assets  *notion.AssetDownloader
+	prev    *siteManifest
+
+	// pages loaded from the previous manifest instead of notion.so
+	cached map[string]bool
+	// edit times of pages in the previous manifest, as currently on notion.so
+	editTimes map[string]int64
+
+	tree  *notion.PageTree
+	paths map[string]string
+}
+
+func (s *siteBuilder) build(rootID string) error {
+	if err := s.loadEditTimes(); err != nil {
+		return err
+	}
+	crawler := notion.NewCrawler(s.client)
+	crawler.LoadPage = s.loadPage
+	crawler.OnPage = func(t *notion.PageTree) {
+		fmt.Fprintf(os.Stderr, "%s%s\n", strings.Repeat("  ", t.Depth()), t.Page.Title)
+	}
+	tree, err := crawler.Crawl(rootID)
+	if err != nil {
+		return err
+	}
+	s.tree = tree
+	s.assignPaths(tree, "")
+
+	manifest := &siteManifest{NavHash: s.navHash(), Pages: map[string]*sitePage{}}
+	rebuildAll := s.prev == nil || s.prev.NavHash != manifest.NavHash
+	for _, t := range tree.Pages() {
+		id := t.Page.ID
+		if s.cached[id] && !rebuildAll {
+			manifest.Pages[id] = s.prev.Pages[id]
+			manifest.Pages[id].Path = s.paths[id]
+			continue
+		}
+		if s.cached[id] {
+			// navigation changed, the full page is needed to render it again
+			page, err := s.client.GetPage(id)
+			if err != nil {
+				return err
+			}
+			t.Page = page
+		}
+		text, err := s.writePage(t)
+		if err != nil {
+			return err
+		}
+		manifest.Pages[id] = &sitePage{
+			Title:          t.Page.Title,
+			Path:           s.paths[id],
+			LastEditedTime: t.Page.LastEditedTime,
+			Children:       notion.SubPageIDs(t.Page.Block),
+			Text:           text,
+		}
+	}
+	s.removeStalePages(manifest)
+	for _, write := range []func(*siteManifest) error{s.writeSitemap, s.writeSearchIndex, s.writeStatic, s.writeManifest} {
+		if err := write(manifest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadEditTimes fetches the current edit times of pages of the previous build.
+func (s *siteBuilder) loadEditTimes() error {
+	s.editTimes = map[string]int64{}
+	if s.prev == nil || len(s.prev.Pages) == 0 {
+		return nil
+	}
+	var ids []string
+	for id := range s.prev.Pages {
+		ids = append(ids, id)
+	}
+	editTimes, err := s.client.LastEditedTimes(ids)
+	if err != nil {
+		return err
+	}
+	s.editTimes = editTimes
+	return nil
+}
+
+// loadPage returns a stub of a page from the previous build if the page didn't
+// change since, and the page from notion.so otherwise.
+func (s *siteBuilder) loadPage(id string) (*notion.Page, error) {
+	if s.prev != nil {
+		p, ok := s.prev.Pages[id]
+		if t, edited := s.editTimes[id]; ok && edited && t == p.LastEditedTime {
+			s.cached[id] = true
+			block := &notiontypes.Block{ID: id, Type: notiontypes.BlockPage, Title: p.Title, LastEditedTime: t}
+			for _, child := range p.Children {
+				block.Content = append(block.Content, &notiontypes.Block{ID: child, Type: notiontypes.BlockPage, ParentID: id})
+			}
+			return &notion.Page{Block: block}, nil
+		}
+	}
+	return s.client.GetPage(id)
+}
+
+// assignPaths assigns output paths to pages, mirroring the page hierarchy.
+func (s *siteBuilder) assignPaths(t *notion.PageTree, dir string) {
+	if s.paths == nil {
+		s.paths = map[string]string{}
+	}
+	s.paths[t.Page.ID] = dir + "index.html"
+	used := map[string]bool{"assets": true}
+	for _, c := range t.Children {
+		slug := notion.Slugify(c.Page.Title)
+		if slug == "" {
+			slug = strings.Replace(c.Page.ID, "-", "", -1)
+		}
+		unique := slug
+		for i := 2; used[unique]; i++ {
+			unique = fmt.Sprintf("%s-%d", slug, i)
+		}
+		used[unique] = true
+		s.assignPaths(c, dir+unique+"/")
+	}
+}
+
+// navHash identifies the navigation of the site. If it changes, all pages
+// need to be rendered again.
+func (s *siteBuilder) navHash() string {
+	h := sha256.New()
+	for _, t := range s.tree.Pages() {
+		fmt.Fprintf(h, "%s\x00%s\x00%s\x00%d\n", t.Page.ID, t.Page.Title, s.paths[t.Page.ID], t.Depth())
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// relURL returns the url of target relative to the page at from. Both are
+// paths relative to the root of the site.
+func relURL(from, target string) string {
+	rel, err := filepath.Rel(path.Dir(from), target)
+	if err != nil {
+		return target
+	}
+	return filepath.ToSlash(rel)
+}
+
+// writePage renders a page and returns its text for the search index.
+func (s *siteBuilder) writePage(t *notion.PageTree) (string, error) {
+	p := s.paths[t.Page.ID]
+	opts := []notion.PrintOption{
+		notion.WithPageURLs(func(id string) string {
+			if target, ok := s.paths[id]; ok {
+				return relURL(p, target)
+			}
+			return notion.PageURL(id)
+		}),
+	}
+	if s.assets != nil {
+		if err := s.assets.Download(t.Page.Block); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+		opts = append(opts, notion.WithAssetURLs(func(u string) string {
+			if local := s.assets.URL(u); local != u {
+				return relURL(p, local)
+			}
+			return u
+		}))
+	}
+	content, err := notion.PrintAsHTML(t.Page.Block, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	data := sitePageData{
+		Title:   t.Page.Title,
+		Static:  strings.TrimSuffix(relURL(p, "style.css"), "style.css"),
+		Nav:     template.HTML(s.nav(s.tree, p)),
+		Content: template.HTML(content),
+	}
+	for a := t.Parent; a != nil; a = a.Parent {
+		crumb := siteLink{Title: a.Page.Title, URL: relURL(p, s.paths[a.Page.ID])}
+		data.Breadcrumbs = append([]siteLink{crumb}, data.Breadcrumbs...)
+	}
+	dst := filepath.Join(s.out, filepath.FromSlash(p))
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", err
+	}
+	f, err := os.Create(dst)
+	if err != nil {
+		return "", err
+	}
+	if err := sitePageTemplate.Execute(f, data); err != nil {
+		f.Close()
+		return "", err
+	}
+	return pageText(t.Page.Block), f.Close()
+}
+
+// nav renders the page hierarchy as nested lists, relative to the page at from.
+func (s *siteBuilder) nav(t *notion.PageTree, from string) string {
+	var sb strings.Builder
+	target := s.paths[t.Page.ID]
+	class := ""
+	if target == from {
+		class = ` class="current"`
+	}
+	fmt.Fprintf(&sb, `<li%s><a href="%s">%s</a>`, class, template.HTMLEscapeString(relURL(from, target)), template.HTMLEscapeString(t.Page.Title))
+	if len(t.Children) > 0 {
+		sb.WriteString("<ul>")
+		for _, c := range t.Children {
+			sb.WriteString(s.nav(c, from))
+		}
+		sb.WriteString("</ul>")
+	}
+	sb.WriteString("</li>")
+	if t.Parent == nil {
+		return "<ul>" + sb.String() + "</ul>"
+	}
+	return sb.String()
+}
+
+// removeStalePages removes pages of the previous build that are no longer
+// part of the site.
+func (s *siteBuilder) removeStalePages(m *siteManifest) {
+	if s.prev == nil {
+		return
+	}
+	current := map[string]bool{}
+	for _, p := range m.Pages {
+		current[p.Path] = true
+	}
+	for _, p := range s.prev.Pages {
+		if !current[p.Path] {
+			os.Remove(filepath.Join(s.out, filepath.FromSlash(p.Path)))
+		}
+	}
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemap struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+func (s *siteBuilder) writeSitemap(m *siteManifest) error {
+	sm := sitemap{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, t := range s.tree.Pages() {
+		p := m.Pages[t.Page.ID]
+		loc := strings.TrimSuffix(p.Path, "index.html")
+		sm.URLs = append(sm.URLs, sitemapURL{
+			Loc:     s.baseURL + "/" + loc,
+			LastMod: time.Unix(p.LastEditedTime/1000, 0).UTC().Format("2006-01-02"),
+		})
+	}
+	b, err := xml.MarshalIndent(sm, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(s.out, "sitemap.xml"), append([]byte(xml.Header), b...), 0644)
+}
+
+type searchEntry struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	Text  string `json:"text"`
+}
+
+func (s *siteBuilder) writeSearchIndex(m *siteManifest) error {
+	var entries []searchEntry
+	for _, t := range s.tree.Pages() {
+		p := m.Pages[t.Page.ID]
+		entries = append(entries, searchEntry{Title: p.Title, URL: p.Path, Text: p.Text})
+	}
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(s.out, "search-index.json"), b, 0644)
+}
+
+func (s *siteBuilder) writeStatic(*siteManifest) error {
+	if err := ioutil.WriteFile(filepath.Join(s.out, "style.css"), []byte(siteCSS), 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(s.out, "search.js"), []byte(siteSearchJS), 0644)
+}
+
+func (s *siteBuilder) writeManifest(m *siteManifest) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(s.out, siteManifestName), b, 0644)
+}
+
+// pageText returns the text of a page without formatting, excluding sub-pages.
+func pageText(page *notiontypes.Block) string {
+	var parts []string
+	notiontypes.InspectSynced(page, func(b *notiontypes.Block) bool {
+		if b != page && b.IsPage() {
+			return false
+		}
+		var sb strings.Builder
+		for _, ib := range b.InlineContent {
+			sb.WriteString(ib.Text)
+		}
+		for _, cell := range b.Cells {
+			for _, ib := range cell {
+				sb.WriteString(ib.Text)
+			}
+			sb.WriteString(" ")
+		}
+		sb.WriteString(b.Code)
+		if t := strings.TrimSpace(sb.String()); t != "" {
+			parts = append(parts, t)
+		}
+		return true
+	})
+	return strings.Join(parts, "\n")
+}
+
+type siteLink struct {
+	Title string
+	URL   string
+}
+
+type sitePageData struct {
+	Title       string
+	Static      string
+	Nav         template.HTML
+	Breadcrumbs []siteLink
+	Content     template.HTML
+}
+
+var sitePageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="{{.Static}}style.css">
+</head>
+<body>
+<nav class="site-nav">
+<input type="search" id="search" placeholder="Search" data-root="{{.Static}}">
+<ul id="search-results"></ul>
+{{.Nav}}
+</nav>
+<main>
+{{if .Breadcrumbs}}<nav class="breadcrumbs">{{range .Breadcrumbs}}<a href="{{.URL}}">{{.Title}}</a> / {{end}}{{.Title}}</nav>{{end}}
+{{.Content}}
+</main>
+<script src="{{.Static}}search.js"></script>
+</body>
+</html>
+`))
+
+const siteCSS = `body { display: flex; margin: 0; font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; line-height: 1.5; }
+.site-nav { width: 260px; padding: 1em; background: #f7f6f3; min-height: 100vh; box-sizing: border-box; }
+.site-nav ul { list-style: none; padding-left: 1em; }
+.site-nav .current > a { font-weight: bold; }
+main { max-width: 900px; padding: 1em 3em; flex: 1; }
+.page-cover { width: 100%; max-height: 30vh; object-fit: cover; }
+.callout { display: flex; padding: 1em; background: #f1f1ef; border-radius: 3px; }
+.callout .icon { margin-right: 0.5em; }
+.column-list { display: flex; gap: 1em; }
+.discussion { float: right; clear: right; width: 200px; margin-right: -240px; font-size: 0.85em; color: #666; }
+blockquote { border-left: 3px solid currentColor; padding-left: 1em; margin-left: 0; }
+pre { background: #f7f6f3; padding: 1em; overflow: auto; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ddd; padding: 0.25em 0.5em; }
+img { max-width: 100%; }
+`
+
+const siteSearchJS = `(function() {
+  var input = document.getElementById("search");
+  var results = document.getElementById("search-results");
+  var root = input.getAttribute("data-root");
+  var index = null;
+  input.addEventListener("input", function() {
+    var q = input.value.toLowerCase();
+    var show = function() {
+      results.innerHTML = "";
+      if (!q) return;
+      index.filter(function(e) {
+        return e.title.toLowerCase().indexOf(q) >= 0 || e.text.toLowerCase().indexOf(q) >= 0;
+      }).slice(0, 20).forEach(function(e) {
+        var li = document.createElement("li");
+        var a = document.createElement("a");
+        a.href = root + e.url;
+        a.textContent = e.title;
+        li.appendChild(a);
+        results.appendChild(li);
+      });
+    };
+    if (index) return show();
+    fetch(root + "search-index.json").then(function(r) { return r.json(); }).then(function(data) {
+      index = data;
+      show();
+    });
+  });
+})();
+`
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tmc/notion"
+)
+
+func runTree(c *notion.Client, args []string) error {
+	fs := newFlagSet("tree", "[flags] [root-page]")
+	depth := fs.Int("depth", 0, "maximum depth of sub-pages, 0 means no limit")
+	rows := fs.Bool("rows", false, "include rows of databases")
+	edited := fs.Bool("edited", true, "show when pages were last edited")
+	asJSON := fs.Bool("json", false, "print the tree as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	crawler := notion.NewCrawler(c)
+	crawler.MaxDepth = *depth
+	crawler.Rows = *rows
+
+	if fs.NArg() > 0 {
+		rootID, err := pageArg(fs)
+		if err != nil {
+			return err
+		}
+		tree, err := crawler.Crawl(rootID)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(newTreeNode(tree))
+		}
+		printTree(tree, "", "", *edited)
+		return nil
+	}
+
+	// all top-level pages of all spaces
+	records, err := c.LoadUserContent()
+	if err != nil {
+		return err
+	}
+	var spaces []*treeSpace
+	for _, s := range records.Space {
+		if s.Value == nil {
+			continue
+		}
+		space := &treeSpace{ID: s.Value.ID, Name: s.Value.Name, Pages: []*treeNode{}}
+		for _, id := range s.Value.Pages {
+			tree, err := crawler.Crawl(id)
+			if err != nil {
+				return err
+			}
+			space.trees = append(space.trees, tree)
+			space.Pages = append(space.Pages, newTreeNode(tree))
+		}
+		spaces = append(spaces, space)
+	}
+	sort.Slice(spaces, func(i, j int) bool { return spaces[i].Name < spaces[j].Name })
+	if *asJSON {
+		return printJSON(spaces)
+	}
+	for i, s := range spaces {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(s.Name)
+		for j, t := range s.trees {
+			if j == len(s.trees)-1 {
+				printTree(t, "└── ", "    ", *edited)
+			} else {
+				printTree(t, "├── ", "│   ", *edited)
+			}
+		}
+	}
+	return nil
+}
+
+// treeSpace is a space in JSON output.
+type treeSpace struct {
+	ID    string      `json:"id"`
+	Name  string      `json:"name"`
+	Pages []*treeNode `json:"pages"`
+
+	trees []*notion.PageTree
+}
+
+// treeNode is a page in JSON output.
+type treeNode struct {
+	ID         string      `json:"id"`
+	Title      string      `json:"title"`
+	Icon       string      `json:"icon,omitempty"`
+	URL        string      `json:"url"`
+	LastEdited time.Time   `json:"last_edited"`
+	Row        bool        `json:"row,omitempty"`
+	Children   []*treeNode `json:"children,omitempty"`
+}
+
+func newTreeNode(t *notion.PageTree) *treeNode {
+	n := &treeNode{
+		ID:         t.Page.ID,
+		Title:      t.Page.Title,
+		URL:        notion.PageURL(t.Page.ID),
+		LastEdited: t.Page.UpdatedOn().UTC(),
+		Row:        t.Page.Collection != nil,
+	}
+	if f := t.Page.FormatPage; f != nil {
+		n.Icon = f.PageIcon
+	}
+	for _, c := range t.Children {
+		n.Children = append(n.Children, newTreeNode(c))
+	}
+	return n
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printTree prints a page and its sub-pages as a tree, with marker before
+// the page and indent before its sub-pages.
+func printTree(t *notion.PageTree, marker string, indent string, edited bool) {
+	title := t.Page.Title
+	if title == "" {
+		title = "Untitled"
+	}
+	// icons that are urls of images can't be shown
+	if f := t.Page.FormatPage; f != nil && f.PageIcon != "" && !strings.HasPrefix(f.PageIcon, "http") && !strings.HasPrefix(f.PageIcon, "/") {
+		title = f.PageIcon + " " + title
+	}
+	if edited {
+		title += t.Page.UpdatedOn().Local().Format("  (2006-01-02 15:04)")
+	}
+	fmt.Println(marker + title)
+	for i, c := range t.Children {
+		if i == len(t.Children)-1 {
+			printTree(c, indent+"└── ", indent+"    ", edited)
+		} else {
+			printTree(c, indent+"├── ", indent+"│   ", edited)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tmc/notion"
+)
+
+// vaultExporter writes pages as notes of an Obsidian vault, which Logseq
+// can open as well. Notes mirror the page hierarchy: the sub-pages of
+// "A.md" are in the folder "A". Links between exported pages are wiki links
+// and blocks keep their ids as anchors, so links to blocks keep working.
+type vaultExporter struct {
+	dir    string
+	assets *notion.AssetDownloader
+
+	// paths of notes relative to dir without extension, by page id
+	paths map[string]string
+}
+
+func (e *vaultExporter) export(tree *notion.PageTree) error {
+	e.paths = map[string]string{}
+	e.assignPaths([]*notion.PageTree{tree}, "")
+	for _, t := range tree.Pages() {
+		if err := e.writePage(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// noteNameEscaper removes characters that can't be used in names of notes
+// or break wiki links.
+var noteNameEscaper = strings.NewReplacer(
+	"/", " ", `\`, " ", ":", " ", "*", "", "?", "", `"`, "", "<", "", ">", "",
+	"|", "", "#", "", "^", "", "[", "(", "]", ")",
+)
+
+// noteName returns the name of the note of a page.
+func noteName(page *notion.Page) string {
+	name := strings.Join(strings.Fields(noteNameEscaper.Replace(page.Title)), " ")
+	name = strings.Trim(name, ". ")
+	if name == "" {
+		name = "Untitled"
+	}
+	return name
+}
+
+// assignPaths assigns paths to pages with the same parent. Names of notes
+// are unique regardless of case, since vaults are often on case-insensitive
+// file systems.
+func (e *vaultExporter) assignPaths(pages []*notion.PageTree, dir string) {
+	used := map[string]bool{}
+	for _, t := range pages {
+		name := noteName(t.Page)
+		unique := name
+		for i := 2; used[strings.ToLower(unique)]; i++ {
+			unique = fmt.Sprintf("%s %d", name, i)
+		}
+		used[strings.ToLower(unique)] = true
+		e.paths[t.Page.ID] = dir + unique
+		e.assignPaths(t.Children, dir+unique+"/")
+	}
+}
+
+// target returns the wiki link target of an exported page, or "".
+func (e *vaultExporter) target(pageID string) string {
+	return e.paths[pageID]
+}
+
+func (e *vaultExporter) writePage(t *notion.PageTree) error {
+	p := e.paths[t.Page.ID]
+	opts := []notion.PrintOption{notion.WithoutTitle(), notion.WithWikiLinks(e.target), notion.WithBlockIDs()}
+	if e.assets != nil {
+		if err := e.assets.Download(t.Page.Block); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+		// attachments are referred to relative to the note
+		up := strings.Repeat("../", strings.Count(p, "/"))
+		opts = append(opts, notion.WithAssetURLs(func(u string) string {
+			if local := e.assets.URL(u); local != u {
+				return up + local
+			}
+			return u
+		}))
+	}
+	content, err := notion.PrintAsMarkdown(t.Page.Block, opts...)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := notion.PageFrontMatter(t.Page, opts...).WriteYAML(&buf); err != nil {
+		return err
+	}
+	buf.WriteString("\n")
+	buf.Write(content)
+
+	dst := filepath.Join(e.dir, filepath.FromSlash(p)+".md")
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dst, buf.Bytes(), 0644)
+}
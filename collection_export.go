@@ -0,0 +1,245 @@
+package notion
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// ExportFormat is a tabular format collections can be exported to.
+type ExportFormat string
+
+// Formats supported by ExportCollection.
+const (
+	// ExportCSV writes comma separated values with a header row.
+	ExportCSV ExportFormat = "csv"
+	// ExportTSV writes tab separated values with a header row.
+	ExportTSV ExportFormat = "tsv"
+	// ExportJSONL writes one JSON object per row, keyed by property name,
+	// with an "id" key holding the id of the row.
+	ExportJSONL ExportFormat = "jsonl"
+)
+
+// ExportCollection writes rows of a collection to w in the given format. Rows
+// are filtered and sorted as in view, and properties are ordered and hidden as
+// in the table properties of view.
+//
+// In CSV and TSV, multiple values are separated by ", ", people are written by
+// name and date ranges as start/end in ISO 8601. JSON Lines keeps the types of
+// values: numbers, booleans, arrays and dates as strings or objects with start
+// and end.
+func (c *Client) ExportCollection(collection *notiontypes.Collection, view *notiontypes.CollectionView, w io.Writer, format ExportFormat) error {
+	switch format {
+	case ExportCSV, ExportTSV, ExportJSONL:
+	default:
+		return fmt.Errorf("unknown export format %q", format)
+	}
+	rows, err := c.QueryCollection(collection.ID, view)
+	if err != nil {
+		return errors.Wrapf(err, "querying collection %v", collection.ID)
+	}
+	users := map[string]*notiontypes.User{}
+	ids := collectionUserIDs(collection, rows)
+	if len(ids) > 0 {
+		us, err := c.GetUsers(ids...)
+		if err != nil {
+			c.logger.WithError(err).Warnln("loading users failed")
+		}
+		for _, u := range us {
+			users[u.ID] = u
+		}
+	}
+	return writeCollection(w, format, collection, view, rows, users)
+}
+
+// collectionUserIDs returns ids of users referred to by properties of rows.
+func collectionUserIDs(collection *notiontypes.Collection, rows []*notiontypes.Block) []string {
+	var ids []string
+	seen := map[string]bool{}
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, row := range rows {
+		for _, p := range collection.RowProperties(row) {
+			switch v := p.Value.(type) {
+			case []string:
+				if p.Type == notiontypes.ColumnTypePerson {
+					for _, id := range v {
+						add(id)
+					}
+				}
+			case string:
+				if p.Type == notiontypes.ColumnTypeCreatedBy || p.Type == notiontypes.ColumnTypeLastEditedBy {
+					add(v)
+				}
+			}
+		}
+	}
+	return ids
+}
+
+func writeCollection(w io.Writer, format ExportFormat, collection *notiontypes.Collection, view *notiontypes.CollectionView, rows []*notiontypes.Block, users map[string]*notiontypes.User) error {
+	ids := collection.ViewPropertyIDs(view)
+	props := func(row *notiontypes.Block) []*notiontypes.Property {
+		result := make([]*notiontypes.Property, len(ids))
+		for i, id := range ids {
+			col := collection.CollectionSchema[id]
+			result[i] = &notiontypes.Property{ID: id, Name: col.Name, Type: col.Type, Value: notiontypes.PropertyValue(row, id, col.Type)}
+			result[i].Value = exportValue(result[i], users)
+		}
+		return result
+	}
+
+	switch format {
+	case ExportCSV, ExportTSV:
+		cw := csv.NewWriter(w)
+		if format == ExportTSV {
+			cw.Comma = '\t'
+		}
+		header := make([]string, len(ids))
+		for i, id := range ids {
+			header[i] = collection.CollectionSchema[id].Name
+		}
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			var record []string
+			for _, p := range props(row) {
+				record = append(record, exportText(p.Value))
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case ExportJSONL:
+		enc := json.NewEncoder(w)
+		for _, row := range rows {
+			obj := orderedObject{{"id", row.ID}}
+			for _, p := range props(row) {
+				obj = append(obj, orderedField{p.Name, p.Value})
+			}
+			if err := enc.Encode(obj); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
+
+// exportDate is a date range in exported rows.
+type exportDate struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// exportValue converts the value of a property for export: users are
+// replaced by names, dates and times by ISO 8601 strings.
+func exportValue(p *notiontypes.Property, users map[string]*notiontypes.User) interface{} {
+	userName := func(id string) string {
+		if u, ok := users[id]; ok {
+			return u.Name()
+		}
+		return id
+	}
+	switch v := p.Value.(type) {
+	case *notiontypes.Date:
+		start, err := v.Start()
+		if err != nil {
+			return v.StartDate
+		}
+		if !v.IsRange() || v.EndDate == "" {
+			return isoDate(v, start)
+		}
+		end, err := v.End()
+		if err != nil {
+			return isoDate(v, start)
+		}
+		return exportDate{Start: isoDate(v, start), End: isoDate(v, end)}
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case []string:
+		if p.Type == notiontypes.ColumnTypePerson {
+			names := make([]string, len(v))
+			for i, id := range v {
+				names[i] = userName(id)
+			}
+			return names
+		}
+	case string:
+		if p.Type == notiontypes.ColumnTypeCreatedBy || p.Type == notiontypes.ColumnTypeLastEditedBy {
+			return userName(v)
+		}
+	}
+	return p.Value
+}
+
+func isoDate(d *notiontypes.Date, t time.Time) string {
+	if d.HasTime() {
+		return t.Format(time.RFC3339)
+	}
+	return t.Format("2006-01-02")
+}
+
+// exportText formats a value returned by exportValue as text.
+func exportText(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []string:
+		return strings.Join(v, ", ")
+	case exportDate:
+		return v.Start + "/" + v.End
+	}
+	return fmt.Sprint(v)
+}
+
+type orderedField struct {
+	Key   string
+	Value interface{}
+}
+
+// orderedObject is a JSON object that keeps the order of its keys.
+type orderedObject []orderedField
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i, f := range o {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		k, err := json.Marshal(f.Key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		sb.Write(k)
+		sb.WriteString(":")
+		sb.Write(v)
+	}
+	sb.WriteString("}")
+	return []byte(sb.String()), nil
+}
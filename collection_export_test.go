@@ -0,0 +1,84 @@
+package notion
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestWriteCollection(t *testing.T) {
+	var rows []*notiontypes.Block
+	if err := json.Unmarshal([]byte(`[
+		{"id": "r1", "type": "page", "properties": {"title": [["First, row"]], "n": [["1.5"]], "done": [["Yes"]], "who": [["‣", [["u", "u1"]]]],
+		 "when": [["‣", [["d", {"type": "daterange", "start_date": "2020-03-01", "end_date": "2020-03-02"}]]]]}},
+		{"id": "r2", "type": "page", "properties": {"title": [["Second"]], "secret": [["x"]]}},
+		{"id": "r3", "type": "page", "properties": {"title": [["Third"]], "when": [["‣", [["d", {"type": "daterange", "start_date": "2020-04-01"}]]]]}}
+	]`), &rows); err != nil {
+		t.Fatal(err)
+	}
+	coll := &notiontypes.Collection{
+		CollectionSchema: map[string]*notiontypes.CollectionColumnInfo{
+			"title":  {Name: "Name", Type: notiontypes.ColumnTypeTitle},
+			"n":      {Name: "Count", Type: notiontypes.ColumnTypeNumber},
+			"done":   {Name: "Done", Type: notiontypes.ColumnTypeCheckbox},
+			"who":    {Name: "Owner", Type: notiontypes.ColumnTypePerson},
+			"when":   {Name: "When", Type: notiontypes.ColumnTypeDate},
+			"secret": {Name: "Secret", Type: notiontypes.ColumnTypeText},
+		},
+	}
+	view := &notiontypes.CollectionView{Format: &notiontypes.CollectionViewFormat{
+		TableProperties: []*notiontypes.TableProperty{
+			{Property: "n", Visible: true},
+			{Property: "title", Visible: true},
+			{Property: "secret", Visible: false},
+			{Property: "done", Visible: true},
+			{Property: "who", Visible: true},
+			{Property: "when", Visible: true},
+		},
+	}}
+	users := map[string]*notiontypes.User{"u1": {ID: "u1", GivenName: "Ada", FamilyName: "Lovelace"}}
+
+	tests := []struct {
+		format ExportFormat
+		want   string
+	}{
+		{ExportCSV, `Count,Name,Done,Owner,When
+1.5,"First, row",true,Ada Lovelace,2020-03-01/2020-03-02
+,Second,false,,
+,Third,false,,2020-04-01
+`},
+		{ExportJSONL, `{"id":"r1","Count":1.5,"Name":"First, row","Done":true,"Owner":["Ada Lovelace"],"When":{"start":"2020-03-01","end":"2020-03-02"}}
+{"id":"r2","Count":null,"Name":"Second","Done":false,"Owner":null,"When":null}
+{"id":"r3","Count":null,"Name":"Third","Done":false,"Owner":null,"When":"2020-04-01"}
+`},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := writeCollection(&buf, tt.format, coll, view, rows, users); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s:\n%s\nwant:\n%s", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestQueryCollectionTooManyRows(t *testing.T) {
+	responses := map[string]string{
+		"queryCollection": `{"result":{"blockIds":["r1"],"total":1},"recordMap":{"block":{
+			"r1":{"value":{"id":"r1","type":"page","properties":{"title":[["One"]]}}}}}}`,
+	}
+	c, _ := newTestClient(t, responses)
+	view := &notiontypes.CollectionView{ID: "v"}
+	rows, err := c.QueryCollection("c", view)
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("got %d rows, %v", len(rows), err)
+	}
+	responses["queryCollection"] = `{"result":{"blockIds":["r1"],"total":10001},"recordMap":{"block":{
+		"r1":{"value":{"id":"r1","type":"page","properties":{"title":[["One"]]}}}}}}`
+	if _, err := c.QueryCollection("c", view); err == nil {
+		t.Error("no error for a truncated result")
+	}
+}
@@ -0,0 +1,171 @@
+package notion
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+type queryCollectionLoader struct {
+	Type         string `json:"type"`
+	Limit        int    `json:"limit"`
+	SearchQuery  string `json:"searchQuery"`
+	UserTimeZone string `json:"userTimeZone"`
+}
+
+type queryCollectionRequest struct {
+	CollectionID     string                           `json:"collectionId"`
+	CollectionViewID string                           `json:"collectionViewId"`
+	Query            *notiontypes.CollectionViewQuery `json:"query"`
+	Loader           queryCollectionLoader            `json:"loader"`
+}
+
+type queryCollectionResponse struct {
+	Result struct {
+		BlockIDs []string `json:"blockIds"`
+		Total    int      `json:"total"`
+	} `json:"result"`
+	RecordMap notiontypes.RecordMap `json:"recordMap"`
+}
+
+// maxCollectionRows is the maximum number of rows that QueryCollection
+// returns, as notion.so doesn't page results of queries.
+const maxCollectionRows = 10000
+
+// QueryCollection returns rows of a collection, filtered and sorted as in the
+// given view of the collection. Rows have their properties resolved but not
+// their content; use GetPage to get the content of a row. Queries of more than
+// 10000 rows fail rather than return some of the rows.
+func (c *Client) QueryCollection(collectionID string, view *notiontypes.CollectionView) ([]*notiontypes.Block, error) {
+	query := view.Query
+	if query == nil {
+		query = &notiontypes.CollectionViewQuery{}
+	}
+	req := queryCollectionRequest{
+		CollectionID:     collectionID,
+		CollectionViewID: view.ID,
+		Query:            query,
+		Loader: queryCollectionLoader{
+			Type:         "table",
+			Limit:        maxCollectionRows + 1,
+			UserTimeZone: "UTC",
+		},
+	}
+	b, err := c.post(req, "queryCollection")
+	if err != nil {
+		return nil, err
+	}
+	r := &queryCollectionResponse{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling queryCollectionResponse")
+	}
+	if n := len(r.Result.BlockIDs); n > maxCollectionRows || r.Result.Total > n {
+		return nil, errors.Errorf("collection %v has more than %d rows", collectionID, maxCollectionRows)
+	}
+	blocks := make(map[string]*notiontypes.Block, len(r.RecordMap.Blocks))
+	for k, v := range r.RecordMap.Blocks {
+		if v.Value != nil {
+			blocks[k] = v.Value
+		}
+	}
+	var rows []*notiontypes.Block
+	for _, id := range r.Result.BlockIDs {
+		row, ok := blocks[id]
+		if !ok {
+			continue
+		}
+		// content of rows is not part of the response
+		row.Content = []*notiontypes.Block{}
+		if err := notiontypes.ResolveBlock(row, blocks); err != nil {
+			return nil, errors.Wrap(err, "resolving collection row")
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// GetCollections returns collections with the given ids. Collections that
+// don't exist or are not accessible are skipped.
+func (c *Client) GetCollections(ids ...string) ([]*notiontypes.Collection, error) {
+	var result []*notiontypes.Collection
+	err := c.getRecordsOf(notiontypes.TableCollection, ids, func(v json.RawMessage) error {
+		coll := &notiontypes.Collection{}
+		result = append(result, coll)
+		return json.Unmarshal(v, coll)
+	})
+	return result, err
+}
+
+// isCollectionView returns true if b displays a collection.
+func isCollectionView(b *notiontypes.Block) bool {
+	return b.Type == notiontypes.BlockCollectionView || b.Type == notiontypes.BlockCollectionViewPage
+}
+
+// resolveCollections populates CollectionViews of collection view blocks in
+// the page and Collection of pages that are rows of a collection.
+func (c *Client) resolveCollections(page *Page, rm *notiontypes.RecordMap) error {
+	collection := func(id string) (*notiontypes.Collection, error) {
+		if v, ok := rm.Collections[id]; ok && v.Value != nil {
+			return v.Value, nil
+		}
+		colls, err := c.GetCollections(id)
+		if err != nil || len(colls) == 0 {
+			return nil, err
+		}
+		rm.Collections[id] = &notiontypes.CollectionWithRole{Value: colls[0]}
+		return colls[0], nil
+	}
+
+	if page.ParentTable == notiontypes.TableCollection {
+		coll, err := collection(page.ParentID)
+		if err != nil {
+			return err
+		}
+		page.Collection = coll
+	}
+
+	var views []*notiontypes.Block
+	notiontypes.InspectSynced(page.Block, func(b *notiontypes.Block) bool {
+		// full page databases are sub-pages, resolved when loaded
+		if b != page.Block && (b.IsPage() || b.Type == notiontypes.BlockCollectionViewPage) {
+			return false
+		}
+		if isCollectionView(b) {
+			views = append(views, b)
+		}
+		return true
+	})
+
+	for _, b := range views {
+		if b.CollectionID == "" || b.CollectionViews != nil {
+			continue
+		}
+		coll, err := collection(b.CollectionID)
+		if err != nil {
+			return err
+		}
+		if coll == nil {
+			continue
+		}
+		if b.Title == "" {
+			b.Title = coll.Title()
+		}
+		for _, viewID := range b.ViewIDs {
+			v, ok := rm.CollectionViews[viewID]
+			if !ok || v.Value == nil {
+				continue
+			}
+			rows, err := c.QueryCollection(coll.ID, v.Value)
+			if err != nil {
+				return errors.Wrapf(err, "querying collection %v", coll.ID)
+			}
+			b.CollectionViews = append(b.CollectionViews, &notiontypes.CollectionViewInfo{
+				CollectionView: v.Value,
+				Collection:     coll,
+				CollectionRows: rows,
+			})
+		}
+	}
+	return nil
+}
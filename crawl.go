@@ -0,0 +1,129 @@
+package notion
+
+import (
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// PageTree is a page and its sub-pages.
+type PageTree struct {
+	Page     *Page
+	Parent   *PageTree
+	Children []*PageTree
+}
+
+// Depth returns the number of ancestors of the page in the tree.
+func (t *PageTree) Depth() int {
+	d := 0
+	for p := t.Parent; p != nil; p = p.Parent {
+		d++
+	}
+	return d
+}
+
+// Pages returns pages of the tree in depth-first order, starting with t.
+func (t *PageTree) Pages() []*PageTree {
+	result := []*PageTree{t}
+	for _, c := range t.Children {
+		result = append(result, c.Pages()...)
+	}
+	return result
+}
+
+// SubPageIDs returns ids of sub-pages of a page, in order. Full page
+// databases are included, links to pages are not.
+func SubPageIDs(page *notiontypes.Block) []string {
+	var ids []string
+	notiontypes.Inspect(page, func(b *notiontypes.Block) bool {
+		if b == page {
+			return true
+		}
+		if b.IsPage() || b.Type == notiontypes.BlockCollectionViewPage {
+			if b.ParentID == page.ID || b.ParentID == "" {
+				ids = append(ids, b.ID)
+			}
+			return false
+		}
+		// sub-pages can be nested in columns, toggles etc.
+		return true
+	})
+	return ids
+}
+
+// CollectionRowIDs returns ids of rows of collections displayed in a page,
+// in the order of the first view of each collection.
+func CollectionRowIDs(page *notiontypes.Block) []string {
+	var ids []string
+	seen := map[string]bool{}
+	notiontypes.Inspect(page, func(b *notiontypes.Block) bool {
+		if b != page && b.IsPage() {
+			return false
+		}
+		if len(b.CollectionViews) > 0 {
+			for _, row := range b.CollectionViews[0].CollectionRows {
+				if !seen[row.ID] {
+					seen[row.ID] = true
+					ids = append(ids, row.ID)
+				}
+			}
+		}
+		return true
+	})
+	return ids
+}
+
+// Crawler fetches a page and its sub-pages, recursively.
+type Crawler struct {
+	// MaxDepth limits the depth of sub-pages that are fetched, 0 means no limit.
+	MaxDepth int
+	// Rows makes rows of collections (databases) children of the page that
+	// displays the collection.
+	Rows bool
+	// LoadPage loads a page. It defaults to Client.GetPage and can be replaced
+	// e.g. to serve unchanged pages from a cache. Pages returned by LoadPage
+	// must have their sub-pages in Content.
+	LoadPage func(pageID string) (*Page, error)
+	// OnPage, if set, is called after each page is loaded.
+	OnPage func(t *PageTree)
+}
+
+// NewCrawler initializes a new Crawler that fetches pages using c.
+func NewCrawler(c *Client) *Crawler {
+	return &Crawler{LoadPage: c.GetPage}
+}
+
+// Crawl fetches the page with the given id and its sub-pages.
+func (cr *Crawler) Crawl(rootID string) (*PageTree, error) {
+	seen := map[string]bool{}
+	var crawl func(id string, parent *PageTree) (*PageTree, error)
+	crawl = func(id string, parent *PageTree) (*PageTree, error) {
+		seen[id] = true
+		page, err := cr.LoadPage(id)
+		if err != nil {
+			return nil, errors.Wrapf(err, "loading page %v", id)
+		}
+		t := &PageTree{Page: page, Parent: parent}
+		if cr.OnPage != nil {
+			cr.OnPage(t)
+		}
+		if cr.MaxDepth > 0 && t.Depth() >= cr.MaxDepth {
+			return t, nil
+		}
+		childIDs := SubPageIDs(page.Block)
+		if cr.Rows {
+			childIDs = append(childIDs, CollectionRowIDs(page.Block)...)
+		}
+		for _, childID := range childIDs {
+			if seen[childID] {
+				continue
+			}
+			child, err := crawl(childID, t)
+			if err != nil {
+				return nil, err
+			}
+			t.Children = append(t.Children, child)
+		}
+		return t, nil
+	}
+	return crawl(rootID, nil)
+}
@@ -0,0 +1,49 @@
+package notion
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCrawler(t *testing.T) {
+	c, requests := newTestClient(t, map[string]string{
+		"loadPageChunk": `{"cursor":{"stack":[]},"recordMap":{"block":{
+			"r":{"value":{"id":"r","type":"page","alive":true,"properties":{"title":[["Root"]]},"content":["a","t","b"]}},
+			"a":{"value":{"id":"a","type":"page","alive":true,"parent_id":"r","properties":{"title":[["A"]]},"content":["c","l"]}},
+			"t":{"value":{"id":"t","type":"text","alive":true,"parent_id":"r","properties":{"title":[["text"]]}}},
+			"b":{"value":{"id":"b","type":"page","alive":true,"parent_id":"r","properties":{"title":[["B"]]}}},
+			"c":{"value":{"id":"c","type":"page","alive":true,"parent_id":"a","properties":{"title":[["C"]]}}},
+			"l":{"value":{"id":"l","type":"alias","alive":true,"parent_id":"a","format":{"alias_pointer":{"id":"r"}}}}}}}`,
+	})
+	crawl := func(cr *Crawler) string {
+		t.Helper()
+		var visited []string
+		cr.OnPage = func(p *PageTree) {
+			visited = append(visited, strings.Repeat(" ", p.Depth())+p.Page.Title)
+		}
+		tree, err := cr.Crawl("r")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var pages []string
+		for _, p := range tree.Pages() {
+			pages = append(pages, strings.Repeat(" ", p.Depth())+p.Page.Title)
+		}
+		if strings.Join(visited, "|") != strings.Join(pages, "|") {
+			t.Errorf("OnPage called for %q, want %q", visited, pages)
+		}
+		return strings.Join(pages, "|")
+	}
+
+	if got, want := crawl(NewCrawler(c)), "Root| A|  C| B"; got != want {
+		t.Errorf("tree = %q, want %q", got, want)
+	}
+	if n := len(requests["loadPageChunk"]); n != 4 {
+		t.Errorf("%d pages loaded, want 4", n)
+	}
+	cr := NewCrawler(c)
+	cr.MaxDepth = 1
+	if got, want := crawl(cr), "Root| A| B"; got != want {
+		t.Errorf("tree with MaxDepth 1 = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,527 @@
+package notion
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// maxSelectOptions is the maximum number of distinct values of a column that
+// is inferred to be a select.
+const maxSelectOptions = 20
+
+// optionColors are colors assigned to options of select columns, in order.
+var optionColors = []string{"default", "gray", "brown", "orange", "yellow", "green", "blue", "purple", "pink", "red"}
+
+// csvDateLayouts are layouts of dates recognized in CSV files, the first two
+// without time of day.
+var csvDateLayouts = []string{
+	"2006-01-02",
+	"01/02/2006",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+}
+
+var (
+	csvEmail  = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	csvNumber = regexp.MustCompile(`^-?\d{1,3}(,\d{3})+(\.\d+)?$`)
+)
+
+// parseCSVNumber parses numbers like "-12.5" and "1,234.5".
+func parseCSVNumber(s string) (float64, bool) {
+	if csvNumber.MatchString(s) {
+		s = strings.Replace(s, ",", "", -1)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil
+}
+
+func parseCSVBool(s string) (bool, bool) {
+	switch strings.ToLower(s) {
+	case "yes", "true", "x", "checked", "✓", "✔":
+		return true, true
+	case "no", "false", "unchecked", "":
+		return false, true
+	}
+	return false, false
+}
+
+// parseCSVDate parses a date in one of csvDateLayouts, or a range of dates
+// written as start/end as in files exported by ExportCollection.
+func parseCSVDate(s string) (*notiontypes.Date, bool) {
+	if d, ok := parseCSVSingleDate(s); ok {
+		return d, true
+	}
+	for i := strings.Index(s, "/"); i >= 0; i = nextIndex(s, "/", i) {
+		start, ok1 := parseCSVSingleDate(s[:i])
+		end, ok2 := parseCSVSingleDate(s[i+1:])
+		if !ok1 || !ok2 {
+			continue
+		}
+		start.EndDate = end.StartDate
+		start.EndTime = end.StartTime
+		if start.Type == notiontypes.DateTypeDateTime {
+			start.Type = notiontypes.DateTypeDateTimeRange
+		} else {
+			start.Type = notiontypes.DateTypeDateRange
+		}
+		return start, true
+	}
+	return nil, false
+}
+
+// nextIndex returns the index of the next sep in s after index i, or -1.
+func nextIndex(s string, sep string, i int) int {
+	j := strings.Index(s[i+1:], sep)
+	if j < 0 {
+		return -1
+	}
+	return i + 1 + j
+}
+
+func parseCSVSingleDate(s string) (*notiontypes.Date, bool) {
+	for i, layout := range csvDateLayouts {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		d := &notiontypes.Date{Type: notiontypes.DateTypeDate, StartDate: t.Format("2006-01-02")}
+		if i >= 2 {
+			tm := t.UTC().Format("15:04")
+			d.Type = notiontypes.DateTypeDateTime
+			d.StartDate = t.UTC().Format("2006-01-02")
+			d.StartTime = &tm
+		}
+		return d, true
+	}
+	return nil, false
+}
+
+func isCSVURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// splitMultiSelect splits a cell of a multi-select column into its values.
+func splitMultiSelect(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// inferColumnType returns the type of a column with the given values.
+func inferColumnType(values []string) string {
+	var nonEmpty []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			nonEmpty = append(nonEmpty, v)
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return notiontypes.ColumnTypeText
+	}
+	all := func(fn func(string) bool) bool {
+		for _, v := range nonEmpty {
+			if !fn(v) {
+				return false
+			}
+		}
+		return true
+	}
+	switch {
+	case all(func(s string) bool { _, ok := parseCSVNumber(s); return ok }):
+		return notiontypes.ColumnTypeNumber
+	case all(func(s string) bool { _, ok := parseCSVBool(s); return ok }):
+		return notiontypes.ColumnTypeCheckbox
+	case all(func(s string) bool { _, ok := parseCSVDate(s); return ok }):
+		return notiontypes.ColumnTypeDate
+	case all(isCSVURL):
+		return notiontypes.ColumnTypeURL
+	case all(csvEmail.MatchString):
+		return notiontypes.ColumnTypeEmail
+	}
+	// columns with few, repeated values are selects
+	distinct := map[string]bool{}
+	multi := false
+	tokens := 0
+	for _, v := range nonEmpty {
+		if strings.Contains(v, ",") {
+			multi = true
+		}
+		for _, t := range splitMultiSelect(v) {
+			distinct[t] = true
+			tokens++
+		}
+	}
+	if len(distinct) <= maxSelectOptions && len(distinct) < tokens {
+		if multi {
+			return notiontypes.ColumnMultiSelect
+		}
+		return notiontypes.ColumnTypeSelect
+	}
+	return notiontypes.ColumnTypeText
+}
+
+// addOptions adds options for values of a select or multi-select column that
+// are not options yet, and returns true if any was added.
+func addOptions(col *notiontypes.CollectionColumnInfo, values []string) bool {
+	added := false
+	for _, v := range values {
+		exists := false
+		for _, o := range col.Options {
+			if o.Value == v {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			col.Options = append(col.Options, &notiontypes.CollectionColumnOption{
+				ID:    NewID(),
+				Color: optionColors[len(col.Options)%len(optionColors)],
+				Value: v,
+			})
+			added = true
+		}
+	}
+	return added
+}
+
+// InferSchema infers the schema of a collection from the header and records
+// of a CSV file. The first column is the title. Other columns are numbers,
+// checkboxes, dates, urls or emails if all their values can be parsed as
+// such. Columns with a few distinct, repeated values are selects, or
+// multi-selects if values are comma separated lists. Other columns are text.
+//
+// It returns the ids of properties in the order of the columns, and the
+// schema.
+func InferSchema(header []string, records [][]string) ([]string, map[string]*notiontypes.CollectionColumnInfo) {
+	schema := map[string]*notiontypes.CollectionColumnInfo{}
+	var ids []string
+	for i, name := range header {
+		id := "title"
+		col := &notiontypes.CollectionColumnInfo{Name: name, Type: notiontypes.ColumnTypeTitle}
+		if i > 0 {
+			// property ids are short random strings
+			for id == "title" || schema[id] != nil {
+				id = NewID()[:4]
+			}
+			var values []string
+			for _, r := range records {
+				if i < len(r) {
+					values = append(values, r[i])
+				}
+			}
+			col.Type = inferColumnType(values)
+			if col.Type == notiontypes.ColumnTypeSelect || col.Type == notiontypes.ColumnMultiSelect {
+				for _, v := range values {
+					addOptions(col, splitMultiSelect(v))
+				}
+			}
+		}
+		schema[id] = col
+		ids = append(ids, id)
+	}
+	return ids, schema
+}
+
+// parseCSVValue parses a CSV cell as a value of a property of the given
+// type, see notiontypes.Property for types of values. Empty cells are nil.
+func parseCSVValue(columnType string, s string) (interface{}, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	switch columnType {
+	case notiontypes.ColumnTypeNumber:
+		if f, ok := parseCSVNumber(s); ok {
+			return f, nil
+		}
+	case notiontypes.ColumnTypeCheckbox:
+		if b, ok := parseCSVBool(s); ok {
+			return b, nil
+		}
+	case notiontypes.ColumnTypeDate:
+		if d, ok := parseCSVDate(s); ok {
+			return d, nil
+		}
+	case notiontypes.ColumnMultiSelect, notiontypes.ColumnTypeFile:
+		return splitMultiSelect(s), nil
+	default:
+		if !importable(columnType) {
+			return nil, fmt.Errorf("importing %s properties is not supported", columnType)
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("%q is not a valid %s", s, columnType)
+}
+
+// importable returns true if values of properties of the given type can be
+// imported from text.
+func importable(columnType string) bool {
+	switch columnType {
+	case notiontypes.ColumnTypePerson, notiontypes.ColumnTypeRelation, notiontypes.ColumnTypeFormula,
+		notiontypes.ColumnTypeRollup, notiontypes.ColumnTypeCreatedTime, notiontypes.ColumnTypeCreatedBy,
+		notiontypes.ColumnTypeLastEditedTime, notiontypes.ColumnTypeLastEditedBy:
+		return false
+	}
+	return true
+}
+
+// readCSV reads the header and records of a CSV file.
+func readCSV(r io.Reader) ([]string, [][]string, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "reading csv")
+	}
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("csv has no header")
+	}
+	return records[0], records[1:], nil
+}
+
+// rowProperties returns raw properties of a row from a CSV record, for
+// columns mapped to property ids. Cells that can't be converted are reported
+// as errors, empty cells are skipped.
+func rowProperties(schema map[string]*notiontypes.CollectionColumnInfo, ids []string, record []string) (map[string]interface{}, error) {
+	props := map[string]interface{}{}
+	for i, id := range ids {
+		if id == "" || i >= len(record) {
+			continue
+		}
+		col := schema[id]
+		v, err := parseCSVValue(col.Type, record[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "column %q", col.Name)
+		}
+		if v != nil {
+			props[id] = notiontypes.RawPropertyValue(col.Type, v)
+		}
+	}
+	return props, nil
+}
+
+// rowKey returns the value of a property of a row as text, as it is
+// exported, for matching records of a CSV file to existing rows.
+func rowKey(row *notiontypes.Block, id string, columnType string) string {
+	p := &notiontypes.Property{ID: id, Type: columnType, Value: notiontypes.PropertyValue(row, id, columnType)}
+	return exportText(exportValue(p, nil))
+}
+
+// csvRowKey returns the key of a record of a CSV file from its properties
+// returned by rowProperties, normalized like keys of existing rows so that
+// e.g. "42.0" matches 42 and "Yes" matches true.
+func csvRowKey(props map[string]interface{}, id string, columnType string) (string, error) {
+	v, ok := props[id]
+	if !ok {
+		return "", nil
+	}
+	// properties are compared in the form they are loaded in
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	var raw interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return "", err
+	}
+	row := &notiontypes.Block{Properties: map[string]interface{}{id: raw}}
+	return rowKey(row, id, columnType), nil
+}
+
+// CreateCollectionFromCSV creates an inline database with the given title at
+// the end of the page with id parentID, with a schema inferred from the CSV
+// file read from r (see InferSchema), a table view showing all columns and
+// a row for each record.
+func (c *Client) CreateCollectionFromCSV(parentID string, title string, r io.Reader) (*notiontypes.Collection, error) {
+	header, records, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	ids, schema := InferSchema(header, records)
+	userID, err := c.currentUserID()
+	if err != nil {
+		return nil, err
+	}
+	ts := now()
+	block := &notiontypes.Block{ID: NewID(), Type: notiontypes.BlockCollectionView, CollectionID: NewID()}
+	coll := &notiontypes.Collection{
+		ID:               block.CollectionID,
+		Alive:            true,
+		Name:             [][]string{{title}},
+		ParentID:         block.ID,
+		ParentTable:      notiontypes.TableBlock,
+		CollectionSchema: schema,
+		Version:          1,
+	}
+	view := &notiontypes.CollectionView{
+		ID:          NewID(),
+		Alive:       true,
+		Name:        "Table",
+		Type:        "table",
+		ParentID:    block.ID,
+		ParentTable: notiontypes.TableBlock,
+		Format:      &notiontypes.CollectionViewFormat{},
+		Version:     1,
+	}
+	block.ViewIDs = []string{view.ID}
+	for _, id := range ids {
+		view.Format.TableProperties = append(view.Format.TableProperties, &notiontypes.TableProperty{Property: id, Visible: true, Width: 200})
+	}
+
+	groups := createBlockOps(block, parentID, notiontypes.TableBlock, "", userID, ts)
+	groups[0] = append(groups[0],
+		&Operation{ID: coll.ID, Table: notiontypes.TableCollection, Path: []string{}, Command: CommandSet, Args: coll},
+		&Operation{ID: view.ID, Table: notiontypes.TableCollectionView, Path: []string{}, Command: CommandSet, Args: view},
+	)
+	for n, record := range records {
+		props, err := rowProperties(schema, ids, record)
+		if err != nil {
+			return nil, errors.Wrapf(err, "record %d", n+1)
+		}
+		row := &notiontypes.Block{Type: notiontypes.BlockPage, Properties: props}
+		groups = append(groups, createBlockOps(row, coll.ID, notiontypes.TableCollection, "", userID, ts)...)
+	}
+	if err := c.submitInChunks(groups); err != nil {
+		return nil, errors.Wrap(err, "creating collection")
+	}
+	return coll, nil
+}
+
+// ImportCSV imports records of the CSV file read from r into an existing
+// collection. Columns are mapped to properties by name, ignoring case;
+// columns without a property are skipped. Records are matched to rows by
+// the value of keyColumn, or of the title if keyColumn is empty: matching rows
+// are updated, other records are inserted as new rows. Empty cells don't
+// clear values of existing rows. Values of select columns that are not
+// options yet are added as options.
+//
+// view is used to query existing rows of the collection, without its filter
+// so that all rows are matched. Collections of more than 10000 rows can't be
+// queried completely and are not imported into, as records would be inserted
+// as duplicates of rows that weren't returned.
+func (c *Client) ImportCSV(coll *notiontypes.Collection, view *notiontypes.CollectionView, r io.Reader, keyColumn string) error {
+	header, records, err := readCSV(r)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, len(header))
+	key := -1
+	for i, name := range header {
+		for id, col := range coll.CollectionSchema {
+			if strings.EqualFold(strings.TrimSpace(name), col.Name) {
+				ids[i] = id
+			}
+		}
+		if ids[i] == "" {
+			c.logger.WithField("column", name).Warnln("skipping column, it's not a property of the collection")
+			continue
+		}
+		if t := coll.CollectionSchema[ids[i]].Type; !importable(t) {
+			c.logger.WithField("column", name).Warnln("skipping column, importing", t, "properties is not supported")
+			ids[i] = ""
+			continue
+		}
+		if (keyColumn == "" && ids[i] == "title") || (keyColumn != "" && strings.EqualFold(keyColumn, name)) {
+			key = i
+		}
+	}
+	if key < 0 {
+		return fmt.Errorf("key column %q is not in the csv file", keyColumn)
+	}
+	keyID := ids[key]
+	keyCol := coll.CollectionSchema[keyID]
+
+	// rows hidden by the filter of the view are matched too
+	all := &notiontypes.CollectionView{ID: view.ID, Type: view.Type}
+	rows, err := c.QueryCollection(coll.ID, all)
+	if err != nil {
+		return errors.Wrapf(err, "querying collection %v", coll.ID)
+	}
+	existing := map[string]string{}
+	for _, row := range rows {
+		if k := rowKey(row, keyID, keyCol.Type); k != "" {
+			existing[k] = row.ID
+		}
+	}
+
+	userID, err := c.currentUserID()
+	if err != nil {
+		return err
+	}
+	ts := now()
+	var groups [][]*Operation
+	changedOptions := map[string]bool{}
+	for n, record := range records {
+		for i, id := range ids {
+			if id == "" || i >= len(record) {
+				continue
+			}
+			col := coll.CollectionSchema[id]
+			if col.Type == notiontypes.ColumnTypeSelect || col.Type == notiontypes.ColumnMultiSelect {
+				if addOptions(col, splitMultiSelect(record[i])) {
+					changedOptions[id] = true
+				}
+			}
+		}
+		props, err := rowProperties(coll.CollectionSchema, ids, record)
+		if err != nil {
+			return errors.Wrapf(err, "record %d", n+1)
+		}
+		k, err := csvRowKey(props, keyID, keyCol.Type)
+		if err != nil {
+			return errors.Wrapf(err, "record %d", n+1)
+		}
+		rowID, ok := existing[k]
+		if !ok || k == "" {
+			row := &notiontypes.Block{Type: notiontypes.BlockPage, Properties: props}
+			groups = append(groups, createBlockOps(row, coll.ID, notiontypes.TableCollection, "", userID, ts)...)
+			if k != "" {
+				// later records with the same key update this row
+				existing[k] = row.ID
+			}
+			continue
+		}
+		ops := []*Operation{{
+			ID:      rowID,
+			Table:   notiontypes.TableBlock,
+			Path:    []string{},
+			Command: CommandUpdate,
+			Args:    map[string]interface{}{"last_edited_by": userID, "last_edited_time": ts},
+		}}
+		for _, id := range ids {
+			if v, ok := props[id]; ok {
+				ops = append(ops, &Operation{ID: rowID, Table: notiontypes.TableBlock, Path: []string{"properties", id}, Command: CommandSet, Args: v})
+			}
+		}
+		groups = append(groups, ops)
+	}
+	var optionOps []*Operation
+	for id := range changedOptions {
+		optionOps = append(optionOps, &Operation{
+			ID:      coll.ID,
+			Table:   notiontypes.TableCollection,
+			Path:    []string{"schema", id, "options"},
+			Command: CommandSet,
+			Args:    coll.CollectionSchema[id].Options,
+		})
+	}
+	// options are set before rows refer to them
+	groups = append([][]*Operation{optionOps}, groups...)
+	return errors.Wrap(c.submitInChunks(groups), "importing rows")
+}
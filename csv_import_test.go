@@ -0,0 +1,146 @@
+package notion
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestInferSchema(t *testing.T) {
+	header := []string{"Name", "Count", "Done", "Due", "Site", "Status", "Tags", "Notes"}
+	records := [][]string{
+		{"a", "1,200", "yes", "2020-01-02", "https://a.com", "open", "x, y", "first"},
+		{"b", "-3.5", "no", "2020-01-03/2020-01-05", "", "open", "y", "second"},
+		{"c", "", "", "01/04/2020", "http://c.com", "closed", "", "third"},
+	}
+	ids, schema := InferSchema(header, records)
+	want := []string{
+		notiontypes.ColumnTypeTitle, notiontypes.ColumnTypeNumber, notiontypes.ColumnTypeCheckbox,
+		notiontypes.ColumnTypeDate, notiontypes.ColumnTypeURL, notiontypes.ColumnTypeSelect,
+		notiontypes.ColumnMultiSelect, notiontypes.ColumnTypeText,
+	}
+	for i, id := range ids {
+		if got := schema[id].Type; got != want[i] {
+			t.Errorf("%s: got type %s, want %s", header[i], got, want[i])
+		}
+	}
+	if opts := schema[ids[5]].Options; len(opts) != 2 || opts[0].Value != "open" || opts[1].Value != "closed" {
+		t.Errorf("unexpected options of Status: %+v", opts)
+	}
+	v, err := parseCSVValue(notiontypes.ColumnTypeDate, records[1][3])
+	if d, ok := v.(*notiontypes.Date); err != nil || !ok || d.Type != notiontypes.DateTypeDateRange || d.EndDate != "2020-01-05" {
+		t.Errorf("date range: %+v, %v", v, err)
+	}
+}
+
+func TestImportCSVUpserts(t *testing.T) {
+	c, requests := newTestClient(t, map[string]string{
+		"loadUserContent": `{"recordMap":{"notion_user":{"u1":{"value":{"id":"u1"}}}}}`,
+		"queryCollection": `{"result":{"blockIds":["r1"]},"recordMap":{"block":{
+			"r1":{"value":{"id":"r1","type":"page","properties":{"title":[["existing"]],"st":[["open"]]}}}
+		}}}`,
+		"submitTransaction": `{}`,
+	})
+	coll := &notiontypes.Collection{
+		ID: "c1",
+		CollectionSchema: map[string]*notiontypes.CollectionColumnInfo{
+			"title": {Name: "Name", Type: notiontypes.ColumnTypeTitle},
+			"st": {Name: "Status", Type: notiontypes.ColumnTypeSelect, Options: []*notiontypes.CollectionColumnOption{
+				{ID: "o1", Value: "open", Color: "default"},
+			}},
+		},
+	}
+	csv := "name,status,ignored\nexisting,closed,x\nnew,open,y\n"
+	if err := c.ImportCSV(coll, &notiontypes.CollectionView{ID: "v1"}, strings.NewReader(csv), ""); err != nil {
+		t.Fatal(err)
+	}
+	var tx submitTransactionRequest
+	if err := json.Unmarshal([]byte(requests["submitTransaction"][0]), &tx); err != nil {
+		t.Fatal(err)
+	}
+	var summary []string
+	for _, op := range tx.Operations {
+		summary = append(summary, op.Table+":"+op.ID+":"+op.Command+":"+strings.Join(op.Path, "."))
+	}
+	got := strings.Join(summary, "\n")
+	want := strings.Join([]string{
+		"collection:c1:set:schema.st.options",
+		"block:r1:update:",
+		"block:r1:set:properties.title",
+		"block:r1:set:properties.st",
+		"block:" + tx.Operations[4].ID + ":set:",
+	}, "\n")
+	if got != want {
+		t.Errorf("operations:\n%s\nwant:\n%s", got, want)
+	}
+	if row := tx.Operations[4].Args.(map[string]interface{}); row["parent_id"] != "c1" || row["parent_table"] != "collection" {
+		t.Errorf("unexpected new row: %+v", row)
+	}
+}
+
+func TestImportCSVNormalizesKeys(t *testing.T) {
+	c, requests := newTestClient(t, map[string]string{
+		"loadUserContent": `{"recordMap":{"notion_user":{"u1":{"value":{"id":"u1"}}}}}`,
+		"queryCollection": `{"result":{"blockIds":["r1"]},"recordMap":{"block":{
+			"r1":{"value":{"id":"r1","type":"page","properties":{"n":[["42"]]}}}
+		}}}`,
+		"submitTransaction": `{}`,
+	})
+	coll := &notiontypes.Collection{
+		ID: "c1",
+		CollectionSchema: map[string]*notiontypes.CollectionColumnInfo{
+			"title": {Name: "Name", Type: notiontypes.ColumnTypeTitle},
+			"n":     {Name: "Number", Type: notiontypes.ColumnTypeNumber},
+		},
+	}
+	csv := "Number\n42.0\n7\n7.00\n"
+	if err := c.ImportCSV(coll, &notiontypes.CollectionView{ID: "v1"}, strings.NewReader(csv), "Number"); err != nil {
+		t.Fatal(err)
+	}
+	var tx submitTransactionRequest
+	if err := json.Unmarshal([]byte(requests["submitTransaction"][0]), &tx); err != nil {
+		t.Fatal(err)
+	}
+	var summary []string
+	for _, op := range tx.Operations {
+		summary = append(summary, op.ID+":"+op.Command)
+	}
+	newID := tx.Operations[2].ID
+	want := strings.Join([]string{
+		"r1:update", "r1:set",
+		newID + ":set",
+		newID + ":update", newID + ":set",
+	}, "\n")
+	if got := strings.Join(summary, "\n"); got != want {
+		t.Errorf("operations:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestImportCSVAllRows(t *testing.T) {
+	responses := map[string]string{
+		"loadUserContent": `{"recordMap":{"notion_user":{"u1":{"value":{"id":"u1"}}}}}`,
+		"queryCollection": `{"result":{"blockIds":["r1"],"total":10001},"recordMap":{"block":{
+			"r1":{"value":{"id":"r1","type":"page","properties":{"title":[["a"]]}}}
+		}}}`,
+		"submitTransaction": `{}`,
+	}
+	c, requests := newTestClient(t, responses)
+	coll := &notiontypes.Collection{
+		ID: "c1",
+		CollectionSchema: map[string]*notiontypes.CollectionColumnInfo{
+			"title": {Name: "Name", Type: notiontypes.ColumnTypeTitle},
+		},
+	}
+	view := &notiontypes.CollectionView{ID: "v1", Query: &notiontypes.CollectionViewQuery{Filter: json.RawMessage(`{"operator":"and"}`)}}
+	if err := c.ImportCSV(coll, view, strings.NewReader("Name\nb\n"), ""); err == nil {
+		t.Error("no error importing into a collection with too many rows")
+	}
+	if len(requests["submitTransaction"]) != 0 {
+		t.Error("rows were imported")
+	}
+	if q := requests["queryCollection"][0]; strings.Contains(q, "operator") {
+		t.Errorf("query has the filter of the view: %s", q)
+	}
+}
@@ -0,0 +1,537 @@
+package notion
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Kinds of BlockChange.
+const (
+	ChangeInsert = "insert"
+	ChangeDelete = "delete"
+	ChangeMove   = "move"
+	ChangeModify = "modify"
+)
+
+// TextChange is a part of a diff of two texts.
+type TextChange struct {
+	// Op is '=' for text in both texts, '-' for deleted and '+' for inserted
+	// text.
+	Op   byte
+	Text string
+}
+
+// BlockChange is a change of a block between two versions of a page tree.
+// A block can be both moved and modified, which are reported as separate
+// changes.
+type BlockChange struct {
+	Kind string
+	// Old and New are the versions of the block, Old is nil for inserted
+	// and New for deleted blocks.
+	Old, New *notiontypes.Block
+	// for modified blocks, the diff of their text and names of other
+	// changed attributes, e.g. "type" or "checked"
+	Text   []TextChange
+	Fields []string
+}
+
+// PageDiff is the difference between two versions of a page tree, with
+// blocks matched by ID.
+type PageDiff struct {
+	Old, New *notiontypes.Block
+	Changes  []*BlockChange
+
+	rows []*diffRow
+}
+
+// diffRow is a block in the merged tree of both versions, in document order.
+type diffRow struct {
+	depth    int
+	old, new *notiontypes.Block
+	moved    bool
+	modified *BlockChange
+}
+
+func (r *diffRow) changed() bool {
+	return r.old == nil || r.new == nil || r.moved || r.modified != nil
+}
+
+// blockIndex records the parent and children of blocks of a tree.
+type blockIndex struct {
+	blocks   map[string]*notiontypes.Block
+	parent   map[string]string
+	children map[string][]string
+	// ids in document order
+	order []string
+}
+
+func indexBlocks(root *notiontypes.Block) *blockIndex {
+	idx := &blockIndex{
+		blocks:   map[string]*notiontypes.Block{},
+		parent:   map[string]string{},
+		children: map[string][]string{},
+	}
+	notiontypes.Walk(root, func(c *notiontypes.Cursor) error {
+		b := c.Block
+		idx.blocks[b.ID] = b
+		idx.order = append(idx.order, b.ID)
+		if p := c.Parent(); p != nil {
+			idx.parent[b.ID] = p.ID
+			idx.children[p.ID] = append(idx.children[p.ID], b.ID)
+		}
+		return nil
+	}, nil)
+	return idx
+}
+
+// blockText returns the text of a block that is compared by DiffPages.
+func blockText(m *markdownPrinter, b *notiontypes.Block) string {
+	switch b.Type {
+	case notiontypes.BlockPage:
+		return b.Title
+	case notiontypes.BlockCode:
+		return b.Code
+	case notiontypes.BlockEquation:
+		return b.Equation
+	case notiontypes.BlockTableRow:
+		var cells []string
+		for _, c := range b.Cells {
+			cells = append(cells, m.inline(c))
+		}
+		return strings.Join(cells, " | ")
+	}
+	return m.inline(b.InlineContent)
+}
+
+// blockFields returns names of attributes other than text that differ.
+func blockFields(a, b *notiontypes.Block) []string {
+	var fields []string
+	add := func(name string, changed bool) {
+		if changed {
+			fields = append(fields, name)
+		}
+	}
+	add("type", a.Type != b.Type)
+	add("checked", a.IsChecked != b.IsChecked)
+	add("language", a.CodeLanguage != b.CodeLanguage)
+	add("source", a.Source != b.Source)
+	add("link", a.Link != b.Link)
+	add("icon", pageIcon(a) != pageIcon(b))
+	add("color", blockColor(a) != blockColor(b))
+	return fields
+}
+
+// DiffPages compares two versions of a page tree. Blocks are matched by ID:
+// blocks only in new are inserted, blocks only in old are deleted, blocks
+// with a different parent or order among their siblings are moved, and
+// blocks with different text or attributes are modified. Text is compared
+// word by word.
+func DiffPages(oldPage, newPage *notiontypes.Block) *PageDiff {
+	d := &PageDiff{Old: oldPage, New: newPage}
+	oldIdx, newIdx := indexBlocks(oldPage), indexBlocks(newPage)
+	m := &markdownPrinter{buf: new(bytes.Buffer), cfg: newPrintConfig(nil)}
+
+	// changes in the order of the new tree, deletions in the order of the
+	// old tree
+	moved := map[string]bool{}
+	modified := map[string]*BlockChange{}
+	var walk func(b *notiontypes.Block)
+	walk = func(b *notiontypes.Block) {
+		var common []string
+		for _, id := range newIdx.children[b.ID] {
+			if oldIdx.parent[id] == b.ID {
+				common = append(common, id)
+			}
+		}
+		stay := longestCommonSubsequence(oldIdx.children[b.ID], common)
+		for _, c := range b.Content {
+			o, ok := oldIdx.blocks[c.ID]
+			switch {
+			case !ok:
+				d.Changes = append(d.Changes, &BlockChange{Kind: ChangeInsert, New: c})
+			default:
+				if !stay[c.ID] {
+					moved[c.ID] = true
+					d.Changes = append(d.Changes, &BlockChange{Kind: ChangeMove, Old: o, New: c})
+				}
+				ot, nt := blockText(m, o), blockText(m, c)
+				fields := blockFields(o, c)
+				if ot != nt || len(fields) > 0 {
+					ch := &BlockChange{Kind: ChangeModify, Old: o, New: c, Fields: fields}
+					if ot != nt {
+						ch.Text = DiffText(ot, nt)
+					}
+					modified[c.ID] = ch
+					d.Changes = append(d.Changes, ch)
+				}
+			}
+			walk(c)
+		}
+	}
+	walk(newPage)
+	for _, id := range oldIdx.order {
+		if _, ok := newIdx.blocks[id]; !ok {
+			// nested blocks are deleted with their parent
+			if _, parentKept := newIdx.blocks[oldIdx.parent[id]]; parentKept {
+				d.Changes = append(d.Changes, &BlockChange{Kind: ChangeDelete, Old: oldIdx.blocks[id]})
+			}
+		}
+	}
+
+	// merge both trees into rows, deleted blocks follow their previous
+	// sibling in the old tree
+	var merge func(newParent *notiontypes.Block, oldParentID string, depth int)
+	var addDeleted func(id string, depth int)
+	addDeleted = func(id string, depth int) {
+		d.rows = append(d.rows, &diffRow{depth: depth, old: oldIdx.blocks[id]})
+		for _, c := range oldIdx.children[id] {
+			if _, ok := newIdx.blocks[c]; !ok {
+				addDeleted(c, depth+1)
+			}
+		}
+	}
+	merge = func(newParent *notiontypes.Block, oldParentID string, depth int) {
+		deletedAfter := map[string][]string{}
+		prev := ""
+		for _, id := range oldIdx.children[oldParentID] {
+			if _, ok := newIdx.blocks[id]; ok {
+				prev = id
+				continue
+			}
+			deletedAfter[prev] = append(deletedAfter[prev], id)
+		}
+		flush := func(after string) {
+			for _, id := range deletedAfter[after] {
+				addDeleted(id, depth)
+			}
+			delete(deletedAfter, after)
+		}
+		flush("")
+		if newParent != nil {
+			for _, c := range newParent.Content {
+				row := &diffRow{depth: depth, old: oldIdx.blocks[c.ID], new: c, moved: moved[c.ID], modified: modified[c.ID]}
+				d.rows = append(d.rows, row)
+				merge(c, c.ID, depth+1)
+				if oldIdx.parent[c.ID] == oldParentID {
+					flush(c.ID)
+				}
+			}
+		}
+		// deleted blocks after blocks that moved away
+		for _, id := range oldIdx.children[oldParentID] {
+			flush(id)
+		}
+	}
+	merge(newPage, oldPage.ID, 0)
+	return d
+}
+
+var diffTokens = regexp.MustCompile(`\s+|[\pL\pN_]+|.`)
+
+// DiffText compares two texts word by word.
+func DiffText(a, b string) []TextChange {
+	ta := diffTokens.FindAllString(a, -1)
+	tb := diffTokens.FindAllString(b, -1)
+	n, m := len(ta), len(tb)
+	lengths := make([][]int, n+1)
+	for i := range lengths {
+		lengths[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if ta[i] == tb[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	var changes []TextChange
+	add := func(op byte, s string) {
+		if k := len(changes); k > 0 && changes[k-1].Op == op {
+			changes[k-1].Text += s
+			return
+		}
+		changes = append(changes, TextChange{Op: op, Text: s})
+	}
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case ta[i] == tb[j]:
+			add('=', ta[i])
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			add('-', ta[i])
+			i++
+		default:
+			add('+', tb[j])
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		add('-', ta[i])
+	}
+	for ; j < m; j++ {
+		add('+', tb[j])
+	}
+	return changes
+}
+
+// blockLine returns a one-line representation of a block for diffs, with
+// Markdown-like prefixes for the block type.
+func blockLine(m *markdownPrinter, b *notiontypes.Block, text string) string {
+	text = firstLine(text)
+	switch b.Type {
+	case notiontypes.BlockHeader, notiontypes.BlockSubHeader, notiontypes.BlockSubSubHeader:
+		return strings.Repeat("#", b.HeaderLevel()) + " " + text
+	case notiontypes.BlockBulletedList, notiontypes.BlockToggle:
+		return "- " + text
+	case notiontypes.BlockNumberedList:
+		return "1. " + text
+	case notiontypes.BlockTodo:
+		if b.IsChecked {
+			return "[x] " + text
+		}
+		return "[ ] " + text
+	case notiontypes.BlockQuote:
+		return "> " + text
+	case notiontypes.BlockCode:
+		return "```" + strings.ToLower(b.CodeLanguage) + " " + text
+	case notiontypes.BlockPage:
+		return "[" + text + "]"
+	case notiontypes.BlockDivider:
+		return "---"
+	case notiontypes.BlockText, notiontypes.BlockTableRow, notiontypes.BlockEquation, notiontypes.BlockCallout:
+		return text
+	}
+	if text != "" {
+		return "[[" + b.Type + "]] " + text
+	}
+	return "[[" + b.Type + "]]"
+}
+
+// firstLine returns the first line of text, with an ellipsis if there are
+// more.
+func firstLine(text string) string {
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		return text[:i] + " …"
+	}
+	return text
+}
+
+// rowText returns the line of a row for one side of a diff ('-' for old,
+// '+' for new) or for a unified diff (0), with inline changes marked by
+// mark.
+func (d *PageDiff) rowText(m *markdownPrinter, r *diffRow, side byte, mark func(op byte, s string) string) string {
+	b := r.new
+	if side == '-' || b == nil {
+		b = r.old
+	}
+	var text string
+	if r.modified == nil || r.modified.Text == nil {
+		text = mark('=', firstLine(blockText(m, b)))
+	} else {
+		var sb strings.Builder
+		for _, tc := range r.modified.Text {
+			switch {
+			case tc.Op == '=':
+				sb.WriteString(mark('=', tc.Text))
+			case side == 0 || side == tc.Op:
+				sb.WriteString(mark(tc.Op, tc.Text))
+			}
+		}
+		text = firstLine(sb.String())
+	}
+	if text == "" {
+		return mark('=', blockLine(m, b, ""))
+	}
+	// the prefixes of the block type are passed through mark too, the text
+	// is marked already
+	line := blockLine(m, b, "\x00")
+	i := strings.IndexByte(line, 0)
+	if i < 0 {
+		return mark('=', line)
+	}
+	return mark('=', line[:i]) + text + mark('=', line[i+1:])
+}
+
+// rowMarker returns a marker for the kind of change of a row.
+func rowMarker(r *diffRow) string {
+	switch {
+	case r.old == nil:
+		return "+"
+	case r.new == nil:
+		return "-"
+	case r.moved && r.modified != nil:
+		return "»~"
+	case r.moved:
+		return "»"
+	case r.modified != nil:
+		return "~"
+	}
+	return " "
+}
+
+// visibleRows returns all rows, or with context only changed rows and their
+// ancestors.
+func (d *PageDiff) visibleRows(all bool) []*diffRow {
+	if all {
+		return d.rows
+	}
+	var result []*diffRow
+	var stack []*diffRow
+	shown := map[*diffRow]bool{}
+	for _, r := range d.rows {
+		for len(stack) > 0 && stack[len(stack)-1].depth >= r.depth {
+			stack = stack[:len(stack)-1]
+		}
+		if r.changed() {
+			for _, a := range stack {
+				if !shown[a] {
+					shown[a] = true
+					result = append(result, a)
+				}
+			}
+			shown[r] = true
+			result = append(result, r)
+		}
+		stack = append(stack, r)
+	}
+	return result
+}
+
+// WriteUnified writes the diff as text with one block per line, prefixed by
+// "+" for inserted, "-" for deleted, "~" for modified and "»" for moved
+// blocks. Inline changes are marked as [-deleted-]{+inserted+}. Unchanged
+// blocks are only written if all is true, or if they are ancestors of
+// changed blocks.
+func (d *PageDiff) WriteUnified(w io.Writer, all bool) error {
+	m := &markdownPrinter{buf: new(bytes.Buffer), cfg: newPrintConfig(nil)}
+	mark := func(op byte, s string) string {
+		switch op {
+		case '-':
+			return "[-" + s + "-]"
+		case '+':
+			return "{+" + s + "+}"
+		}
+		return s
+	}
+	var buf bytes.Buffer
+	for _, r := range d.visibleRows(all) {
+		fmt.Fprintf(&buf, "%-2s %s%s\n", rowMarker(r), strings.Repeat("  ", r.depth), d.rowText(m, r, 0, mark))
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// truncate shortens s to at most width runes.
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+	if width == 1 {
+		return "…"
+	}
+	r := []rune(s)
+	return string(r[:width-1]) + "…"
+}
+
+// WriteSideBySide writes the diff as two columns of text, the old version
+// on the left and the new one on the right, each width characters wide.
+func (d *PageDiff) WriteSideBySide(w io.Writer, width int, all bool) error {
+	if width < 1 {
+		return fmt.Errorf("notion: width of side by side diff must be positive, got %d", width)
+	}
+	m := &markdownPrinter{buf: new(bytes.Buffer), cfg: newPrintConfig(nil)}
+	plain := func(op byte, s string) string { return s }
+	pad := func(s string) string {
+		s = truncate(s, width)
+		return s + strings.Repeat(" ", width-utf8.RuneCountInString(s))
+	}
+	var buf bytes.Buffer
+	for _, r := range d.visibleRows(all) {
+		indent := strings.Repeat("  ", r.depth)
+		left, right := "", ""
+		if r.old != nil {
+			left = indent + d.rowText(m, r, '-', plain)
+		}
+		if r.new != nil {
+			right = indent + d.rowText(m, r, '+', plain)
+		}
+		fmt.Fprintf(&buf, "%s %-2s %s\n", pad(left), rowMarker(r), strings.TrimRight(truncate(right, width), " "))
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// WriteHTML writes the diff as an HTML fragment, either as a unified list
+// of blocks or as a table with old and new versions side by side. Inline
+// changes are marked with <del> and <ins>.
+func (d *PageDiff) WriteHTML(w io.Writer, sideBySide bool, all bool) error {
+	m := &markdownPrinter{buf: new(bytes.Buffer), cfg: newPrintConfig(nil)}
+	mark := func(op byte, s string) string {
+		switch op {
+		case '-':
+			return "<del>" + html.EscapeString(s) + "</del>"
+		case '+':
+			return "<ins>" + html.EscapeString(s) + "</ins>"
+		}
+		return html.EscapeString(s)
+	}
+	class := func(r *diffRow) string {
+		switch {
+		case r.old == nil:
+			return "insert"
+		case r.new == nil:
+			return "delete"
+		case r.moved && r.modified != nil:
+			return "move modify"
+		case r.moved:
+			return "move"
+		case r.modified != nil:
+			return "modify"
+		}
+		return "same"
+	}
+	var buf bytes.Buffer
+	if sideBySide {
+		buf.WriteString("<table class=\"page-diff\">\n")
+	} else {
+		buf.WriteString("<div class=\"page-diff\">\n")
+	}
+	for _, r := range d.visibleRows(all) {
+		style := fmt.Sprintf(` style="padding-left: %dem"`, r.depth*2)
+		if !sideBySide {
+			fmt.Fprintf(&buf, "<div class=\"%s\"%s>%s</div>\n", class(r), style, d.rowText(m, r, 0, mark))
+			continue
+		}
+		left, right := "", ""
+		if r.old != nil {
+			left = d.rowText(m, r, '-', mark)
+		}
+		if r.new != nil {
+			right = d.rowText(m, r, '+', mark)
+		}
+		fmt.Fprintf(&buf, "<tr class=\"%s\"><td%s>%s</td><td%s>%s</td></tr>\n", class(r), style, left, style, right)
+	}
+	if sideBySide {
+		buf.WriteString("</table>\n")
+	} else {
+		buf.WriteString("</div>\n")
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,116 @@
+package notion
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDiffPages(t *testing.T) {
+	old := testPage(t, `[
+		{"id": "p", "type": "page", "properties": {"title": [["Page"]]}, "content": ["a", "b", "c", "d"]},
+		{"id": "a", "type": "header", "properties": {"title": [["Title"]]}},
+		{"id": "b", "type": "text", "properties": {"title": [["the quick brown fox"]]}},
+		{"id": "c", "type": "to_do", "properties": {"title": [["task"]]}, "content": ["e"]},
+		{"id": "e", "type": "text", "properties": {"title": [["nested"]]}},
+		{"id": "d", "type": "text", "properties": {"title": [["gone"]]}}
+	]`)
+	cur := testPage(t, `[
+		{"id": "p", "type": "page", "properties": {"title": [["Page"]]}, "content": ["a", "c", "b", "f"]},
+		{"id": "a", "type": "header", "properties": {"title": [["Title"]]}},
+		{"id": "b", "type": "text", "properties": {"title": [["the slow brown fox"]]}},
+		{"id": "c", "type": "to_do", "properties": {"title": [["task"]], "checked": [["Yes"]]}, "content": ["e"]},
+		{"id": "e", "type": "text", "properties": {"title": [["nested"]]}},
+		{"id": "f", "type": "text", "properties": {"title": [["new"]]}}
+	]`)
+	d := DiffPages(old.Block, cur.Block)
+	var got []string
+	for _, c := range d.Changes {
+		id := ""
+		if c.New != nil {
+			id = c.New.ID
+		} else {
+			id = c.Old.ID
+		}
+		got = append(got, c.Kind+" "+id+" "+strings.Join(c.Fields, ","))
+	}
+	want := []string{"modify c checked", "move b ", "modify b ", "insert f ", "delete d "}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("changes: %q\nwant: %q", got, want)
+	}
+
+	var buf bytes.Buffer
+	if err := d.WriteUnified(&buf, false); err != nil {
+		t.Fatal(err)
+	}
+	wantText := "~  [x] task\n" +
+		"-  gone\n" +
+		"»~ the [-quick-]{+slow+} brown fox\n" +
+		"+  new\n"
+	if buf.String() != wantText {
+		t.Errorf("unified:\n%s\nwant:\n%s", buf.String(), wantText)
+	}
+}
+
+func TestDiffText(t *testing.T) {
+	var got []string
+	for _, c := range DiffText("a big, red dog", "a red cat") {
+		got = append(got, string(c.Op)+c.Text)
+	}
+	want := []string{"=a ", "-big, ", "=red ", "-dog", "+cat"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 5, "hello"},
+		{"hello", 4, "hel…"},
+		{"héllo", 2, "h…"},
+		{"hello", 1, "…"},
+		{"hello", 0, ""},
+		{"hello", -1, ""},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.width); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestDiffHTMLEscapesText(t *testing.T) {
+	old := testPage(t, `[
+		{"id": "p", "type": "page", "properties": {"title": [["Page"]]}, "content": ["a", "b", "c"]},
+		{"id": "a", "type": "text", "properties": {"title": [["<b>same</b>"]]}},
+		{"id": "b", "type": "code", "properties": {"title": [["x"]], "language": [["<i>"]]}},
+		{"id": "c", "type": "text", "properties": {"title": [["<em>gone</em>"]]}}
+	]`)
+	cur := testPage(t, `[
+		{"id": "p", "type": "page", "properties": {"title": [["Page"]]}, "content": ["a", "b", "d"]},
+		{"id": "a", "type": "text", "properties": {"title": [["<b>same</b>"]]}},
+		{"id": "b", "type": "code", "properties": {"title": [["x"]], "language": [["<i>"]]}},
+		{"id": "d", "type": "text", "properties": {"title": [["<script>alert(1)</script>"]]}}
+	]`)
+	d := DiffPages(old.Block, cur.Block)
+	for _, sideBySide := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := d.WriteHTML(&buf, sideBySide, true); err != nil {
+			t.Fatal(err)
+		}
+		got := buf.String()
+		for _, tag := range []string{"<script>", "<b>", "<i>", "<em>"} {
+			if strings.Contains(got, tag) {
+				t.Errorf("side by side %v: unescaped %s in:\n%s", sideBySide, tag, got)
+			}
+		}
+		if !strings.Contains(got, "&lt;script&gt;alert(1)") {
+			t.Errorf("side by side %v: inserted text missing:\n%s", sideBySide, got)
+		}
+	}
+}
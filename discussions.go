@@ -0,0 +1,234 @@
+package notion
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+const (
+	tableDiscussion = "discussion"
+	tableComment    = "comment"
+	tableUser       = "notion_user"
+)
+
+// GetDiscussions returns discussions with the given ids. Discussions that
+// don't exist or are not accessible are skipped.
+func (c *Client) GetDiscussions(ids ...string) ([]*notiontypes.Discussion, error) {
+	var result []*notiontypes.Discussion
+	err := c.getRecordsOf(tableDiscussion, ids, func(v json.RawMessage) error {
+		d := &notiontypes.Discussion{}
+		result = append(result, d)
+		return json.Unmarshal(v, d)
+	})
+	return result, err
+}
+
+// GetComments returns comments with the given ids. Comments that don't exist
+// or are not accessible are skipped.
+func (c *Client) GetComments(ids ...string) ([]*notiontypes.Comment, error) {
+	var result []*notiontypes.Comment
+	err := c.getRecordsOf(tableComment, ids, func(v json.RawMessage) error {
+		cm := &notiontypes.Comment{}
+		result = append(result, cm)
+		return json.Unmarshal(v, cm)
+	})
+	return result, err
+}
+
+// maxRecordsPerRequest limits the number of records fetched by one
+// getRecordValues request, larger requests are rejected.
+const maxRecordsPerRequest = 100
+
+// getRecordsOf fetches records with given ids from a table and calls fn
+// for each one that exists. Records are fetched in batches of
+// maxRecordsPerRequest.
+func (c *Client) getRecordsOf(table string, ids []string, fn func(json.RawMessage) error) error {
+	for len(ids) > 0 {
+		n := len(ids)
+		if n > maxRecordsPerRequest {
+			n = maxRecordsPerRequest
+		}
+		records := make([]Record, n)
+		for i, id := range ids[:n] {
+			records[i] = Record{Table: table, ID: id}
+		}
+		ids = ids[n:]
+		values, err := c.getRawRecordValues(records...)
+		if err != nil {
+			return err
+		}
+		for _, v := range values {
+			if v.Value == nil {
+				continue
+			}
+			if err := fn(v.Value); err != nil {
+				return errors.Wrapf(err, "unmarshaling %s", table)
+			}
+		}
+	}
+	return nil
+}
+
+// LastEditedTimes returns the edit times of blocks by id, in milliseconds
+// since the epoch. Blocks that were deleted or are not accessible are left
+// out. Blocks are fetched in batches, so ids may be many.
+func (c *Client) LastEditedTimes(ids []string) (map[string]int64, error) {
+	times := map[string]int64{}
+	err := c.getRecordsOf(notiontypes.TableBlock, ids, func(v json.RawMessage) error {
+		var b struct {
+			ID             string `json:"id"`
+			Alive          bool   `json:"alive"`
+			LastEditedTime int64  `json:"last_edited_time"`
+		}
+		if err := json.Unmarshal(v, &b); err != nil {
+			return err
+		}
+		if b.Alive {
+			times[b.ID] = b.LastEditedTime
+		}
+		return nil
+	})
+	return times, err
+}
+
+// loadMissingDiscussions fetches discussions and comments that are referred to
+// by blocks in rm but were not included in it.
+func (c *Client) loadMissingDiscussions(rm *notiontypes.RecordMap) error {
+	var missing []string
+	for _, b := range rm.Blocks {
+		if b.Value == nil {
+			continue
+		}
+		for _, id := range b.Value.DiscussionIDs {
+			if _, ok := rm.Discussions[id]; !ok {
+				missing = append(missing, id)
+			}
+		}
+	}
+	discussions, err := c.GetDiscussions(missing...)
+	if err != nil {
+		return err
+	}
+	for _, d := range discussions {
+		rm.Discussions[d.ID] = &notiontypes.DiscussionWithRole{Value: d}
+	}
+
+	missing = missing[:0]
+	for _, d := range rm.Discussions {
+		if d.Value == nil {
+			continue
+		}
+		for _, id := range d.Value.CommentIDs {
+			if _, ok := rm.Comments[id]; !ok {
+				missing = append(missing, id)
+			}
+		}
+	}
+	comments, err := c.GetComments(missing...)
+	if err != nil {
+		return err
+	}
+	for _, cm := range comments {
+		rm.Comments[cm.ID] = &notiontypes.CommentWithRole{Value: cm}
+	}
+	return nil
+}
+
+// resolveDiscussions attaches discussions and comments in rm to blocks.
+func resolveDiscussions(blocks map[string]*notiontypes.Block, rm *notiontypes.RecordMap) error {
+	discussions := make(map[string]*notiontypes.Discussion, len(rm.Discussions))
+	for k, v := range rm.Discussions {
+		if v.Value != nil {
+			discussions[k] = v.Value
+		}
+	}
+	comments := make(map[string]*notiontypes.Comment, len(rm.Comments))
+	for k, v := range rm.Comments {
+		if v.Value != nil {
+			comments[k] = v.Value
+		}
+	}
+	users := make(map[string]*notiontypes.User, len(rm.Users))
+	for k, v := range rm.Users {
+		if v.Value != nil {
+			users[k] = v.Value
+		}
+	}
+	return notiontypes.ResolveDiscussions(blocks, discussions, comments, users)
+}
+
+// currentUserID returns the id of the authenticated user. loadUserContent
+// also returns other members of the user's spaces, which the authenticated
+// user can only read, while it can edit its own record.
+func (c *Client) currentUserID() (string, error) {
+	rm, err := c.LoadUserContent()
+	if err != nil {
+		return "", err
+	}
+	if len(rm.Users) == 1 {
+		for id := range rm.Users {
+			return id, nil
+		}
+	}
+	var ids []string
+	for id, u := range rm.Users {
+		if u.Role == notiontypes.RoleEditor {
+			ids = append(ids, id)
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("notion: no user in loadUserContent response, is the token set?")
+	case 1:
+		return ids[0], nil
+	}
+	sort.Strings(ids)
+	return "", fmt.Errorf("notion: can't tell the authenticated user from users %s", strings.Join(ids, ", "))
+}
+
+// ReplyToDiscussion adds a comment with the given text at the end of a
+// discussion. The comment is authored by the authenticated user.
+func (c *Client) ReplyToDiscussion(discussionID string, text string) (*notiontypes.Comment, error) {
+	userID, err := c.currentUserID()
+	if err != nil {
+		return nil, err
+	}
+	ts := now()
+	comment := &notiontypes.Comment{
+		ID:             NewID(),
+		Alive:          true,
+		Version:        1,
+		ParentID:       discussionID,
+		ParentTable:    tableDiscussion,
+		TextRaw:        [][]string{{text}},
+		CreatedBy:      userID,
+		CreatedTime:    ts,
+		LastEditedTime: ts,
+	}
+	err = c.SubmitTransaction(
+		&Operation{
+			ID:      comment.ID,
+			Table:   tableComment,
+			Path:    []string{},
+			Command: CommandSet,
+			Args:    comment,
+		},
+		&Operation{
+			ID:      discussionID,
+			Table:   tableDiscussion,
+			Path:    []string{"comments"},
+			Command: CommandListAfter,
+			Args:    ListArgs{ID: comment.ID},
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "submitting comment")
+	}
+	comment.InlineContent = []*notiontypes.InlineBlock{{Text: text}}
+	return comment, nil
+}
@@ -0,0 +1,735 @@
+package notion
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// The text form of a page used by PageEdit has one block per line, nested
+// blocks are indented by two spaces. The type of a block is given by a
+// Markdown-like prefix, e.g. "# " for headers, "- " for bulleted lists or
+// "[x] " for checked to-dos, text uses inline Markdown. Each existing block
+// ends with a marker like " ^1a2b3c4d" with the start of its id. Blocks that
+// can't be edited as text are shown as "[[type]]" and can only be moved or
+// deleted.
+//
+//	# Heading ^1a2b3c4d
+//	Some **bold** text ^5e6f7a8b
+//	- item ^9c0d1e2f
+//	  [ ] nested to-do ^3a4b5c6d
+//	```go ^7e8f9a0b
+//	fmt.Println("hi")
+//	```
+//	[[collection_view]] ^1c2d3e4f
+
+var (
+	editMarker = regexp.MustCompile(`(^|\s)\^([0-9a-f]{8,32})$`)
+	editOpaque = regexp.MustCompile(`^\[\[([a-z_]+)\]\]$`)
+	editNumber = regexp.MustCompile(`^\d+\. `)
+	editFence  = regexp.MustCompile("^(```+)\\s*([^`\\s]*)$")
+)
+
+// editPrefixes are prefixes of lines of block types other than text.
+var editPrefixes = []struct {
+	prefix string
+	typ    string
+}{
+	{"### ", notiontypes.BlockSubSubHeader},
+	{"## ", notiontypes.BlockSubHeader},
+	{"# ", notiontypes.BlockHeader},
+	{"- ", notiontypes.BlockBulletedList},
+	{"[ ] ", notiontypes.BlockTodo},
+	{"[x] ", notiontypes.BlockTodo},
+	{"> ", notiontypes.BlockQuote},
+	{"▸ ", notiontypes.BlockToggle},
+}
+
+// editableTypes are block types that can be edited as text.
+var editableTypes = map[string]bool{
+	notiontypes.BlockText:         true,
+	notiontypes.BlockHeader:       true,
+	notiontypes.BlockSubHeader:    true,
+	notiontypes.BlockSubSubHeader: true,
+	notiontypes.BlockBulletedList: true,
+	notiontypes.BlockNumberedList: true,
+	notiontypes.BlockTodo:         true,
+	notiontypes.BlockQuote:        true,
+	notiontypes.BlockToggle:       true,
+	notiontypes.BlockCode:         true,
+	notiontypes.BlockDivider:      true,
+}
+
+// editBlock is a block in the text form of a page.
+type editBlock struct {
+	// id of an existing block, "" for new blocks
+	id       string
+	typ      string
+	opaque   bool
+	text     string
+	checked  bool
+	language string
+	children []*editBlock
+}
+
+func (b *editBlock) equal(o *editBlock) bool {
+	return b.typ == o.typ && b.text == o.text && b.checked == o.checked && b.language == o.language
+}
+
+// summary returns the start of the text of a block for previews.
+func (b *editBlock) summary() string {
+	s := b.text
+	if b.opaque || s == "" {
+		s = b.typ
+	}
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i] + "…"
+	}
+	if len(s) > 40 {
+		s = s[:40] + "…"
+	}
+	return fmt.Sprintf("%s %q", b.typ, s)
+}
+
+// PageEdit allows editing the content of a page as text, e.g. in a text
+// editor, and computes the changes of blocks made by the edit.
+type PageEdit struct {
+	// Page is the edited page, its Version is checked by Client.ApplyEdit.
+	Page *notiontypes.Block
+
+	// full ids of blocks by short id used in markers, and the reverse
+	fullIDs  map[string]string
+	shortIDs map[string]string
+	blocks   map[string]*notiontypes.Block
+	text     []byte
+	original []*editBlock
+}
+
+// NewPageEdit initializes an edit of the content of page.
+func NewPageEdit(page *notiontypes.Block) *PageEdit {
+	e := &PageEdit{
+		Page:     page,
+		fullIDs:  map[string]string{},
+		shortIDs: map[string]string{},
+		blocks:   map[string]*notiontypes.Block{},
+	}
+	var ids []string
+	var collect func(blocks []*notiontypes.Block)
+	collect = func(blocks []*notiontypes.Block) {
+		for _, b := range blocks {
+			ids = append(ids, b.ID)
+			e.blocks[b.ID] = b
+			if editableTypes[b.Type] {
+				collect(b.Content)
+			}
+		}
+	}
+	collect(page.Content)
+	// the shortest prefix of ids without dashes that is unique
+	for n := 8; n <= 32; n++ {
+		seen := map[string]bool{}
+		unique := true
+		for _, id := range ids {
+			short := strings.Replace(id, "-", "", -1)
+			if len(short) > n {
+				short = short[:n]
+			}
+			if seen[short] {
+				unique = false
+				break
+			}
+			seen[short] = true
+		}
+		if unique || n == 32 {
+			for _, id := range ids {
+				short := strings.Replace(id, "-", "", -1)
+				if len(short) > n {
+					short = short[:n]
+				}
+				e.fullIDs[short] = id
+				e.shortIDs[id] = short
+			}
+			break
+		}
+	}
+
+	var buf bytes.Buffer
+	m := &markdownPrinter{buf: new(bytes.Buffer), cfg: newPrintConfig(nil), root: page}
+	var render func(blocks []*notiontypes.Block, indent string)
+	render = func(blocks []*notiontypes.Block, indent string) {
+		for _, b := range blocks {
+			e.renderBlock(&buf, m, b, indent)
+			if editableTypes[b.Type] {
+				render(b.Content, indent+"  ")
+			}
+		}
+	}
+	render(page.Content, "")
+	e.text = buf.Bytes()
+	e.original, _ = e.parse(e.text)
+	return e
+}
+
+// Text returns the content of the page in the text form for editing.
+func (e *PageEdit) Text() []byte {
+	return e.text
+}
+
+func (e *PageEdit) renderBlock(buf *bytes.Buffer, m *markdownPrinter, b *notiontypes.Block, indent string) {
+	marker := " ^" + e.shortIDs[b.ID]
+	if !editableTypes[b.Type] {
+		fmt.Fprintf(buf, "%s[[%s]]%s\n", indent, b.Type, marker)
+		return
+	}
+	switch b.Type {
+	case notiontypes.BlockCode:
+		fence := codeFence(b.Code)
+		fmt.Fprintf(buf, "%s%s%s%s\n", indent, fence, strings.ToLower(b.CodeLanguage), marker)
+		for _, l := range strings.Split(b.Code, "\n") {
+			if l != "" {
+				buf.WriteString(indent + l)
+			}
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(buf, "%s%s\n", indent, fence)
+		return
+	case notiontypes.BlockDivider:
+		fmt.Fprintf(buf, "%s---%s\n", indent, marker)
+		return
+	}
+	text := m.inline(b.InlineContent)
+	prefix := ""
+	switch b.Type {
+	case notiontypes.BlockNumberedList:
+		prefix = "1. "
+	case notiontypes.BlockTodo:
+		prefix = "[ ] "
+		if b.IsChecked {
+			prefix = "[x] "
+		}
+	case notiontypes.BlockText:
+		// text that looks like another type of block
+		if editNumber.MatchString(text) {
+			text = strings.Replace(text, ".", `\.`, 1)
+		} else if t, _ := editLineType(text); t != notiontypes.BlockText || strings.HasPrefix(text, "```") || text == "---" {
+			text = `\` + text
+		}
+	default:
+		for _, p := range editPrefixes {
+			if p.typ == b.Type {
+				prefix = p.prefix
+				break
+			}
+		}
+	}
+	// lines of multi-line text end with a backslash
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		if i == 0 {
+			l = indent + prefix + l
+		}
+		switch {
+		case i < len(lines)-1:
+			buf.WriteString(l + "\\\n")
+		case strings.TrimSpace(l) == "":
+			// empty text, the marker must not change the indentation
+			buf.WriteString(l + strings.TrimPrefix(marker, " ") + "\n")
+		default:
+			buf.WriteString(l + marker + "\n")
+		}
+	}
+}
+
+// editLineType returns the block type of a line in the text form and the
+// line without its prefix.
+func editLineType(line string) (string, string) {
+	if editNumber.MatchString(line) {
+		return notiontypes.BlockNumberedList, line[strings.Index(line, " ")+1:]
+	}
+	for _, p := range editPrefixes {
+		if strings.HasPrefix(line, p.prefix) {
+			return p.typ, line[len(p.prefix):]
+		}
+	}
+	trimmed := strings.TrimSpace(line)
+	for _, p := range editPrefixes {
+		// prefixes of empty blocks, e.g. "-"
+		if trimmed == strings.TrimSpace(p.prefix) {
+			return p.typ, ""
+		}
+	}
+	return notiontypes.BlockText, line
+}
+
+// codeFence returns a fence for a code block that is longer than any run of
+// backticks in the code, so that the code can contain fences.
+func codeFence(code string) string {
+	longest := longestBacktickRun(code)
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
+
+// closesFence returns true if a line closes a code block opened with fence,
+// that is it has only the backticks or tildes of the fence and at least as
+// many.
+func closesFence(line string, fence string) bool {
+	l := strings.TrimSpace(line)
+	return len(l) >= len(fence) && strings.Trim(l, fence[:1]) == ""
+}
+
+// parse parses the text form into a tree of blocks.
+func (e *PageEdit) parse(text []byte) ([]*editBlock, error) {
+	// lines have tabs expanded for indentation, code is taken from rawLines
+	var lines, rawLines []string
+	sc := bufio.NewScanner(bytes.NewReader(text))
+	sc.Buffer(nil, 16*1024*1024)
+	for sc.Scan() {
+		rawLines = append(rawLines, sc.Text())
+		lines = append(lines, strings.Replace(sc.Text(), "\t", "    ", -1))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	type level struct {
+		indent int
+		block  *editBlock
+	}
+	root := &editBlock{}
+	stack := []level{{-1, root}}
+	used := map[string]bool{}
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := indentation(line)
+		line = line[indent:]
+		// continuation lines end with an odd number of backslashes
+		for i+1 < len(lines) && !editFence.MatchString(line) && trailingBackslashes(line)%2 == 1 {
+			i++
+			line = line[:len(line)-1] + "\n" + strings.TrimLeft(lines[i], " ")
+		}
+		b := &editBlock{}
+		if m := editMarker.FindStringSubmatch(line); m != nil {
+			if id, ok := e.fullIDs[m[2]]; ok && !used[id] {
+				used[id] = true
+				b.id = id
+			}
+			line = line[:len(line)-len(m[0])]
+		}
+		switch {
+		case editFence.MatchString(line):
+			b.typ = notiontypes.BlockCode
+			m := editFence.FindStringSubmatch(line)
+			b.language = codeLanguage(m[2])
+			var code []string
+			for i++; i < len(lines) && !closesFence(lines[i], m[1]); i++ {
+				l := rawLines[i]
+				if n := indentation(l); n < indent {
+					l = l[n:]
+				} else {
+					l = l[indent:]
+				}
+				code = append(code, l)
+			}
+			if i == len(lines) {
+				return nil, fmt.Errorf("line %d: code block is not closed", len(lines))
+			}
+			b.text = strings.Join(code, "\n")
+		case strings.TrimSpace(line) == "---":
+			b.typ = notiontypes.BlockDivider
+		case editOpaque.MatchString(line) && b.id != "":
+			b.typ = editOpaque.FindStringSubmatch(line)[1]
+			b.opaque = true
+			if orig := e.blocks[b.id]; orig != nil && orig.Type != b.typ {
+				return nil, fmt.Errorf("line %d: type of %s blocks can't be changed", i+1, orig.Type)
+			}
+		default:
+			b.typ, b.text = editLineType(line)
+			b.checked = strings.HasPrefix(line, "[x]")
+		}
+		for indent <= stack[len(stack)-1].indent {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1].block
+		if parent.opaque || parent.typ == notiontypes.BlockCode || parent.typ == notiontypes.BlockDivider {
+			return nil, fmt.Errorf("line %d: %s blocks can't have nested blocks", i+1, parent.typ)
+		}
+		parent.children = append(parent.children, b)
+		stack = append(stack, level{indent, b})
+	}
+	return root.children, nil
+}
+
+func trailingBackslashes(s string) int {
+	return len(s) - len(strings.TrimRight(s, `\`))
+}
+
+// EditChange is a change of a block made by editing the text of a page.
+type EditChange struct {
+	// Kind is "insert", "update", "move" or "delete".
+	Kind    string
+	BlockID string
+	// Description is a human readable description of the change.
+	Description string
+
+	ops func(userID string, ts int64) []*Operation
+}
+
+// Diff returns changes of blocks needed to change the page to the edited text.
+// Blocks are matched by their marker: blocks without a marker are inserted,
+// blocks whose marker is missing are deleted, and blocks whose text, type or
+// position changed are updated or moved.
+//
+// Text of changed blocks is parsed as inline Markdown, so mentions of users
+// and dates in them become plain text. Unchanged blocks keep their formatting.
+func (e *PageEdit) Diff(text []byte) ([]*EditChange, error) {
+	edited, err := e.parse(text)
+	if err != nil {
+		return nil, err
+	}
+	oldParent := map[string]string{}
+	oldChildren := map[string][]string{}
+	oldBlocks := map[string]*editBlock{}
+	var index func(parentID string, blocks []*editBlock)
+	index = func(parentID string, blocks []*editBlock) {
+		for _, b := range blocks {
+			oldParent[b.id] = parentID
+			oldChildren[parentID] = append(oldChildren[parentID], b.id)
+			oldBlocks[b.id] = b
+			index(b.id, b.children)
+		}
+	}
+	index(e.Page.ID, e.original)
+
+	newParent := map[string]string{}
+	var indexNew func(parentID string, blocks []*editBlock)
+	indexNew = func(parentID string, blocks []*editBlock) {
+		for _, b := range blocks {
+			if b.id != "" {
+				newParent[b.id] = parentID
+			}
+			indexNew(b.id, b.children)
+		}
+	}
+	indexNew(e.Page.ID, edited)
+
+	var changes []*EditChange
+	var walk func(parentID string, blocks []*editBlock)
+	walk = func(parentID string, blocks []*editBlock) {
+		// blocks that keep their position: the longest common subsequence
+		// of old and new children
+		var seq []string
+		for _, b := range blocks {
+			if b.id != "" && oldParent[b.id] == parentID {
+				seq = append(seq, b.id)
+			}
+		}
+		stay := longestCommonSubsequence(oldChildren[parentID], seq)
+		prev := ""
+		for _, b := range blocks {
+			b := b
+			if b.id == "" {
+				b.id = NewID()
+				after := prev
+				changes = append(changes, &EditChange{
+					Kind:        "insert",
+					BlockID:     b.id,
+					Description: "insert " + b.summary(),
+					ops: func(userID string, ts int64) []*Operation {
+						nb := e.notionBlock(b)
+						return []*Operation{
+							{ID: b.id, Table: notiontypes.TableBlock, Path: []string{}, Command: CommandSet, Args: blockRecord(nb, parentID, notiontypes.TableBlock, userID, ts)},
+							{ID: parentID, Table: notiontypes.TableBlock, Path: []string{"content"}, Command: CommandListAfter, Args: ListArgs{ID: b.id, After: after}},
+						}
+					},
+				})
+			} else {
+				if !stay[b.id] {
+					from, after := oldParent[b.id], prev
+					changes = append(changes, &EditChange{
+						Kind:        "move",
+						BlockID:     b.id,
+						Description: "move " + b.summary(),
+						ops: func(userID string, ts int64) []*Operation {
+							return []*Operation{
+								{ID: from, Table: notiontypes.TableBlock, Path: []string{"content"}, Command: CommandListRemove, Args: ListArgs{ID: b.id}},
+								{ID: parentID, Table: notiontypes.TableBlock, Path: []string{"content"}, Command: CommandListAfter, Args: ListArgs{ID: b.id, After: after}},
+								{ID: b.id, Table: notiontypes.TableBlock, Path: []string{}, Command: CommandUpdate, Args: map[string]interface{}{
+									"parent_id": parentID, "parent_table": notiontypes.TableBlock, "last_edited_by": userID, "last_edited_time": ts,
+								}},
+							}
+						},
+					})
+				}
+				if old := oldBlocks[b.id]; !b.equal(old) {
+					changes = append(changes, &EditChange{
+						Kind:        "update",
+						BlockID:     b.id,
+						Description: fmt.Sprintf("update %s -> %s", old.summary(), b.summary()),
+						ops: func(userID string, ts int64) []*Operation {
+							nb := e.notionBlock(b)
+							return []*Operation{
+								{ID: b.id, Table: notiontypes.TableBlock, Path: []string{}, Command: CommandUpdate, Args: map[string]interface{}{
+									"type": nb.Type, "last_edited_by": userID, "last_edited_time": ts,
+								}},
+								{ID: b.id, Table: notiontypes.TableBlock, Path: []string{"properties"}, Command: CommandSet, Args: nb.RawProperties()},
+							}
+						},
+					})
+				}
+			}
+			prev = b.id
+			walk(b.id, b.children)
+		}
+	}
+	walk(e.Page.ID, edited)
+
+	var deleted func(parentID string, blocks []*editBlock)
+	deleted = func(parentID string, blocks []*editBlock) {
+		for _, b := range blocks {
+			b := b
+			if _, ok := newParent[b.id]; ok {
+				deleted(b.id, b.children)
+				continue
+			}
+			changes = append(changes, &EditChange{
+				Kind:        "delete",
+				BlockID:     b.id,
+				Description: "delete " + b.summary(),
+				ops: func(userID string, ts int64) []*Operation {
+					return []*Operation{
+						{ID: b.id, Table: notiontypes.TableBlock, Path: []string{}, Command: CommandUpdate, Args: map[string]interface{}{
+							"alive": false, "last_edited_by": userID, "last_edited_time": ts,
+						}},
+						{ID: parentID, Table: notiontypes.TableBlock, Path: []string{"content"}, Command: CommandListRemove, Args: ListArgs{ID: b.id}},
+					}
+				},
+			})
+			// nested blocks are deleted with their parent, those that were
+			// moved out are handled by walk
+		}
+	}
+	deleted(e.Page.ID, e.original)
+	return changes, nil
+}
+
+// notionBlock returns the block with the content of b. Properties other than
+// the text of existing blocks are kept, as are attributes of unchanged text
+// that the text form can't express, see carryInlineAttributes.
+func (e *PageEdit) notionBlock(b *editBlock) *notiontypes.Block {
+	nb := &notiontypes.Block{ID: b.id, Type: b.typ, IsChecked: b.checked}
+	orig, ok := e.blocks[b.id]
+	if ok {
+		nb.Properties = orig.Properties
+	}
+	switch b.typ {
+	case notiontypes.BlockCode:
+		nb.Code = b.text
+		nb.CodeLanguage = b.language
+	case notiontypes.BlockDivider:
+	default:
+		nb.InlineContent = parseMarkdownInline(b.text)
+		if ok {
+			nb.InlineContent = carryInlineAttributes(orig.InlineContent, nb.InlineContent)
+		}
+	}
+	return nb
+}
+
+// inlineSource is the inline block of the original text, and the offset in
+// its text, that a character of edited text comes from.
+type inlineSource struct {
+	run    *notiontypes.InlineBlock
+	offset int
+}
+
+// carryInlineAttributes returns the edited text cur with the attributes of
+// the original text orig that the text form can't express: underline, colors
+// and discussions are kept for unchanged text, and mentions of users, dates
+// and pages for mentions whose text is unchanged.
+func carryInlineAttributes(orig, cur []*notiontypes.InlineBlock) []*notiontypes.InlineBlock {
+	var origText, curText strings.Builder
+	var origSources []inlineSource
+	for _, b := range orig {
+		t := plainText(b)
+		origText.WriteString(t)
+		for i := range []rune(t) {
+			origSources = append(origSources, inlineSource{b, i})
+		}
+	}
+	for _, b := range cur {
+		curText.WriteString(plainText(b))
+	}
+	sources := make([]inlineSource, utf8.RuneCountInString(curText.String()))
+	i, j := 0, 0
+	for _, c := range DiffText(origText.String(), curText.String()) {
+		n := utf8.RuneCountInString(c.Text)
+		switch c.Op {
+		case '=':
+			copy(sources[j:j+n], origSources[i:i+n])
+			i += n
+			j += n
+		case '-':
+			i += n
+		case '+':
+			j += n
+		}
+	}
+
+	var result []*notiontypes.InlineBlock
+	j = 0
+	for _, b := range cur {
+		runes := []rune(plainText(b))
+		if b.IsEquation() || len(runes) == 0 {
+			result = append(result, b)
+			j += len(runes)
+			continue
+		}
+		// split b where its text comes from different original blocks
+		for start := 0; start < len(runes); {
+			end := start + 1
+			for end < len(runes) && sources[j+end].run == sources[j+start].run {
+				end++
+			}
+			nb := carriedInline(b, string(runes[start:end]), sources[j+start:j+end])
+			if n := len(result); n > 0 && sameTextStyle(result[n-1], nb) {
+				merged := *result[n-1]
+				merged.Text += nb.Text
+				result[n-1] = &merged
+			} else {
+				result = append(result, nb)
+			}
+			start = end
+		}
+		j += len(runes)
+	}
+	return result
+}
+
+// sameTextStyle returns true if a and b are text with the same attributes.
+func sameTextStyle(a, b *notiontypes.InlineBlock) bool {
+	special := func(b *notiontypes.InlineBlock) bool {
+		return b.UserID != "" || b.Date != nil || b.IsPageMention() || b.IsEquation()
+	}
+	return !special(a) && !special(b) && a.AttrFlags == b.AttrFlags && a.Color == b.Color &&
+		a.BackgroundColor == b.BackgroundColor && a.Link == b.Link &&
+		strings.Join(a.DiscussionIDs, ",") == strings.Join(b.DiscussionIDs, ",")
+}
+
+// carriedInline returns a copy of b with text and the attributes of the
+// original inline block that text comes from.
+func carriedInline(b *notiontypes.InlineBlock, text string, sources []inlineSource) *notiontypes.InlineBlock {
+	nb := *b
+	nb.Text = text
+	o := sources[0].run
+	if o == nil {
+		return &nb
+	}
+	if o.UserID != "" || o.Date != nil || o.IsPageMention() || o.IsEquation() {
+		full := len(sources) == utf8.RuneCountInString(plainText(o))
+		for i, s := range sources {
+			if s.offset != i {
+				full = false
+			}
+		}
+		if full {
+			m := *o
+			return &m
+		}
+		return &nb
+	}
+	if o.AttrFlags&notiontypes.AttrUnderline != 0 {
+		nb.AttrFlags |= notiontypes.AttrUnderline
+	}
+	if nb.Color == "" {
+		nb.Color = o.Color
+	}
+	if nb.BackgroundColor == "" {
+		nb.BackgroundColor = o.BackgroundColor
+	}
+	nb.DiscussionIDs = o.DiscussionIDs
+	return &nb
+}
+
+// longestCommonSubsequence returns the elements of the longest common
+// subsequence of a and b.
+func longestCommonSubsequence(a, b []string) map[string]bool {
+	n, m := len(a), len(b)
+	lengths := make([][]int, n+1)
+	for i := range lengths {
+		lengths[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	common := map[string]bool{}
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case a[i] == b[j]:
+			common[a[i]] = true
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return common
+}
+
+// ErrPageChanged is returned by ApplyEdit if the page changed since it was
+// loaded.
+var ErrPageChanged = errors.New("notion: page was changed since it was loaded")
+
+// ApplyEdit applies changes returned by PageEdit.Diff in a single
+// transaction. It returns ErrPageChanged if the version of the page changed
+// since it was loaded.
+//
+// Changed blocks are rewritten from their text form. Underline, colors and
+// comment marks of text are kept where the text is unchanged, and mentions
+// where their text is unchanged; changed text loses them, and mentions whose
+// text was edited become plain text.
+func (c *Client) ApplyEdit(e *PageEdit, changes []*EditChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	current, err := c.GetRecordValues(Record{Table: notiontypes.TableBlock, ID: e.Page.ID})
+	if err != nil {
+		return err
+	}
+	if len(current) == 0 || current[0].Value == nil {
+		return fmt.Errorf("notion: page %v not found", e.Page.ID)
+	}
+	if current[0].Value.Version != e.Page.Version {
+		return ErrPageChanged
+	}
+	userID, err := c.currentUserID()
+	if err != nil {
+		return err
+	}
+	ts := now()
+	var ops []*Operation
+	for _, ch := range changes {
+		ops = append(ops, ch.ops(userID, ts)...)
+	}
+	return errors.Wrap(c.SubmitTransaction(ops...), "applying edit")
+}
@@ -0,0 +1,137 @@
+package notion
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPageEditDiff(t *testing.T) {
+	page := testPage(t, `[
+		{"id": "00000000-0000-0000-0000-000000000000", "type": "page", "version": 3, "properties": {"title": [["Page"]]},
+		 "content": ["11111111-0000-0000-0000-000000000000", "22222222-0000-0000-0000-000000000000", "33333333-0000-0000-0000-000000000000", "44444444-0000-0000-0000-000000000000", "55555555-0000-0000-0000-000000000000"]},
+		{"id": "11111111-0000-0000-0000-000000000000", "type": "header", "properties": {"title": [["Title"]]}},
+		{"id": "22222222-0000-0000-0000-000000000000", "type": "text", "properties": {"title": [["Hi ", [["b"]]], ["‣", [["u", "u1"]]]]}},
+		{"id": "33333333-0000-0000-0000-000000000000", "type": "to_do", "properties": {"title": [["task"]]}, "content": ["66666666-0000-0000-0000-000000000000"]},
+		{"id": "66666666-0000-0000-0000-000000000000", "type": "text", "properties": {"title": [["- not a list"]]}},
+		{"id": "44444444-0000-0000-0000-000000000000", "type": "collection_view"},
+		{"id": "55555555-0000-0000-0000-000000000000", "type": "text"}
+	]`)
+	e := NewPageEdit(page.Block)
+	want := "# Title ^11111111\n" +
+		"**Hi** @u1 ^22222222\n" +
+		"[ ] task ^33333333\n" +
+		"  \\- not a list ^66666666\n" +
+		"[[collection_view]] ^44444444\n" +
+		"^55555555\n"
+	if got := string(e.Text()); got != want {
+		t.Fatalf("Text:\n%s\nwant:\n%s", got, want)
+	}
+	changes, err := e.Diff(e.Text())
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("unedited text has changes %v, %v", changes, err)
+	}
+
+	edited := "[[collection_view]] ^44444444\n" +
+		"# Title ^11111111\n" +
+		"**Hi** @u1 ^22222222\n" +
+		"[x] task ^33333333\n" +
+		"- new item\n" +
+		"  \\- not a list ^66666666\n"
+	changes, err = e.Diff([]byte(edited))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.Kind+" "+c.BlockID[:8])
+	}
+	newID := changes[2].BlockID
+	wantChanges := []string{
+		"move 44444444",
+		"update 33333333",
+		"insert " + newID[:8],
+		"move 66666666",
+		"delete 55555555",
+	}
+	if strings.Join(got, ", ") != strings.Join(wantChanges, ", ") {
+		t.Errorf("changes: %v\nwant: %v", got, wantChanges)
+	}
+}
+
+func TestApplyEditRefusesChangedPage(t *testing.T) {
+	c, requests := newTestClient(t, map[string]string{
+		"getRecordValues": `{"results":[{"role":"editor","value":{"id":"p","type":"page","version":4}}]}`,
+	})
+	e := NewPageEdit(testPage(t, `[{"id": "p", "type": "page", "version": 3}]`).Block)
+	changes, err := e.Diff([]byte("new text\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ApplyEdit(e, changes); err != ErrPageChanged {
+		t.Errorf("got error %v, want ErrPageChanged", err)
+	}
+	if len(requests["submitTransaction"]) != 0 {
+		t.Errorf("transaction was submitted")
+	}
+}
+
+func TestPageEditCodeRoundTrip(t *testing.T) {
+	code := "Some *markdown*:\n\n```go\n\tfmt.Println(\"hi\")  \n```\n"
+	js, _ := json.Marshal(code)
+	page := testPage(t, `[
+		{"id": "00000000-0000-0000-0000-000000000000", "type": "page", "properties": {"title": [["Page"]]},
+		 "content": ["11111111-0000-0000-0000-000000000000"]},
+		{"id": "11111111-0000-0000-0000-000000000000", "type": "bulleted_list", "properties": {"title": [["item"]]},
+		 "content": ["22222222-0000-0000-0000-000000000000"]},
+		{"id": "22222222-0000-0000-0000-000000000000", "type": "code", "properties": {"title": [[`+string(js)+`]], "language": [["Markdown"]]}}
+	]`)
+	e := NewPageEdit(page.Block)
+	if !strings.Contains(string(e.Text()), "  ````markdown ^22222222\n") {
+		t.Errorf("code block isn't fenced by four backticks:\n%s", e.Text())
+	}
+	changes, err := e.Diff(e.Text())
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("unedited text has changes %v, %v:\n%s", changes, err, e.Text())
+	}
+	blocks, err := e.parse(e.Text())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := blocks[0].children[0].text; got != code {
+		t.Errorf("code:\n%q\nwant:\n%q", got, code)
+	}
+}
+
+func TestPageEditKeepsAttributes(t *testing.T) {
+	page := testPage(t, `[
+		{"id": "00000000-0000-0000-0000-000000000000", "type": "page", "properties": {"title": [["Page"]]}, "content": ["11111111-0000-0000-0000-000000000000"]},
+		{"id": "11111111-0000-0000-0000-000000000000", "type": "text", "properties": {"title": [
+			["see "], ["under", [["_"], ["h", "red"], ["m", "d1"]]], [" and "], ["‣", [["u", "u1"]]], [" the end"]
+		]}}
+	]`)
+	e := NewPageEdit(page.Block)
+	if want := "see under and @u1 the end ^11111111\n"; string(e.Text()) != want {
+		t.Fatalf("Text: %q, want %q", e.Text(), want)
+	}
+	changes, err := e.Diff([]byte("see **under** and @u1 the finish ^11111111\n"))
+	if err != nil || len(changes) != 1 {
+		t.Fatalf("changes %v, %v", changes, err)
+	}
+	ops := changes[0].ops("u", 1)
+	got, _ := json.Marshal(ops[1].Args)
+	want := `{"title":[["see "],["under",[["b"],["_"],["h","red"],["m","d1"]]],[" and "],["‣",[["u","u1"]]],[" the finish"]]}`
+	if string(got) != want {
+		t.Errorf("properties:\n%s\nwant:\n%s", got, want)
+	}
+
+	changes, err = e.Diff([]byte("see undo and @u2 the end ^11111111\n"))
+	if err != nil || len(changes) != 1 {
+		t.Fatalf("changes %v, %v", changes, err)
+	}
+	got, _ = json.Marshal(changes[0].ops("u", 1)[1].Args)
+	want = `{"title":[["see undo and @u2 the end"]]}`
+	if string(got) != want {
+		t.Errorf("properties:\n%s\nwant:\n%s", got, want)
+	}
+}
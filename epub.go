@@ -0,0 +1,336 @@
+package notion
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// epubMediaTypes are the media types of images that can be embedded in an
+// EPUB, by file extension.
+var epubMediaTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".svg":  "image/svg+xml",
+	".webp": "image/webp",
+}
+
+const epubStyle = `body { font-family: serif; line-height: 1.4; }
+img { max-width: 100%; }
+pre { white-space: pre-wrap; font-size: 0.85em; }
+blockquote, .callout { margin-left: 1em; padding-left: 0.5em; border-left: 3px solid #ccc; }
+.discussion { font-size: 0.85em; color: #555; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ccc; padding: 0.2em 0.4em; }
+`
+
+// EPUBWriter writes a page tree as an EPUB 3 book with one XHTML chapter per
+// page, in the order of PageTree.Pages, and a table of contents of page
+// titles and headers.
+type EPUBWriter struct {
+	// Assets holds downloaded images to embed in the book. The cover of the
+	// root page becomes the cover of the book. Images that were not
+	// downloaded are referred to by their urls.
+	Assets *AssetDownloader
+	// Language is the language of the book, "en" if not set.
+	Language string
+}
+
+// NewEPUBWriter initializes a new EPUBWriter embedding images downloaded by
+// assets, which may be nil.
+func NewEPUBWriter(assets *AssetDownloader) *EPUBWriter {
+	return &EPUBWriter{Assets: assets, Language: "en"}
+}
+
+// epubImage is an image embedded in a book.
+type epubImage struct {
+	id, name, mediaType, src string
+}
+
+// tocEntry is an entry of the table of contents.
+type tocEntry struct {
+	title, href string
+	children    []*tocEntry
+}
+
+// Write writes the book to w.
+func (e *EPUBWriter) Write(w io.Writer, tree *PageTree) error {
+	lang := e.Language
+	if lang == "" {
+		lang = "en"
+	}
+	bookTitle := tree.Page.Title
+	if bookTitle == "" {
+		bookTitle = "Untitled"
+	}
+	pages := tree.Pages()
+	chapters := map[string]string{}
+	modified := tree.Page.UpdatedOn()
+	for i, t := range pages {
+		chapters[t.Page.ID] = fmt.Sprintf("chapter-%03d.xhtml", i+1)
+		if u := t.Page.UpdatedOn(); u.After(modified) {
+			modified = u
+		}
+	}
+
+	// embeddable images, by original url
+	images := map[string]*epubImage{}
+	if e.Assets != nil {
+		var urls []string
+		files := e.Assets.Files()
+		for u := range files {
+			urls = append(urls, u)
+		}
+		sort.Strings(urls)
+		for _, u := range urls {
+			name := files[u]
+			mediaType, ok := epubMediaTypes[strings.ToLower(path.Ext(name))]
+			if !ok {
+				continue
+			}
+			images[u] = &epubImage{
+				id:        fmt.Sprintf("image-%d", len(images)+1),
+				name:      "images/" + name,
+				mediaType: mediaType,
+				src:       filepath.Join(e.Assets.Dir, name),
+			}
+		}
+	}
+	assetURL := func(u string) string {
+		if img, ok := images[u]; ok {
+			return img.name
+		}
+		return u
+	}
+	pageURL := func(id string) string {
+		if c, ok := chapters[id]; ok {
+			return c
+		}
+		return PageURL(id)
+	}
+	var cover *epubImage
+	if f := tree.Page.FormatPage; f != nil {
+		cover = images[f.PageCover]
+	}
+
+	z := zip.NewWriter(w)
+	// the mimetype must be the first file, uncompressed
+	f, err := z.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	io.WriteString(f, "application/epub+zip")
+	add := func(name string, content []byte) error {
+		f, err := z.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(content)
+		return err
+	}
+	if err := add("META-INF/container.xml", []byte(epubContainer)); err != nil {
+		return err
+	}
+	if err := add("OEBPS/style.css", []byte(epubStyle)); err != nil {
+		return err
+	}
+
+	var toc []*tocEntry
+	entries := map[*PageTree]*tocEntry{}
+	// chapters showing images or embeds that are not in the book, by page id
+	remote := map[string]bool{}
+	for _, t := range pages {
+		body, err := PrintAsHTML(t.Page.Block, asXHTML(), WithAssetURLs(assetURL), WithPageURLs(pageURL))
+		if err != nil {
+			return err
+		}
+		remote[t.Page.ID] = epubRemoteResource.Match(body)
+		title := t.Page.Title
+		if title == "" {
+			title = "Untitled"
+		}
+		if err := add("OEBPS/"+chapters[t.Page.ID], epubXHTML(lang, title, body)); err != nil {
+			return err
+		}
+
+		entry := &tocEntry{title: title, href: chapters[t.Page.ID]}
+		entry.children = headerEntries(t.Page.Block, entry.href)
+		entries[t] = entry
+		if parent := entries[t.Parent]; t.Parent != nil && parent != nil {
+			parent.children = append(parent.children, entry)
+		} else {
+			toc = append(toc, entry)
+		}
+	}
+
+	var imgs []*epubImage
+	for _, img := range images {
+		imgs = append(imgs, img)
+	}
+	sort.Slice(imgs, func(i, j int) bool { return imgs[i].name < imgs[j].name })
+	for _, img := range imgs {
+		content, err := ioutil.ReadFile(img.src)
+		if err != nil {
+			return errors.Wrap(err, "embedding image")
+		}
+		if err := add("OEBPS/"+img.name, content); err != nil {
+			return err
+		}
+	}
+	if cover != nil {
+		body := fmt.Sprintf(`<div class="cover"><img src="%s" alt="%s" /></div>`, attr(cover.name), attr(bookTitle))
+		if err := add("OEBPS/cover.xhtml", epubXHTML(lang, bookTitle, []byte(body))); err != nil {
+			return err
+		}
+	}
+
+	var nav bytes.Buffer
+	writeTOC(&nav, toc)
+	if err := add("OEBPS/nav.xhtml", epubXHTML(lang, "Contents", []byte(`<nav epub:type="toc" id="toc"><h1>Contents</h1>`+"\n"+nav.String()+"</nav>\n"))); err != nil {
+		return err
+	}
+
+	// package document
+	var opf bytes.Buffer
+	author := ""
+	if u := tree.Page.CreatedByUser; u != nil {
+		author = u.Name()
+	}
+	fmt.Fprintf(&opf, `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="%s">
+<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:identifier id="book-id">urn:uuid:%s</dc:identifier>
+<dc:title>%s</dc:title>
+<dc:language>%s</dc:language>
+`, attr(lang), attr(tree.Page.ID), html.EscapeString(bookTitle), html.EscapeString(lang))
+	if author != "" {
+		fmt.Fprintf(&opf, "<dc:creator>%s</dc:creator>\n", html.EscapeString(author))
+	}
+	fmt.Fprintf(&opf, "<meta property=\"dcterms:modified\">%s</meta>\n", modified.UTC().Format(time.RFC3339))
+	if cover != nil {
+		fmt.Fprintf(&opf, "<meta name=\"cover\" content=\"%s\" />\n", cover.id)
+	}
+	opf.WriteString("</metadata>\n<manifest>\n")
+	opf.WriteString(`<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav" />` + "\n")
+	opf.WriteString(`<item id="style" href="style.css" media-type="text/css" />` + "\n")
+	if cover != nil {
+		opf.WriteString(`<item id="cover" href="cover.xhtml" media-type="application/xhtml+xml" />` + "\n")
+	}
+	for i, t := range pages {
+		props := ""
+		if remote[t.Page.ID] {
+			props = ` properties="remote-resources"`
+		}
+		fmt.Fprintf(&opf, "<item id=\"chapter-%d\" href=\"%s\" media-type=\"application/xhtml+xml\"%s />\n", i+1, chapters[t.Page.ID], props)
+	}
+	for _, img := range imgs {
+		props := ""
+		if img == cover {
+			props = ` properties="cover-image"`
+		}
+		fmt.Fprintf(&opf, "<item id=\"%s\" href=\"%s\" media-type=\"%s\"%s />\n", img.id, attr(img.name), img.mediaType, props)
+	}
+	opf.WriteString("</manifest>\n<spine>\n")
+	if cover != nil {
+		opf.WriteString(`<itemref idref="cover" />` + "\n")
+	}
+	opf.WriteString(`<itemref idref="nav" linear="no" />` + "\n")
+	for i := range pages {
+		fmt.Fprintf(&opf, "<itemref idref=\"chapter-%d\" />\n", i+1)
+	}
+	opf.WriteString("</spine>\n</package>\n")
+	if err := add("OEBPS/content.opf", opf.Bytes()); err != nil {
+		return err
+	}
+	return z.Close()
+}
+
+// epubRemoteResource matches elements of chapters that load resources from
+// outside the book, which must be declared in the manifest.
+var epubRemoteResource = regexp.MustCompile(`<(img|iframe|audio|video|source)\s[^>]*\bsrc="([a-zA-Z][a-zA-Z0-9+.-]*:)?//`)
+
+const epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles>
+<rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml" />
+</rootfiles>
+</container>
+`
+
+// epubXHTML wraps body in an XHTML document.
+func epubXHTML(lang string, title string, body []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="%s" xml:lang="%s">
+<head>
+<meta charset="UTF-8" />
+<title>%s</title>
+<link rel="stylesheet" type="text/css" href="style.css" />
+</head>
+<body>
+`, attr(lang), attr(lang), html.EscapeString(title))
+	buf.Write(body)
+	buf.WriteString("</body>\n</html>\n")
+	return buf.Bytes()
+}
+
+// headerEntries returns entries for the headers of a page, nested by level.
+func headerEntries(page *notiontypes.Block, href string) []*tocEntry {
+	var top []*tocEntry
+	type level struct {
+		n     int
+		entry *tocEntry
+	}
+	var stack []level
+	for _, h := range headers(page) {
+		title := inlineText(h.InlineContent)
+		if title == "" {
+			continue
+		}
+		e := &tocEntry{title: title, href: href + "#" + h.ID}
+		n := h.HeaderLevel()
+		for len(stack) > 0 && stack[len(stack)-1].n >= n {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			top = append(top, e)
+		} else {
+			parent := stack[len(stack)-1].entry
+			parent.children = append(parent.children, e)
+		}
+		stack = append(stack, level{n, e})
+	}
+	return top
+}
+
+// writeTOC writes entries as nested ordered lists.
+func writeTOC(buf *bytes.Buffer, entries []*tocEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	buf.WriteString("<ol>\n")
+	for _, e := range entries {
+		fmt.Fprintf(buf, `<li><a href="%s">%s</a>`, attr(e.href), html.EscapeString(e.title))
+		if len(e.children) > 0 {
+			buf.WriteString("\n")
+			writeTOC(buf, e.children)
+		}
+		buf.WriteString("</li>\n")
+	}
+	buf.WriteString("</ol>\n")
+}
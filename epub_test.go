@@ -0,0 +1,98 @@
+package notion
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEPUBWriter(t *testing.T) {
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("image " + r.URL.Path))
+	}))
+	defer files.Close()
+	c, _ := newTestClient(t, nil)
+	root := testPage(t, `[
+	{"id":"r","type":"page","properties":{"title":[["Handbook"]]},"content":["h","todo","sub"],"format":{"page_cover":"`+files.URL+`/cover.png"}},
+	{"id":"h","type":"header","properties":{"title":[["Welcome & Intro"]]}},
+	{"id":"todo","type":"to_do","properties":{"title":[["read"]],"checked":[["Yes"]]}},
+	{"id":"sub","type":"page","parent_id":"r","properties":{"title":[["Chapter"]]}}
+	]`)
+	sub := testPage(t, `[
+	{"id":"sub","type":"page","properties":{"title":[["Chapter"]]},"content":["img","emb"]},
+	{"id":"img","type":"image","properties":{"source":[["`+files.URL+`/photo.png"]]}},
+	{"id":"emb","type":"embed","properties":{"source":[["https://example.com/widget"]]}}
+	]`)
+	tree := &PageTree{Page: root}
+	tree.Children = []*PageTree{{Page: sub, Parent: tree}}
+
+	w := NewEPUBWriter(NewAssetDownloader(c, t.TempDir()))
+	for _, p := range tree.Pages() {
+		if err := w.Assets.Download(p.Page.Block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := w.Write(&buf, tree); err != nil {
+		t.Fatal(err)
+	}
+
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := z.File[0]; f.Name != "mimetype" || f.Method != zip.Store {
+		t.Errorf("first file is %s, method %d", f.Name, f.Method)
+	}
+	contents := map[string]string{}
+	for _, f := range z.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := ioutil.ReadAll(r)
+		r.Close()
+		contents[f.Name] = string(b)
+		if strings.HasSuffix(f.Name, ".xhtml") || strings.HasSuffix(f.Name, ".opf") {
+			d := xml.NewDecoder(bytes.NewReader(b))
+			for {
+				if _, err := d.Token(); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Errorf("%s is not well-formed: %v\n%s", f.Name, err, b)
+					break
+				}
+			}
+		}
+	}
+	nav := contents["OEBPS/nav.xhtml"]
+	for _, want := range []string{
+		`<a href="chapter-001.xhtml">Handbook</a>`,
+		`<a href="chapter-001.xhtml#h">Welcome &amp; Intro</a>`,
+		`<a href="chapter-002.xhtml">Chapter</a>`,
+	} {
+		if !strings.Contains(nav, want) {
+			t.Errorf("nav lacks %s:\n%s", want, nav)
+		}
+	}
+	opf := contents["OEBPS/content.opf"]
+	if !strings.Contains(opf, `properties="cover-image"`) || !strings.Contains(opf, `<itemref idref="cover" />`) {
+		t.Errorf("no cover in package document:\n%s", opf)
+	}
+	if !strings.Contains(opf, `href="chapter-002.xhtml" media-type="application/xhtml+xml" properties="remote-resources"`) ||
+		strings.Contains(opf, `href="chapter-001.xhtml" media-type="application/xhtml+xml" properties`) {
+		t.Errorf("remote resources not declared for the chapter with an embed only:\n%s", opf)
+	}
+	if !strings.Contains(contents["OEBPS/chapter-001.xhtml"], `href="chapter-002.xhtml"`) {
+		t.Errorf("sub-page link not rewritten:\n%s", contents["OEBPS/chapter-001.xhtml"])
+	}
+	if !strings.Contains(contents["OEBPS/chapter-002.xhtml"], `<img src="images/`) {
+		t.Errorf("image not embedded:\n%s", contents["OEBPS/chapter-002.xhtml"])
+	}
+}
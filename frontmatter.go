@@ -0,0 +1,231 @@
+package notion
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// FrontMatterField is a single key and value of FrontMatter.
+type FrontMatterField struct {
+	Key   string
+	Value interface{}
+}
+
+// FrontMatter is metadata of a page in the form used by static site generators
+// like Hugo and Jekyll. Values are strings, bools, float64s, time.Times or
+// []strings.
+type FrontMatter []FrontMatterField
+
+// Set sets the value of key, replacing an existing value.
+func (fm *FrontMatter) Set(key string, value interface{}) {
+	for i, f := range *fm {
+		if f.Key == key {
+			(*fm)[i].Value = value
+			return
+		}
+	}
+	*fm = append(*fm, FrontMatterField{Key: key, Value: value})
+}
+
+// Get returns the value of key or nil if it's not set.
+func (fm FrontMatter) Get(key string) interface{} {
+	for _, f := range fm {
+		if f.Key == key {
+			return f.Value
+		}
+	}
+	return nil
+}
+
+// PageFrontMatter returns front matter of a page: title, date (creation time),
+// lastmod, author, icon, cover and notion_id. For rows of a collection, it also
+// includes all properties with typed values, keyed by the property name in
+// snake case. Properties replace the default keys, e.g. a "Date" property is
+// used as date.
+//
+// WithAssetURLs can be used to rewrite urls of the icon and cover.
+func PageFrontMatter(page *Page, opts ...PrintOption) FrontMatter {
+	cfg := newPrintConfig(opts)
+	fm := FrontMatter{
+		{"title", page.Title},
+		{"date", page.CreatedOn().UTC()},
+		{"lastmod", page.UpdatedOn().UTC()},
+	}
+	if u := page.CreatedByUser; u != nil {
+		fm.Set("author", u.Name())
+	}
+	if icon := pageIcon(page.Block); icon != "" {
+		if u := cfg.iconURL(icon); u != "" {
+			icon = u
+		}
+		fm.Set("icon", icon)
+	}
+	if cover := cfg.coverURL(page.Block); cover != "" {
+		fm.Set("cover", cover)
+	}
+	fm.Set("notion_id", page.ID)
+	if page.Collection == nil {
+		return fm
+	}
+	for _, p := range page.Collection.RowProperties(page.Block) {
+		if p.Type == notiontypes.ColumnTypeTitle {
+			continue
+		}
+		if v := frontMatterValue(p, page.Users); v != nil {
+			fm.Set(snakeCase(p.Name), v)
+		}
+	}
+	return fm
+}
+
+// frontMatterValue converts a property value to one of the types of FrontMatter.
+func frontMatterValue(p *notiontypes.Property, users map[string]*notiontypes.User) interface{} {
+	userName := func(id string) string {
+		if u, ok := users[id]; ok {
+			return u.Name()
+		}
+		return id
+	}
+	switch v := p.Value.(type) {
+	case nil:
+		return nil
+	case *notiontypes.Date:
+		start, err := v.Start()
+		if err != nil {
+			return v.StartDate
+		}
+		end, err := v.End()
+		if err != nil || end.IsZero() {
+			return start
+		}
+		return []string{start.Format(time.RFC3339), end.Format(time.RFC3339)}
+	case []string:
+		if p.Type == notiontypes.ColumnTypePerson {
+			names := make([]string, len(v))
+			for i, id := range v {
+				names[i] = userName(id)
+			}
+			return names
+		}
+		return v
+	case string:
+		if p.Type == notiontypes.ColumnTypeCreatedBy || p.Type == notiontypes.ColumnTypeLastEditedBy {
+			return userName(v)
+		}
+		return v
+	case time.Time:
+		return v.UTC()
+	}
+	return p.Value
+}
+
+// snakeCase converts a property name like "Published Date" to "published_date".
+func snakeCase(s string) string {
+	return strings.Replace(Slugify(s), "-", "_", -1)
+}
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func frontMatterKey(k string) string {
+	if bareKey.MatchString(k) {
+		return k
+	}
+	return quoteString(k)
+}
+
+// quoteString quotes s as a double-quoted string with only the escapes that
+// TOML and YAML have in common, unlike strconv.Quote which uses Go escapes
+// like \a and \x07. Control characters are written as \uXXXX escapes.
+func quoteString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\r':
+			sb.WriteString(`\r`)
+		default:
+			if r < 0x20 || r >= 0x7f && r <= 0x9f {
+				fmt.Fprintf(&sb, `\u%04X`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+func frontMatterScalar(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return quoteString(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return quoteString(fmt.Sprint(v))
+}
+
+// WriteYAML writes front matter in YAML format, delimited by "---" lines.
+func (fm FrontMatter) WriteYAML(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	for _, f := range fm {
+		if list, ok := f.Value.([]string); ok {
+			if len(list) == 0 {
+				fmt.Fprintf(&sb, "%s: []\n", frontMatterKey(f.Key))
+				continue
+			}
+			fmt.Fprintf(&sb, "%s:\n", frontMatterKey(f.Key))
+			for _, v := range list {
+				fmt.Fprintf(&sb, "  - %s\n", quoteString(v))
+			}
+			continue
+		}
+		fmt.Fprintf(&sb, "%s: %s\n", frontMatterKey(f.Key), frontMatterScalar(f.Value))
+	}
+	sb.WriteString("---\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteTOML writes front matter in TOML format, delimited by "+++" lines.
+func (fm FrontMatter) WriteTOML(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("+++\n")
+	for _, f := range fm {
+		if list, ok := f.Value.([]string); ok {
+			quoted := make([]string, len(list))
+			for i, v := range list {
+				quoted[i] = quoteString(v)
+			}
+			fmt.Fprintf(&sb, "%s = [%s]\n", frontMatterKey(f.Key), strings.Join(quoted, ", "))
+			continue
+		}
+		fmt.Fprintf(&sb, "%s = %s\n", frontMatterKey(f.Key), frontMatterScalar(f.Value))
+	}
+	sb.WriteString("+++\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,74 @@
+package notion
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestPageFrontMatter(t *testing.T) {
+	page := testPage(t, `[
+		{"id": "p1", "type": "page", "alive": true, "created_time": 1577836800000, "last_edited_time": 1577923200000,
+		 "parent_table": "collection", "parent_id": "c1",
+		 "properties": {"title": [["Hello"]], "tags": [["go,notion"]], "pub": [["‣", [["d", {"type": "date", "start_date": "2020-03-01"}]]]], "n": [["3"]]}}
+	]`)
+	page.Collection = &notiontypes.Collection{
+		ID: "c1",
+		CollectionSchema: map[string]*notiontypes.CollectionColumnInfo{
+			"title": {Name: "Name", Type: notiontypes.ColumnTypeTitle},
+			"tags":  {Name: "Tags", Type: notiontypes.ColumnMultiSelect},
+			"pub":   {Name: "Date", Type: notiontypes.ColumnTypeDate},
+			"n":     {Name: "Read Time", Type: notiontypes.ColumnTypeNumber},
+		},
+	}
+	fm := PageFrontMatter(page)
+	var buf bytes.Buffer
+	if err := fm.WriteYAML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `---
+title: "Hello"
+date: 2020-03-01T00:00:00Z
+lastmod: 2020-01-02T00:00:00Z
+notion_id: "p1"
+read_time: 3
+tags:
+  - "go"
+  - "notion"
+---
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteYAML:\n%s\nwant:\n%s", got, want)
+	}
+	buf.Reset()
+	if err := fm.WriteTOML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want = `+++
+title = "Hello"
+date = 2020-03-01T00:00:00Z
+lastmod = 2020-01-02T00:00:00Z
+notion_id = "p1"
+read_time = 3
+tags = ["go", "notion"]
++++
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteTOML:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestQuoteString(t *testing.T) {
+	for in, want := range map[string]string{
+		`plain`:               `"plain"`,
+		`say "hi" \ there`:    `"say \"hi\" \\ there"`,
+		"tab\tnl\ncr\r":       `"tab\tnl\ncr\r"`,
+		"bell\a vt\v del\x7f": `"bell\u0007 vt\u000B del\u007F"`,
+		"héllo ✓":             `"héllo ✓"`,
+	} {
+		if got := quoteString(in); got != want {
+			t.Errorf("quoteString(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package notion
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+type htmlPrinter struct {
+	buf  *bytes.Buffer
+	cfg  *printConfig
+	root *notiontypes.Block
+}
+
+func (h *htmlPrinter) W(format string, args ...interface{}) {
+	fmt.Fprintf(h.buf, format, args...)
+	h.buf.WriteString("\n")
+}
+
+func (h *htmlPrinter) printPage(page *notiontypes.Block) {
+	h.W(`<article id="%s" class="page">`, page.ID)
+	if !h.cfg.noTitle {
+		h.W(`<header>`)
+		if cover := h.cfg.coverURL(page); cover != "" {
+			h.W(`<img class="page-cover" src="%s" alt=""%s>`, attr(cover), h.slash())
+		}
+		h.W(`%s<h1 class="page-title">%s</h1>`, h.icon(page), html.EscapeString(page.Title))
+		h.W(`</header>`)
+	}
+	h.printDiscussions(page)
+	h.printBlocks(page.Content)
+	h.W(`</article>`)
+}
+
+// slash returns the end of void elements like <img>, which are closed in
+// XHTML.
+func (h *htmlPrinter) slash() string {
+	if h.cfg.xhtml {
+		return " /"
+	}
+	return ""
+}
+
+// boolAttr returns a boolean attribute, minimized unless writing XHTML.
+func (h *htmlPrinter) boolAttr(name string) string {
+	if h.cfg.xhtml {
+		return fmt.Sprintf(` %s="%s"`, name, name)
+	}
+	return " " + name
+}
+
+func (h *htmlPrinter) icon(b *notiontypes.Block) string {
+	icon := pageIcon(b)
+	if icon == "" {
+		return ""
+	}
+	if u := h.cfg.iconURL(icon); u != "" {
+		return fmt.Sprintf(`<img class="icon" src="%s" alt=""%s>`, attr(u), h.slash())
+	}
+	return fmt.Sprintf(`<span class="icon">%s</span>`, html.EscapeString(icon))
+}
+
+func listTag(b *notiontypes.Block) string {
+	switch b.Type {
+	case notiontypes.BlockBulletedList, notiontypes.BlockToggle:
+		return "ul"
+	case notiontypes.BlockNumberedList:
+		return "ol"
+	case notiontypes.BlockTodo:
+		return `ul class="to-do-list"`
+	}
+	return ""
+}
+
+// printBlocks prints blocks, grouping consecutive list items in lists.
+func (h *htmlPrinter) printBlocks(blocks []*notiontypes.Block) {
+	open := ""
+	for _, b := range blocks {
+		tag := listTag(b)
+		if tag != open {
+			if open != "" {
+				h.W("</%s>", strings.Fields(open)[0])
+			}
+			if tag != "" {
+				h.W("<%s>", tag)
+			}
+			open = tag
+		}
+		h.printBlock(b)
+	}
+	if open != "" {
+		h.W("</%s>", strings.Fields(open)[0])
+	}
+}
+
+// class returns the class attribute for a block.
+func class(names ...string) string {
+	var nonEmpty []string
+	for _, n := range names {
+		if n != "" {
+			nonEmpty = append(nonEmpty, n)
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(` class="%s"`, strings.Join(nonEmpty, " "))
+}
+
+func colorClass(b *notiontypes.Block) string {
+	if c := blockColor(b); c != "" {
+		return "block-color-" + c
+	}
+	return ""
+}
+
+func (h *htmlPrinter) printBlock(b *notiontypes.Block) {
+	text := h.inline(b.InlineContent)
+	id := b.ID
+	switch b.Type {
+	case notiontypes.BlockPage, notiontypes.BlockAlias:
+		pageID, title := pageTitle(b)
+		h.W(`<p id="%s" class="link-to-page"><a href="%s">%s</a></p>`, id, attr(h.cfg.pageURL(pageID)), html.EscapeString(title))
+	case notiontypes.BlockHeader, notiontypes.BlockSubHeader, notiontypes.BlockSubSubHeader:
+		tag := fmt.Sprintf("h%d", b.HeaderLevel()+1)
+		if b.IsToggleable() {
+			h.W(`<details id="%s"%s><summary><%s>%s</%s></summary>`, id, class(colorClass(b)), tag, text, tag)
+			h.printDiscussions(b)
+			h.printBlocks(b.Content)
+			h.W(`</details>`)
+			return
+		}
+		h.W(`<%s id="%s"%s>%s</%s>`, tag, id, class(colorClass(b)), text, tag)
+	case notiontypes.BlockBulletedList, notiontypes.BlockNumberedList:
+		h.W(`<li id="%s"%s>%s`, id, class(colorClass(b)), text)
+		h.printDiscussions(b)
+		h.printBlocks(b.Content)
+		h.W(`</li>`)
+		return
+	case notiontypes.BlockTodo:
+		checked := ""
+		if b.IsChecked {
+			checked = h.boolAttr("checked")
+		}
+		h.W(`<li id="%s"%s><input type="checkbox"%s%s%s> %s`, id, class(colorClass(b)), h.boolAttr("disabled"), checked, h.slash(), text)
+		h.printDiscussions(b)
+		h.printBlocks(b.Content)
+		h.W(`</li>`)
+		return
+	case notiontypes.BlockToggle:
+		h.W(`<li id="%s"%s><details><summary>%s</summary>`, id, class("toggle", colorClass(b)), text)
+		h.printDiscussions(b)
+		h.printBlocks(b.Content)
+		h.W(`</details></li>`)
+		return
+	case notiontypes.BlockQuote:
+		h.W(`<blockquote id="%s"%s>%s`, id, class(colorClass(b)), text)
+		h.printBlocks(b.Content)
+		h.W(`</blockquote>`)
+	case notiontypes.BlockCallout:
+		h.W(`<div id="%s"%s>%s<div class="callout-text">%s`, id, class("callout", colorClass(b)), h.icon(b), text)
+		h.printBlocks(b.Content)
+		h.W(`</div></div>`)
+	case notiontypes.BlockCode:
+		lang := strings.ToLower(b.CodeLanguage)
+		h.W(`<pre id="%s"><code%s>%s</code></pre>`, id, class(languageClass(lang)), html.EscapeString(b.Code))
+	case notiontypes.BlockEquation:
+		h.W(`<div id="%s" class="equation">\[%s\]</div>`, id, html.EscapeString(b.Equation))
+	case notiontypes.BlockDivider:
+		h.W(`<hr id="%s"%s>`, id, h.slash())
+	case notiontypes.BlockImage:
+		h.W(`<figure id="%s" class="image"><img src="%s" alt=""%s>`, id, attr(h.cfg.imageURL(b)), h.slash())
+		if text != "" {
+			h.W(`<figcaption>%s</figcaption>`, text)
+		}
+		h.W(`</figure>`)
+	case notiontypes.BlockBookmark:
+		title := text
+		if title == "" {
+			title = html.EscapeString(b.Link)
+		}
+		if isSafeURL(b.Link) {
+			title = fmt.Sprintf(`<a href="%s">%s</a>`, attr(b.Link), title)
+		}
+		h.W(`<p id="%s" class="bookmark">%s`, id, title)
+		if b.Description != "" {
+			h.W(`<br%s><span class="bookmark-description">%s</span>`, h.slash(), html.EscapeString(b.Description))
+		}
+		h.W(`</p>`)
+	case notiontypes.BlockAudio:
+		h.W(`<audio id="%s"%s src="%s"></audio>`, id, h.boolAttr("controls"), attr(h.cfg.fileURL(b)))
+	case notiontypes.BlockVideo, notiontypes.BlockEmbed, notiontypes.BlockPDF, notiontypes.BlockGist:
+		if src := h.cfg.fileURL(b); isSafeURL(src) {
+			h.W(`<iframe id="%s"%s src="%s"></iframe>`, id, class(b.Type), attr(src))
+		}
+	case notiontypes.BlockFile:
+		title := text
+		if title == "" {
+			title = html.EscapeString(b.Source)
+		}
+		if src := h.cfg.fileURL(b); isSafeURL(src) {
+			title = fmt.Sprintf(`<a href="%s">%s</a>`, attr(src), title)
+		}
+		h.W(`<p id="%s" class="file">%s</p>`, id, title)
+	case notiontypes.BlockTable:
+		h.printTable(b)
+	case notiontypes.BlockTableOfContents:
+		h.W(`<nav id="%s" class="table-of-contents">`, id)
+		for _, hdr := range headers(h.root) {
+			h.W(`<a class="toc-level-%d" href="#%s">%s</a>`, hdr.HeaderLevel(), hdr.ID, html.EscapeString(inlineText(hdr.InlineContent)))
+		}
+		h.W(`</nav>`)
+	case notiontypes.BlockBreadcrumb, notiontypes.BlockCollectionView:
+		// nothing to show
+	case notiontypes.BlockColumnList:
+		h.W(`<div id="%s" class="column-list">`, id)
+		h.printBlocks(b.Content)
+		h.W(`</div>`)
+	case notiontypes.BlockColumn:
+		style := ""
+		if b.FormatColumn != nil && b.FormatColumn.ColumnRation > 0 {
+			style = fmt.Sprintf(` style="width:%.0f%%"`, b.FormatColumn.ColumnRation*100)
+		}
+		h.W(`<div id="%s" class="column"%s>`, id, style)
+		h.printBlocks(b.Content)
+		h.W(`</div>`)
+	case notiontypes.BlockTransclusionContainer, notiontypes.BlockTransclusionReference:
+		h.W(`<div id="%s" class="synced-block">`, id)
+		h.printBlocks(b.SyncedContent())
+		h.W(`</div>`)
+	default:
+		h.W(`<p id="%s"%s>%s</p>`, id, class(colorClass(b)), text)
+		if len(b.Content) > 0 {
+			h.W(`<div class="indented">`)
+			h.printBlocks(b.Content)
+			h.W(`</div>`)
+		}
+	}
+	h.printDiscussions(b)
+}
+
+func languageClass(lang string) string {
+	if lang == "" {
+		return ""
+	}
+	return "language-" + Slugify(lang)
+}
+
+func (h *htmlPrinter) printTable(b *notiontypes.Block) {
+	h.W(`<table id="%s">`, b.ID)
+	columnHeader := b.FormatTable != nil && b.FormatTable.TableBlockColumnHeader
+	rowHeader := b.FormatTable != nil && b.FormatTable.TableBlockRowHeader
+	for i, row := range b.Content {
+		var sb strings.Builder
+		for j, cell := range row.Cells {
+			tag := "td"
+			if (i == 0 && columnHeader) || (j == 0 && rowHeader) {
+				tag = "th"
+			}
+			fmt.Fprintf(&sb, "<%s>%s</%s>", tag, h.inline(cell), tag)
+		}
+		h.W(`<tr id="%s">%s</tr>`, row.ID, sb.String())
+	}
+	h.W(`</table>`)
+}
+
+// printDiscussions prints discussions of a block as margin notes.
+func (h *htmlPrinter) printDiscussions(b *notiontypes.Block) {
+	for _, d := range b.Discussions {
+		if len(d.Comments) == 0 {
+			continue
+		}
+		h.W(`<aside id="%s" class="discussion">`, d.ID)
+		for _, c := range d.Comments {
+			author := c.CreatedBy
+			if c.Author != nil {
+				author = c.Author.Name()
+			}
+			h.W(`<p class="comment"><span class="comment-author">%s</span> %s</p>`, html.EscapeString(author), h.inline(c.InlineContent))
+		}
+		h.W(`</aside>`)
+	}
+}
+
+func (h *htmlPrinter) inline(blocks []*notiontypes.InlineBlock) string {
+	var sb strings.Builder
+	for _, b := range blocks {
+		sb.WriteString(h.inlineBlock(b))
+	}
+	return sb.String()
+}
+
+func (h *htmlPrinter) inlineBlock(b *notiontypes.InlineBlock) string {
+	s := html.EscapeString(plainText(b))
+	switch {
+	case b.IsEquation():
+		s = `<span class="equation">\(` + html.EscapeString(b.Equation) + `\)</span>`
+	case b.IsPageMention():
+		s = fmt.Sprintf(`<a class="page-mention" href="%s">%s</a>`, attr(h.cfg.pageURL(b.PageID)), s)
+	case b.UserID != "":
+		s = `<span class="user-mention">` + s + `</span>`
+	case b.Date != nil:
+		if t, err := b.Date.Start(); err == nil {
+			s = fmt.Sprintf(`<time datetime="%s">%s</time>`, t.Format(time.RFC3339), s)
+		}
+	}
+	if b.AttrFlags&notiontypes.AttrCode != 0 {
+		s = "<code>" + s + "</code>"
+	}
+	if b.AttrFlags&notiontypes.AttrBold != 0 {
+		s = "<strong>" + s + "</strong>"
+	}
+	if b.AttrFlags&notiontypes.AttrItalic != 0 {
+		s = "<em>" + s + "</em>"
+	}
+	if b.AttrFlags&notiontypes.AttrStrikeThrought != 0 {
+		s = "<del>" + s + "</del>"
+	}
+	if b.AttrFlags&notiontypes.AttrUnderline != 0 {
+		s = "<u>" + s + "</u>"
+	}
+	if b.Color != "" || b.BackgroundColor != "" {
+		var classes []string
+		if b.Color != "" {
+			classes = append(classes, "color-"+b.Color)
+		}
+		if b.BackgroundColor != "" {
+			classes = append(classes, "background-"+b.BackgroundColor)
+		}
+		s = fmt.Sprintf(`<span class="%s">%s</span>`, strings.Join(classes, " "), s)
+	}
+	if len(b.DiscussionIDs) > 0 {
+		s = fmt.Sprintf(`<mark class="commented" data-discussions="%s">%s</mark>`, attr(strings.Join(b.DiscussionIDs, " ")), s)
+	}
+	if b.Link != "" && isSafeURL(b.Link) {
+		s = fmt.Sprintf(`<a href="%s">%s</a>`, attr(b.Link), s)
+	}
+	return s
+}
+
+// isSafeURL reports whether a url from page content can be linked to. Only
+// relative urls and http, https and mailto urls are, urls like javascript:
+// and data: ones can run scripts in the page.
+func isSafeURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+// attr escapes s for use as an attribute value.
+func attr(s string) string {
+	return html.EscapeString(s)
+}
+
+// PrintAsHTML renders a notion page as an HTML fragment. Discussions are
+// rendered as margin notes in <aside> elements.
+func PrintAsHTML(page *notiontypes.Block, opts ...PrintOption) ([]byte, error) {
+	h := &htmlPrinter{buf: new(bytes.Buffer), cfg: newPrintConfig(opts), root: page}
+	h.printPage(page)
+	return h.buf.Bytes(), nil
+}
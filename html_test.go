@@ -0,0 +1,46 @@
+package notion
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintAsHTMLUnsafeLinks(t *testing.T) {
+	page := testPage(t, `[
+	{"id":"p","type":"page","properties":{"title":[["Links"]]},"content":["t","bm"]},
+	{"id":"t","type":"text","properties":{"title":[["safe",[["a","https://example.com"]]],["rel",[["a","/other"]]],["bad",[["a","javascript:alert(1)"]]],["data",[["a","data:text/html,x"]]]]}},
+	{"id":"bm","type":"bookmark","properties":{"link":[["JavaScript:alert(2)"]],"title":[["Bookmark"]]}}
+	]`)
+	got, err := PrintAsHTML(page.Block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(got)
+	for _, want := range []string{`<a href="https://example.com">safe</a>`, `<a href="/other">rel</a>`, "bad", "data", "Bookmark"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, s)
+		}
+	}
+	if strings.Contains(strings.ToLower(s), "javascript:") || strings.Contains(s, "data:") {
+		t.Errorf("output contains an unsafe link:\n%s", s)
+	}
+}
+
+func TestIsSafeURL(t *testing.T) {
+	for u, want := range map[string]bool{
+		"https://example.com": true,
+		"http://example.com":  true,
+		"mailto:a@b.c":        true,
+		"../page.html#x":      true,
+		"#anchor":             true,
+		"javascript:alert(1)": false,
+		" javascript:x":       false,
+		"java\tscript:x":      false,
+		"data:text/html,x":    false,
+		"vbscript:x":          false,
+	} {
+		if got := isSafeURL(u); got != want {
+			t.Errorf("isSafeURL(%q) = %v, want %v", u, got, want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package notion
+
+import (
+	"crypto/rand"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// NewID returns a random id in the format notion.so uses for records.
+func NewID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	// version 4 uuid
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+var hexID = regexp.MustCompile(`[0-9a-fA-F]{32}$`)
+
+// ParsePageID returns the id of a page, given either the id with or without
+// dashes or a notion.so url of the page.
+func ParsePageID(s string) (string, error) {
+	orig := s
+	if i := strings.Index(s, "://"); i >= 0 {
+		host := s[i+3:]
+		if j := strings.IndexAny(host, "/?#"); j >= 0 {
+			host = host[:j]
+		}
+		if !isNotionHost(host) {
+			return "", fmt.Errorf("notion: '%s' is not a notion.so url", orig)
+		}
+	}
+	if i := strings.IndexAny(s, "?#"); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimRight(s, "/")
+	if i := strings.LastIndex(s, "/"); i >= 0 {
+		s = s[i+1:]
+	}
+	id := hexID.FindString(strings.Replace(s, "-", "", -1))
+	if id == "" {
+		return "", fmt.Errorf("notion: '%s' is not a page id or url", orig)
+	}
+	id = strings.ToLower(id)
+	return fmt.Sprintf("%s-%s-%s-%s-%s", id[0:8], id[8:12], id[12:16], id[16:20], id[20:]), nil
+}
+
+// isNotionHost returns true for hosts of notion.so and of sites published
+// with notion.site.
+func isNotionHost(host string) bool {
+	host = strings.ToLower(host)
+	return host == "notion.so" || strings.HasSuffix(host, ".notion.so") || strings.HasSuffix(host, ".notion.site")
+}
+
+// parseNotionLink returns the ids of the page and block that a link refers
+// to if it is a notion.so url or a path like "/Title-<id>#<block-id>", as
+// used for links between pages. blockID is empty for links to pages.
+func parseNotionLink(u string) (pageID string, blockID string, ok bool) {
+	rest := u
+	for _, prefix := range []string{"https://", "http://"} {
+		rest = strings.TrimPrefix(rest, prefix)
+	}
+	switch {
+	case rest != u:
+		i := strings.IndexByte(rest, '/')
+		if i < 0 {
+			return "", "", false
+		}
+		host := rest[:i]
+		if !isNotionHost(host) {
+			return "", "", false
+		}
+		rest = rest[i:]
+	case !strings.HasPrefix(u, "/"):
+		return "", "", false
+	}
+	var fragment string
+	if i := strings.IndexByte(rest, '#'); i >= 0 {
+		rest, fragment = rest[:i], rest[i+1:]
+	}
+	pageID, err := ParsePageID(rest)
+	if err != nil {
+		return "", "", false
+	}
+	if fragment != "" {
+		blockID, _ = ParsePageID(fragment)
+	}
+	return pageID, blockID, true
+}
@@ -0,0 +1,62 @@
+package notion
+
+import "testing"
+
+func TestParsePageID(t *testing.T) {
+	const id = "0b6a8f5e-4c3d-4a2b-9e1f-123456789abc"
+	tests := []struct {
+		in, want string
+	}{
+		{"0b6a8f5e4c3d4a2b9e1f123456789abc", id},
+		{"0b6a8f5e-4c3d-4a2b-9e1f-123456789abc", id},
+		{"0B6A8F5E4C3D4A2B9E1F123456789ABC", id},
+		{"https://www.notion.so/Meeting-Notes-0b6a8f5e4c3d4a2b9e1f123456789abc", id},
+		{"https://www.notion.so/team/Cafe-0b6a8f5e4c3d4a2b9e1f123456789abc?v=1&p=2", id},
+		{"https://notion.so/0b6a8f5e4c3d4a2b9e1f123456789abc#aaaaaaaabbbbccccddddeeeeeeeeeeee", id},
+		{"https://team.notion.site/Page-0b6a8f5e4c3d4a2b9e1f123456789abc/", id},
+		{"https://example.com/Page-0b6a8f5e4c3d4a2b9e1f123456789abc", ""},
+		{"https://evilnotion.so/0b6a8f5e4c3d4a2b9e1f123456789abc", ""},
+		{"https://www.notion.so/Page", ""},
+		{"0b6a8f5e4c3d", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, err := ParsePageID(tt.in)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("ParsePageID(%q) = %q, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParsePageID(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseNotionLink(t *testing.T) {
+	const page, block = "0b6a8f5e-4c3d-4a2b-9e1f-123456789abc", "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+	tests := []struct {
+		in          string
+		page, block string
+		ok          bool
+	}{
+		{"/Meeting-Notes-0b6a8f5e4c3d4a2b9e1f123456789abc", page, "", true},
+		{"/0b6a8f5e4c3d4a2b9e1f123456789abc#aaaaaaaabbbbccccddddeeeeeeeeeeee", page, block, true},
+		{"https://www.notion.so/Notes-0b6a8f5e4c3d4a2b9e1f123456789abc?pvs=4#aaaaaaaabbbbccccddddeeeeeeeeeeee", page, block, true},
+		{"http://notion.so/0b6a8f5e-4c3d-4a2b-9e1f-123456789abc", page, "", true},
+		{"https://team.notion.site/Notes-0b6a8f5e4c3d4a2b9e1f123456789abc#not-a-block", page, "", true},
+		{"https://example.com/Notes-0b6a8f5e4c3d4a2b9e1f123456789abc", "", "", false},
+		{"https://evilnotion.so/Notes-0b6a8f5e4c3d4a2b9e1f123456789abc", "", "", false},
+		{"https://www.notion.so", "", "", false},
+		{"https://www.notion.so/Notes", "", "", false},
+		{"mailto:a@example.com", "", "", false},
+		{"Notes-0b6a8f5e4c3d4a2b9e1f123456789abc", "", "", false},
+	}
+	for _, tt := range tests {
+		page, block, ok := parseNotionLink(tt.in)
+		if page != tt.page || block != tt.block || ok != tt.ok {
+			t.Errorf("parseNotionLink(%q) = %q, %q, %v, want %q, %q, %v", tt.in, page, block, ok, tt.page, tt.block, tt.ok)
+		}
+	}
+}
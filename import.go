@@ -0,0 +1,125 @@
+package notion
+
+import (
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// maxTransactionOperations limits the number of operations submitted in a
+// single transaction when creating many blocks.
+const maxTransactionOperations = 200
+
+// blockRecord returns the record of a new block, as set by a transaction.
+func blockRecord(b *notiontypes.Block, parentID string, parentTable string, userID string, ts int64) map[string]interface{} {
+	r := map[string]interface{}{
+		"id":               b.ID,
+		"version":          1,
+		"type":             b.Type,
+		"alive":            true,
+		"parent_id":        parentID,
+		"parent_table":     parentTable,
+		"created_by":       userID,
+		"created_time":     ts,
+		"last_edited_by":   userID,
+		"last_edited_time": ts,
+	}
+	if b.CollectionID != "" {
+		r["collection_id"] = b.CollectionID
+	}
+	if len(b.ViewIDs) > 0 {
+		r["view_ids"] = b.ViewIDs
+	}
+	if props := b.RawProperties(); len(props) > 0 {
+		r["properties"] = props
+	}
+	switch {
+	case len(b.FormatRaw) > 0:
+		r["format"] = b.FormatRaw
+	case b.Type == notiontypes.BlockImage && b.Source != "":
+		r["format"] = map[string]interface{}{"display_source": b.Source}
+	}
+	return r
+}
+
+// createBlockOps returns operations that create b and its content as a child
+// of parentID, after the block with id afterID (or at the end). Blocks without
+// an id are assigned a new one. Operations are grouped by block so they can be
+// split into transactions between groups.
+func createBlockOps(b *notiontypes.Block, parentID string, parentTable string, afterID string, userID string, ts int64) [][]*Operation {
+	if b.ID == "" {
+		b.ID = NewID()
+	}
+	ops := []*Operation{{
+		ID:      b.ID,
+		Table:   notiontypes.TableBlock,
+		Path:    []string{},
+		Command: CommandSet,
+		Args:    blockRecord(b, parentID, parentTable, userID, ts),
+	}}
+	if parentTable == notiontypes.TableBlock {
+		ops = append(ops, &Operation{
+			ID:      parentID,
+			Table:   notiontypes.TableBlock,
+			Path:    []string{"content"},
+			Command: CommandListAfter,
+			Args:    ListArgs{ID: b.ID, After: afterID},
+		})
+	}
+	groups := [][]*Operation{ops}
+	prev := ""
+	for _, child := range b.Content {
+		groups = append(groups, createBlockOps(child, b.ID, notiontypes.TableBlock, prev, userID, ts)...)
+		prev = child.ID
+	}
+	return groups
+}
+
+// submitInChunks submits groups of operations in as few transactions as
+// possible without exceeding maxTransactionOperations.
+func (c *Client) submitInChunks(groups [][]*Operation) error {
+	var ops []*Operation
+	for _, g := range groups {
+		if len(ops) > 0 && len(ops)+len(g) > maxTransactionOperations {
+			if err := c.SubmitTransaction(ops...); err != nil {
+				return err
+			}
+			ops = nil
+		}
+		ops = append(ops, g...)
+	}
+	return c.SubmitTransaction(ops...)
+}
+
+// AppendBlocks creates blocks and their content at the end of the content of
+// the block with the given id. Large numbers of blocks are created in
+// multiple transactions, in order. Blocks are assigned new ids unless they
+// already have one.
+func (c *Client) AppendBlocks(parentID string, blocks []*notiontypes.Block) error {
+	userID, err := c.currentUserID()
+	if err != nil {
+		return err
+	}
+	ts := now()
+	var groups [][]*Operation
+	prev := ""
+	for _, b := range blocks {
+		groups = append(groups, createBlockOps(b, parentID, notiontypes.TableBlock, prev, userID, ts)...)
+		prev = b.ID
+	}
+	return errors.Wrap(c.submitInChunks(groups), "creating blocks")
+}
+
+// CreatePage creates a page with the given title and content as the last
+// sub-page of the page with id parentID, and returns the id of the new page.
+func (c *Client) CreatePage(parentID string, title string, blocks []*notiontypes.Block) (string, error) {
+	page := &notiontypes.Block{Type: notiontypes.BlockPage, Title: title, Content: blocks}
+	userID, err := c.currentUserID()
+	if err != nil {
+		return "", err
+	}
+	groups := createBlockOps(page, parentID, notiontypes.TableBlock, "", userID, now())
+	if err := c.submitInChunks(groups); err != nil {
+		return "", errors.Wrap(err, "creating page")
+	}
+	return page.ID, nil
+}
@@ -0,0 +1,360 @@
+package notion
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+type latexPrinter struct {
+	buf *bytes.Buffer
+	cfg *printConfig
+}
+
+// W writes a line.
+func (l *latexPrinter) W(format string, args ...interface{}) {
+	fmt.Fprintf(l.buf, format, args...)
+	l.buf.WriteString("\n")
+}
+
+const latexPreamble = `\documentclass{article}
+\usepackage[utf8]{inputenc}
+\usepackage[T1]{fontenc}
+\usepackage{amsmath}
+\usepackage{amssymb}
+\usepackage{graphicx}
+\usepackage{listings}
+\usepackage[normalem]{ulem}
+\usepackage{hyperref}
+\lstset{basicstyle=\ttfamily\small,breaklines=true,columns=fullflexible}
+`
+
+func (l *latexPrinter) printPage(page *notiontypes.Block) {
+	l.buf.WriteString(latexPreamble)
+	if !l.cfg.noTitle {
+		l.W(`\title{%s%s}`, latexEscape(page.Title), l.footnotes(page))
+		l.W(`\date{%s}`, page.UpdatedOn().UTC().Format("January 2, 2006"))
+	}
+	l.W(`\begin{document}`)
+	if !l.cfg.noTitle {
+		l.W(`\maketitle`)
+	}
+	l.W("")
+	l.printBlocks(page.Content)
+	l.W(`\end{document}`)
+}
+
+// latexList returns the list environment for a list item, or "".
+func latexList(b *notiontypes.Block) string {
+	switch b.Type {
+	case notiontypes.BlockBulletedList, notiontypes.BlockTodo, notiontypes.BlockToggle:
+		return "itemize"
+	case notiontypes.BlockNumberedList:
+		return "enumerate"
+	}
+	return ""
+}
+
+// printBlocks prints blocks, grouping consecutive list items in lists.
+func (l *latexPrinter) printBlocks(blocks []*notiontypes.Block) {
+	open := ""
+	for _, b := range blocks {
+		env := latexList(b)
+		if env != open {
+			if open != "" {
+				l.W(`\end{%s}`, open)
+				l.W("")
+			}
+			if env != "" {
+				l.W(`\begin{%s}`, env)
+			}
+			open = env
+		}
+		l.printBlock(b)
+	}
+	if open != "" {
+		l.W(`\end{%s}`, open)
+		l.W("")
+	}
+}
+
+var latexSections = []string{"section", "subsection", "subsubsection"}
+
+func (l *latexPrinter) printBlock(b *notiontypes.Block) {
+	text := l.inline(b.InlineContent) + l.footnotes(b)
+	switch b.Type {
+	case notiontypes.BlockPage, notiontypes.BlockAlias:
+		id, title := pageTitle(b)
+		l.W(`\href{%s}{%s}`, latexURL(l.cfg.pageURL(id)), latexEscape(title))
+		l.W("")
+	case notiontypes.BlockHeader, notiontypes.BlockSubHeader, notiontypes.BlockSubSubHeader:
+		l.W(`\%s{%s}`, latexSections[b.HeaderLevel()-1], latexOneLine(text))
+		l.W(`\label{%s}`, b.ID)
+		l.W("")
+		l.printBlocks(b.Content)
+	case notiontypes.BlockBulletedList, notiontypes.BlockNumberedList, notiontypes.BlockToggle:
+		l.W(`\item %s`, text)
+		l.printBlocks(b.Content)
+	case notiontypes.BlockTodo:
+		box := `$\square$`
+		if b.IsChecked {
+			box = `$\boxtimes$`
+		}
+		l.W(`\item[%s] %s`, box, text)
+		l.printBlocks(b.Content)
+	case notiontypes.BlockQuote, notiontypes.BlockCallout:
+		l.W(`\begin{quote}`)
+		l.W("%s", text)
+		if len(b.Content) > 0 {
+			l.W("")
+			l.printBlocks(b.Content)
+		}
+		l.W(`\end{quote}`)
+		l.W("")
+	case notiontypes.BlockCode:
+		if lang := latexLanguage(b.CodeLanguage); lang != "" {
+			l.W(`\begin{lstlisting}[language=%s]`, lang)
+			l.W("%s", b.Code)
+			l.W(`\end{lstlisting}`)
+		} else {
+			l.W(`\begin{verbatim}`)
+			l.W("%s", b.Code)
+			l.W(`\end{verbatim}`)
+		}
+		l.W("")
+	case notiontypes.BlockEquation:
+		l.W(`\[`)
+		l.W("%s", b.Equation)
+		l.W(`\]`)
+		l.W("")
+	case notiontypes.BlockDivider:
+		l.W(`\noindent\rule{\linewidth}{0.4pt}`)
+		l.W("")
+	case notiontypes.BlockImage:
+		l.printImage(b, text)
+	case notiontypes.BlockBookmark:
+		if text == "" {
+			l.W(`\url{%s}`, latexURL(b.Link))
+		} else {
+			l.W(`\href{%s}{%s}`, latexURL(b.Link), text)
+		}
+		l.W("")
+	case notiontypes.BlockFile, notiontypes.BlockPDF, notiontypes.BlockAudio,
+		notiontypes.BlockVideo, notiontypes.BlockEmbed, notiontypes.BlockGist:
+		u := l.cfg.fileURL(b)
+		if text == "" {
+			l.W(`\url{%s}`, latexURL(u))
+		} else {
+			l.W(`\href{%s}{%s}`, latexURL(u), text)
+		}
+		l.W("")
+	case notiontypes.BlockTable:
+		l.printTable(b)
+	case notiontypes.BlockTableOfContents:
+		l.W(`\tableofcontents`)
+		l.W("")
+	case notiontypes.BlockBreadcrumb, notiontypes.BlockCollectionView:
+		// nothing to show
+	case notiontypes.BlockColumnList, notiontypes.BlockColumn,
+		notiontypes.BlockTransclusionContainer, notiontypes.BlockTransclusionReference:
+		l.printBlocks(b.SyncedContent())
+	default:
+		if text != "" {
+			l.W("%s", text)
+			l.W("")
+		}
+		l.printBlocks(b.Content)
+	}
+}
+
+// latexImageExts are the image formats supported by pdflatex.
+var latexImageExts = map[string]bool{".png": true, ".jpg": true, ".jpeg": true, ".pdf": true}
+
+// printImage prints an image as a figure if it is a local file in a format
+// LaTeX can include, e.g. downloaded by an AssetDownloader, and as a link
+// otherwise.
+func (l *latexPrinter) printImage(b *notiontypes.Block, caption string) {
+	u := l.cfg.imageURL(b)
+	local := !strings.Contains(u, "://") && latexImageExts[strings.ToLower(path.Ext(u))]
+	if !local {
+		if caption == "" {
+			caption = "Image"
+		}
+		l.W(`\href{%s}{%s}`, latexURL(u), caption)
+		l.W("")
+		return
+	}
+	l.W(`\begin{figure}[h]`)
+	l.W(`\centering`)
+	l.W(`\includegraphics[width=\linewidth,height=0.8\textheight,keepaspectratio]{%s}`, u)
+	if caption != "" {
+		l.W(`\caption{%s}`, caption)
+	}
+	l.W(`\end{figure}`)
+	l.W("")
+}
+
+func (l *latexPrinter) printTable(b *notiontypes.Block) {
+	cols := 0
+	for _, row := range b.Content {
+		if len(row.Cells) > cols {
+			cols = len(row.Cells)
+		}
+	}
+	if cols == 0 {
+		return
+	}
+	l.W(`\begin{tabular}{|%s}`, strings.Repeat("l|", cols))
+	l.W(`\hline`)
+	header := b.FormatTable != nil && b.FormatTable.TableBlockColumnHeader
+	for i, row := range b.Content {
+		cells := make([]string, cols)
+		for j, cell := range row.Cells {
+			cells[j] = latexOneLine(l.inline(cell))
+			if i == 0 && header {
+				cells[j] = `\textbf{` + cells[j] + `}`
+			}
+		}
+		l.W(`%s \\`, strings.Join(cells, " & "))
+		if i == 0 && header {
+			l.W(`\hline`)
+		}
+	}
+	l.W(`\hline`)
+	l.W(`\end{tabular}`)
+	l.W("")
+}
+
+// footnotes returns discussions of a block as footnotes.
+func (l *latexPrinter) footnotes(b *notiontypes.Block) string {
+	var s string
+	for _, d := range b.Discussions {
+		var comments []string
+		for _, c := range d.Comments {
+			comments = append(comments, latexEscape(commentText(c)))
+		}
+		if len(comments) > 0 {
+			s += `\footnote{` + strings.Join(comments, "; ") + `}`
+		}
+	}
+	return s
+}
+
+func (l *latexPrinter) inline(blocks []*notiontypes.InlineBlock) string {
+	var sb strings.Builder
+	for _, b := range blocks {
+		sb.WriteString(l.inlineBlock(b))
+	}
+	return sb.String()
+}
+
+func (l *latexPrinter) inlineBlock(b *notiontypes.InlineBlock) string {
+	switch {
+	case b.IsEquation():
+		return "$" + b.Equation + "$"
+	case b.IsPageMention():
+		return fmt.Sprintf(`\href{%s}{%s}`, latexURL(l.cfg.pageURL(b.PageID)), latexEscape(plainText(b)))
+	}
+	s := latexEscape(plainText(b))
+	if b.AttrFlags&notiontypes.AttrCode != 0 {
+		s = `\texttt{` + s + `}`
+	}
+	if b.AttrFlags&notiontypes.AttrBold != 0 {
+		s = `\textbf{` + s + `}`
+	}
+	if b.AttrFlags&notiontypes.AttrItalic != 0 {
+		s = `\textit{` + s + `}`
+	}
+	if b.AttrFlags&notiontypes.AttrStrikeThrought != 0 {
+		s = `\sout{` + s + `}`
+	}
+	if b.AttrFlags&notiontypes.AttrUnderline != 0 {
+		s = `\uline{` + s + `}`
+	}
+	if b.Link != "" {
+		s = fmt.Sprintf(`\href{%s}{%s}`, latexURL(b.Link), s)
+	}
+	return s
+}
+
+var latexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`$`, `\$`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+	"\n", `\\`+"\n",
+)
+
+// latexEscape escapes characters with a special meaning in LaTeX.
+func latexEscape(s string) string {
+	return latexEscaper.Replace(s)
+}
+
+var latexLineBreaks = strings.NewReplacer(`\\`+"\n", " ", "\n", " ")
+
+// latexOneLine replaces line breaks of escaped text by spaces, for text of
+// table cells and section titles, where \\ ends the row or breaks the title.
+func latexOneLine(s string) string {
+	return latexLineBreaks.Replace(s)
+}
+
+var latexURLEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `#`, `\#`, `{`, `\{`, `}`, `\}`)
+
+// latexURL escapes an url for \href and \url.
+func latexURL(u string) string {
+	return latexURLEscaper.Replace(u)
+}
+
+// latexLanguages maps notion code languages to languages of the listings
+// package. Code in other languages is printed verbatim.
+var latexLanguages = map[string]string{
+	"bash":         "bash",
+	"shell":        "bash",
+	"c":            "C",
+	"c++":          "C++",
+	"fortran":      "Fortran",
+	"haskell":      "Haskell",
+	"html":         "HTML",
+	"java":         "Java",
+	"lisp":         "Lisp",
+	"lua":          "Lua",
+	"makefile":     "make",
+	"matlab":       "Matlab",
+	"ocaml":        "ML",
+	"pascal":       "Pascal",
+	"perl":         "Perl",
+	"php":          "PHP",
+	"python":       "Python",
+	"r":            "R",
+	"ruby":         "Ruby",
+	"scala":        "Scala",
+	"sql":          "SQL",
+	"tex":          "TeX",
+	"latex":        "TeX",
+	"xml":          "XML",
+	"visual basic": "VBScript",
+}
+
+func latexLanguage(lang string) string {
+	return latexLanguages[strings.ToLower(lang)]
+}
+
+// PrintAsLaTeX renders a notion page as a LaTeX document that can be
+// compiled with pdflatex. Headers become sections, code is printed in
+// lstlisting environments, equations are passed through and discussions
+// become footnotes. Images are included if their urls refer to local files,
+// see WithAssetURLs and AssetDownloader, and linked otherwise.
+func PrintAsLaTeX(page *notiontypes.Block, opts ...PrintOption) ([]byte, error) {
+	l := &latexPrinter{buf: new(bytes.Buffer), cfg: newPrintConfig(opts)}
+	l.printPage(page)
+	return l.buf.Bytes(), nil
+}
@@ -0,0 +1,78 @@
+package notion
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintAsLaTeX(t *testing.T) {
+	page := testPage(t, `[
+	{"id":"p","type":"page","last_edited_time":1577836800000,"properties":{"title":[["Specs & Notes"]]},"content":["h","t","b1","b2","c","i","img"]},
+	{"id":"h","type":"header","properties":{"title":[["Intro\nand more"]]}},
+	{"id":"t","type":"text","properties":{"title":[["costs 5$ or 10% "],["bold",[["b"]]],[" and "],["⁍",[["e","x^2"]]]]}},
+	{"id":"b1","type":"bulleted_list","properties":{"title":[["one"]]}},
+	{"id":"b2","type":"to_do","properties":{"title":[["done"]],"checked":[["Yes"]]}},
+	{"id":"c","type":"code","properties":{"title":[["print(1)"]],"language":[["Python"]]}},
+	{"id":"i","type":"image","properties":{"source":[["https://example.com/a.png"]]}},
+	{"id":"img","type":"image","properties":{"source":[["https://example.com/b.png"]],"title":[["B"]]}}
+	]`)
+	got, err := PrintAsLaTeX(page.Block, WithAssetURLs(func(u string) string {
+		if strings.HasSuffix(u, "b.png") {
+			return "images/b.png"
+		}
+		return u
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `\title{Specs \& Notes}
+\date{January 1, 2020}
+\begin{document}
+\maketitle
+
+\section{Intro and more}
+\label{h}
+
+costs 5\$ or 10\% \textbf{bold} and $x^2$
+
+\begin{itemize}
+\item one
+\item[$\boxtimes$] done
+\end{itemize}
+
+\begin{lstlisting}[language=Python]
+print(1)
+\end{lstlisting}
+
+\href{https://www.notion.so/image/https:\%2F\%2Fexample.com\%2Fa.png}{Image}
+
+\begin{figure}[h]
+\centering
+\includegraphics[width=\linewidth,height=0.8\textheight,keepaspectratio]{images/b.png}
+\caption{B}
+\end{figure}
+
+\end{document}
+`
+	if !strings.HasPrefix(string(got), latexPreamble) {
+		t.Fatalf("missing preamble:\n%s", got)
+	}
+	if body := strings.TrimPrefix(string(got), latexPreamble); body != want {
+		t.Errorf("PrintAsLaTeX() =\n%s\nwant:\n%s", body, want)
+	}
+}
+
+func TestPrintAsLaTeXTableNewlines(t *testing.T) {
+	page := testPage(t, `[
+	{"id":"p","type":"page","properties":{"title":[["T"]]},"content":["tbl"]},
+	{"id":"tbl","type":"table","format":{"table_block_column_order":["a","b"]},"content":["r1"]},
+	{"id":"r1","type":"table_row","properties":{"a":[["two\nlines"]],"b":[["x"]]}}
+	]`)
+	got, err := PrintAsLaTeX(page.Block, WithoutTitle())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "two lines & x \\\\\n"; !strings.Contains(string(got), want) {
+		t.Errorf("table row %q not in:\n%s", want, got)
+	}
+}
@@ -0,0 +1,256 @@
+package notion
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// Kinds of links between pages.
+const (
+	// LinkChild links a page to a sub-page.
+	LinkChild = "child"
+	// LinkRow links a page to a row of a database shown in it.
+	LinkRow = "row"
+	// LinkAlias is a "link to page" block.
+	LinkAlias = "alias"
+	// LinkMention is a mention of a page in text.
+	LinkMention = "mention"
+	// LinkURL is a link in text to a notion.so url of a page or block.
+	LinkURL = "url"
+	// LinkRelation is a value of a relation property of a database row.
+	LinkRelation = "relation"
+)
+
+// PageLink is a link from one page to another.
+type PageLink struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Kind is one of the Link constants, e.g. LinkMention.
+	Kind string `json:"kind"`
+	// BlockID is the id of the block containing the link, if the link is in
+	// a block of the page. For relations it is the name of the property.
+	BlockID string `json:"block,omitempty"`
+}
+
+// LinkGraph is an index of links between pages, for finding backlinks and
+// pages that are not linked to.
+type LinkGraph struct {
+	// Titles of added pages, by id.
+	Titles map[string]string
+
+	links []PageLink
+	out   map[string][]int
+	in    map[string][]int
+	seen  map[PageLink]bool
+}
+
+// NewLinkGraph initializes an empty LinkGraph.
+func NewLinkGraph() *LinkGraph {
+	return &LinkGraph{
+		Titles: map[string]string{},
+		out:    map[string][]int{},
+		in:     map[string][]int{},
+		seen:   map[PageLink]bool{},
+	}
+}
+
+// AddTree adds all pages of a crawled tree.
+func (g *LinkGraph) AddTree(tree *PageTree) {
+	for _, t := range tree.Pages() {
+		g.AddPage(t.Page)
+	}
+}
+
+// AddPage adds a page and the links from it to other pages.
+func (g *LinkGraph) AddPage(page *Page) {
+	g.Titles[page.ID] = page.Title
+	from := page.ID
+	for _, id := range SubPageIDs(page.Block) {
+		g.add(PageLink{From: from, To: id, Kind: LinkChild})
+	}
+	for _, id := range CollectionRowIDs(page.Block) {
+		g.add(PageLink{From: from, To: id, Kind: LinkRow})
+	}
+	notiontypes.Inspect(page.Block, func(b *notiontypes.Block) bool {
+		if b != page.Block && b.IsPage() {
+			// the content of sub-pages is added with them
+			return false
+		}
+		if b.Type == notiontypes.BlockAlias {
+			if id, _ := pageTitle(b); id != "" {
+				g.add(PageLink{From: from, To: id, Kind: LinkAlias, BlockID: b.ID})
+			}
+		}
+		g.addInline(from, b.ID, b.InlineContent)
+		for _, cell := range b.Cells {
+			g.addInline(from, b.ID, cell)
+		}
+		return true
+	})
+	if page.Collection != nil {
+		for _, p := range page.Collection.RowProperties(page.Block) {
+			if ids, ok := p.Value.([]string); ok && p.Type == notiontypes.ColumnTypeRelation {
+				for _, id := range ids {
+					g.add(PageLink{From: from, To: id, Kind: LinkRelation, BlockID: p.Name})
+				}
+			}
+		}
+	}
+}
+
+func (g *LinkGraph) addInline(from string, blockID string, blocks []*notiontypes.InlineBlock) {
+	for _, b := range blocks {
+		if b.IsPageMention() {
+			g.add(PageLink{From: from, To: b.PageID, Kind: LinkMention, BlockID: blockID})
+		}
+		if id, _, ok := parseNotionLink(b.Link); ok {
+			g.add(PageLink{From: from, To: id, Kind: LinkURL, BlockID: blockID})
+		}
+	}
+}
+
+// add adds a link unless it is a link of a page to itself or was added
+// before.
+func (g *LinkGraph) add(l PageLink) {
+	if l.From == l.To || g.seen[l] {
+		return
+	}
+	g.seen[l] = true
+	g.links = append(g.links, l)
+	g.out[l.From] = append(g.out[l.From], len(g.links)-1)
+	g.in[l.To] = append(g.in[l.To], len(g.links)-1)
+}
+
+func (g *LinkGraph) get(indexes []int) []PageLink {
+	links := make([]PageLink, len(indexes))
+	for i, j := range indexes {
+		links[i] = g.links[j]
+	}
+	return links
+}
+
+// Links returns links from a page to other pages, in the order they appear.
+func (g *LinkGraph) Links(pageID string) []PageLink {
+	return g.get(g.out[pageID])
+}
+
+// Backlinks returns links from other pages to a page.
+func (g *LinkGraph) Backlinks(pageID string) []PageLink {
+	return g.get(g.in[pageID])
+}
+
+// Orphans returns ids of added pages that no other page links to, other
+// than by containing them as a sub-page or database row, sorted by title.
+// These are pages that can only be found by browsing the page hierarchy.
+func (g *LinkGraph) Orphans() []string {
+	var ids []string
+	for id := range g.Titles {
+		linked := false
+		for _, l := range g.Backlinks(id) {
+			if l.Kind != LinkChild && l.Kind != LinkRow {
+				linked = true
+				break
+			}
+		}
+		if !linked {
+			ids = append(ids, id)
+		}
+	}
+	g.sortByTitle(ids)
+	return ids
+}
+
+func (g *LinkGraph) sortByTitle(ids []string) {
+	sort.Slice(ids, func(i, j int) bool {
+		if ti, tj := g.Titles[ids[i]], g.Titles[ids[j]]; ti != tj {
+			return ti < tj
+		}
+		return ids[i] < ids[j]
+	})
+}
+
+// pageIDs returns ids of all pages of the graph, including pages that are
+// linked to but weren't added, sorted by title.
+func (g *LinkGraph) pageIDs() []string {
+	seen := map[string]bool{}
+	var ids []string
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for id := range g.Titles {
+		add(id)
+	}
+	for _, l := range g.links {
+		add(l.From)
+		add(l.To)
+	}
+	g.sortByTitle(ids)
+	return ids
+}
+
+// dotEdgeStyles are the Graphviz attributes of links by kind.
+var dotEdgeStyles = map[string]string{
+	LinkChild:    "",
+	LinkRow:      ` [color="gray"]`,
+	LinkAlias:    ` [style="dashed"]`,
+	LinkMention:  ` [color="blue"]`,
+	LinkURL:      ` [color="blue", style="dashed"]`,
+	LinkRelation: ` [color="darkgreen"]`,
+}
+
+// WriteDOT writes the graph in the DOT language of Graphviz. Pages are
+// labeled with their titles. Pages that are linked to but were not added
+// are drawn dashed, links are styled by kind.
+func (g *LinkGraph) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("digraph notion {\n")
+	sb.WriteString("\tnode [shape=box];\n")
+	for _, id := range g.pageIDs() {
+		title, ok := g.Titles[id]
+		attrs := "label=" + strconv.Quote(title)
+		if !ok {
+			attrs = "label=" + strconv.Quote(id) + `, style="dashed"`
+		}
+		fmt.Fprintf(&sb, "\t%q [%s];\n", id, attrs)
+	}
+	for _, l := range g.links {
+		fmt.Fprintf(&sb, "\t%q -> %q%s;\n", l.From, l.To, dotEdgeStyles[l.Kind])
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteJSON writes the graph as a JSON object with "nodes", the pages with
+// their ids and titles, and "links", the links between them.
+func (g *LinkGraph) WriteJSON(w io.Writer) error {
+	type node struct {
+		ID    string `json:"id"`
+		Title string `json:"title,omitempty"`
+		// Missing is set for pages that are linked to but were not added.
+		Missing bool `json:"missing,omitempty"`
+	}
+	graph := struct {
+		Nodes []node     `json:"nodes"`
+		Links []PageLink `json:"links"`
+	}{Nodes: []node{}, Links: g.links}
+	if graph.Links == nil {
+		graph.Links = []PageLink{}
+	}
+	for _, id := range g.pageIDs() {
+		title, ok := g.Titles[id]
+		graph.Nodes = append(graph.Nodes, node{ID: id, Title: title, Missing: !ok})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(graph)
+}
@@ -0,0 +1,65 @@
+package notion
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLinkGraph(t *testing.T) {
+	c := "cccccccc-cccc-4ccc-8ccc-cccccccccccc"
+	a := testPage(t, `[
+	{"id":"a","type":"page","properties":{"title":[["A"]]},"content":["t","b"]},
+	{"id":"t","type":"text","properties":{"title":[["see "],["‣",[["p","`+c+`"]]]]}},
+	{"id":"b","type":"page","parent_id":"a","properties":{"title":[["B"]]}}
+	]`)
+	b := testPage(t, `[
+	{"id":"b","type":"page","properties":{"title":[["B"]]},"content":["l"]},
+	{"id":"l","type":"text","properties":{"title":[["c",[["a","https://www.notion.so/C-cccccccccccc4ccc8ccccccccccccccc#1234"]]]]}}
+	]`)
+	cp := testPage(t, `[{"id":"`+c+`","type":"page","properties":{"title":[["C"]]}}]`)
+	g := NewLinkGraph()
+	for _, p := range []*Page{a, b, cp} {
+		g.AddPage(p)
+	}
+
+	want := []PageLink{
+		{From: "a", To: c, Kind: LinkMention, BlockID: "t"},
+		{From: "b", To: c, Kind: LinkURL, BlockID: "l"},
+	}
+	if got := g.Backlinks(c); !reflect.DeepEqual(got, want) {
+		t.Errorf("Backlinks() = %+v, want %+v", got, want)
+	}
+	if got := g.Links("a"); len(got) != 2 || got[0].Kind != LinkChild || got[0].To != "b" {
+		t.Errorf("Links(a) = %+v", got)
+	}
+	if got, want := g.Orphans(), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Orphans() = %v, want %v", got, want)
+	}
+
+	var dot bytes.Buffer
+	if err := g.WriteDOT(&dot); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`"a" [label="A"];`, `"a" -> "b";`, `"b" -> "` + c + `" [color="blue", style="dashed"];`} {
+		if !strings.Contains(dot.String(), s) {
+			t.Errorf("DOT lacks %s:\n%s", s, dot.String())
+		}
+	}
+	var js bytes.Buffer
+	if err := g.WriteJSON(&js); err != nil {
+		t.Fatal(err)
+	}
+	var graph struct {
+		Nodes []struct{ ID, Title string }
+		Links []PageLink
+	}
+	if err := json.Unmarshal(js.Bytes(), &graph); err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.Nodes) != 3 || len(graph.Links) != 3 {
+		t.Errorf("got %d nodes and %d links:\n%s", len(graph.Nodes), len(graph.Links), js.String())
+	}
+}
@@ -0,0 +1,376 @@
+package notion
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+type markdownPrinter struct {
+	buf       *bytes.Buffer
+	cfg       *printConfig
+	root      *notiontypes.Block
+	prefix    string
+	footnotes []string
+}
+
+// line writes s prefixed with the current prefix. Each line of a
+// multi-line s is prefixed.
+func (m *markdownPrinter) line(s string) {
+	for _, l := range strings.Split(s, "\n") {
+		m.buf.WriteString(strings.TrimRight(m.prefix+l, " "))
+		m.buf.WriteString("\n")
+	}
+}
+
+// verbatim writes the lines of s prefixed with the current prefix, without
+// trimming trailing spaces, as for code.
+func (m *markdownPrinter) verbatim(s string) {
+	for _, l := range strings.Split(s, "\n") {
+		if l == "" {
+			m.buf.WriteString(strings.TrimRight(m.prefix, " "))
+		} else {
+			m.buf.WriteString(m.prefix + l)
+		}
+		m.buf.WriteString("\n")
+	}
+}
+
+// nested prints blocks with prefix added to the current prefix. Nested
+// lists are kept tight, other content is separated by an empty line.
+func (m *markdownPrinter) nested(prefix string, blocks []*notiontypes.Block) {
+	if len(blocks) == 0 {
+		return
+	}
+	old := m.prefix
+	m.prefix += prefix
+	if !isListItem(blocks[0]) {
+		m.line("")
+	}
+	m.printBlocks(blocks)
+	m.prefix = old
+}
+
+func (m *markdownPrinter) printPage(page *notiontypes.Block) {
+	if m.cfg.noTitle {
+		m.printBlocks(page.Content)
+		m.printFootnotes()
+		return
+	}
+	if cover := m.cfg.coverURL(page); cover != "" {
+		m.line(fmt.Sprintf("![](%s)", cover))
+		m.line("")
+	}
+	title := page.Title
+	if icon := pageIcon(page); icon != "" {
+		if u := m.cfg.iconURL(icon); u != "" {
+			m.line(fmt.Sprintf("![](%s)", u))
+			m.line("")
+		} else {
+			title = icon + " " + title
+		}
+	}
+	m.line("# " + escapeMarkdown(title) + m.footnoteRefs(page))
+	if len(page.Content) > 0 {
+		m.line("")
+		m.printBlocks(page.Content)
+	}
+	m.printFootnotes()
+}
+
+func (m *markdownPrinter) printFootnotes() {
+	if len(m.footnotes) > 0 {
+		m.line("")
+		for i, f := range m.footnotes {
+			m.line(fmt.Sprintf("[^%d]: %s", i+1, f))
+		}
+	}
+}
+
+func (m *markdownPrinter) printBlocks(blocks []*notiontypes.Block) {
+	num := 0
+	for i, b := range blocks {
+		if i > 0 && !(isListItem(b) && blocks[i-1].Type == b.Type) {
+			m.line("")
+		}
+		if b.Type == notiontypes.BlockNumberedList {
+			num++
+		} else {
+			num = 0
+		}
+		m.printBlock(b, num)
+	}
+}
+
+func (m *markdownPrinter) printBlock(b *notiontypes.Block, num int) {
+	text := m.inline(b.InlineContent) + m.footnoteRefs(b)
+	anchor := m.anchor(b)
+	switch b.Type {
+	case notiontypes.BlockPage, notiontypes.BlockAlias:
+		id, title := pageTitle(b)
+		m.line(m.pageLink(id, "", title) + anchor)
+	case notiontypes.BlockHeader, notiontypes.BlockSubHeader, notiontypes.BlockSubSubHeader:
+		m.line(strings.Repeat("#", b.HeaderLevel()+1) + " " + text + anchor)
+		m.nested("", b.Content)
+	case notiontypes.BlockBulletedList, notiontypes.BlockToggle:
+		m.line("- " + text + anchor)
+		m.nested("  ", b.Content)
+	case notiontypes.BlockNumberedList:
+		marker := fmt.Sprintf("%d. ", num)
+		m.line(marker + text + anchor)
+		m.nested(strings.Repeat(" ", len(marker)), b.Content)
+	case notiontypes.BlockTodo:
+		check := " "
+		if b.IsChecked {
+			check = "x"
+		}
+		m.line(fmt.Sprintf("- [%s] %s", check, text) + anchor)
+		m.nested("  ", b.Content)
+	case notiontypes.BlockQuote:
+		m.line("> " + text + anchor)
+		m.nested("> ", b.Content)
+	case notiontypes.BlockCallout:
+		icon := pageIcon(b)
+		if u := m.cfg.iconURL(icon); u != "" {
+			icon = fmt.Sprintf("![](%s)", u)
+		}
+		m.line(strings.TrimSpace("> "+icon+" "+text) + anchor)
+		m.nested("> ", b.Content)
+	case notiontypes.BlockCode:
+		fence := codeFence(b.Code)
+		m.line(fence + strings.ToLower(b.CodeLanguage))
+		m.verbatim(b.Code)
+		m.line(fence)
+		m.separateAnchor(b)
+	case notiontypes.BlockEquation:
+		m.line("$$")
+		m.line(b.Equation)
+		m.line("$$")
+		m.separateAnchor(b)
+	case notiontypes.BlockDivider:
+		m.line("---")
+	case notiontypes.BlockImage:
+		m.line(fmt.Sprintf("![%s](%s)", text, m.cfg.imageURL(b)) + anchor)
+	case notiontypes.BlockBookmark:
+		title := text
+		if title == "" {
+			title = escapeMarkdown(b.Link)
+		}
+		m.line(fmt.Sprintf("[%s](%s)", title, b.Link) + anchor)
+	case notiontypes.BlockFile, notiontypes.BlockPDF, notiontypes.BlockAudio,
+		notiontypes.BlockVideo, notiontypes.BlockEmbed, notiontypes.BlockGist:
+		u := m.cfg.fileURL(b)
+		title := text
+		if title == "" {
+			title = escapeMarkdown(b.Source)
+		}
+		m.line(fmt.Sprintf("[%s](%s)", title, u) + anchor)
+	case notiontypes.BlockTable:
+		m.printTable(b)
+		m.separateAnchor(b)
+	case notiontypes.BlockTableOfContents:
+		for _, h := range headers(m.root) {
+			t := inlineText(h.InlineContent)
+			indent := strings.Repeat("  ", h.HeaderLevel()-1)
+			m.line(fmt.Sprintf("%s- [%s](#%s)", indent, escapeMarkdown(t), Slugify(t)))
+		}
+	case notiontypes.BlockBreadcrumb, notiontypes.BlockCollectionView:
+		// nothing to show
+	case notiontypes.BlockColumnList, notiontypes.BlockColumn,
+		notiontypes.BlockTransclusionContainer, notiontypes.BlockTransclusionReference:
+		m.printBlocks(b.SyncedContent())
+	default:
+		if text != "" {
+			text += anchor
+		}
+		m.line(text)
+		m.nested("", b.Content)
+	}
+}
+
+// anchor returns the Obsidian block id of b to append to its line, if
+// enabled by WithBlockIDs.
+func (m *markdownPrinter) anchor(b *notiontypes.Block) string {
+	if !m.cfg.blockIDs {
+		return ""
+	}
+	return " ^" + b.ID
+}
+
+// separateAnchor writes the block id of a code block, equation or table on
+// a line of its own, as Obsidian requires for blocks spanning lines.
+func (m *markdownPrinter) separateAnchor(b *notiontypes.Block) {
+	if m.cfg.blockIDs {
+		m.line("")
+		m.line("^" + b.ID)
+	}
+}
+
+// pageLink returns a link to a page, or to a block of it if blockID is set,
+// as a wiki link if enabled by WithWikiLinks.
+func (m *markdownPrinter) pageLink(pageID string, blockID string, title string) string {
+	if m.cfg.wikiLink != nil {
+		if target := m.cfg.wikiLink(pageID); target != "" {
+			if blockID != "" {
+				target += "#^" + blockID
+			}
+			title = wikiAliasEscaper.Replace(title)
+			if title == "" || title == path.Base(target) {
+				return "[[" + target + "]]"
+			}
+			return "[[" + target + "|" + title + "]]"
+		}
+	}
+	u := m.cfg.pageURL(pageID)
+	if blockID != "" {
+		u += "#" + strings.Replace(blockID, "-", "", -1)
+	}
+	return fmt.Sprintf("[%s](%s)", escapeMarkdown(title), u)
+}
+
+// wikiAliasEscaper removes characters that end the alias of a wiki link.
+var wikiAliasEscaper = strings.NewReplacer("|", "", "[", "", "]", "")
+
+func (m *markdownPrinter) printTable(b *notiontypes.Block) {
+	var rows [][]string
+	for _, row := range b.Content {
+		var cells []string
+		for _, cell := range row.Cells {
+			cells = append(cells, strings.Replace(m.inline(cell), "|", `\|`, -1))
+		}
+		rows = append(rows, cells)
+	}
+	if len(rows) == 0 {
+		return
+	}
+	header := make([]string, len(rows[0]))
+	if b.FormatTable != nil && b.FormatTable.TableBlockColumnHeader {
+		header, rows = rows[0], rows[1:]
+	}
+	m.line("| " + strings.Join(header, " | ") + " |")
+	m.line(strings.Repeat("| --- ", len(header)) + "|")
+	for _, r := range rows {
+		m.line("| " + strings.Join(r, " | ") + " |")
+	}
+}
+
+// footnoteRefs records discussions of a block as footnotes and returns
+// references to them.
+func (m *markdownPrinter) footnoteRefs(b *notiontypes.Block) string {
+	var refs string
+	for _, d := range b.Discussions {
+		var comments []string
+		for _, c := range d.Comments {
+			comments = append(comments, commentText(c))
+		}
+		if len(comments) == 0 {
+			continue
+		}
+		m.footnotes = append(m.footnotes, strings.Join(comments, "; "))
+		refs += fmt.Sprintf("[^%d]", len(m.footnotes))
+	}
+	return refs
+}
+
+func (m *markdownPrinter) inline(blocks []*notiontypes.InlineBlock) string {
+	var sb strings.Builder
+	for _, b := range blocks {
+		sb.WriteString(m.inlineBlock(b))
+	}
+	return sb.String()
+}
+
+func (m *markdownPrinter) inlineBlock(b *notiontypes.InlineBlock) string {
+	var s string
+	switch {
+	case b.IsEquation():
+		return "$" + b.Equation + "$"
+	case b.IsPageMention():
+		return m.pageLink(b.PageID, "", plainText(b))
+	case b.AttrFlags&notiontypes.AttrCode != 0:
+		s = codeSpan(plainText(b))
+	default:
+		s = escapeMarkdown(plainText(b))
+	}
+	if b.AttrFlags&notiontypes.AttrBold != 0 {
+		s = wrapMarkdown(s, "**")
+	}
+	if b.AttrFlags&notiontypes.AttrItalic != 0 {
+		s = wrapMarkdown(s, "_")
+	}
+	if b.AttrFlags&notiontypes.AttrStrikeThrought != 0 {
+		s = wrapMarkdown(s, "~~")
+	}
+	if b.Link != "" {
+		if pageID, blockID, ok := parseNotionLink(b.Link); ok && m.cfg.wikiLink != nil && m.cfg.wikiLink(pageID) != "" {
+			return m.pageLink(pageID, blockID, plainText(b))
+		}
+		s = fmt.Sprintf("[%s](%s)", s, b.Link)
+	}
+	return s
+}
+
+// wrapMarkdown surrounds s with marker keeping leading and trailing
+// whitespace outside of it, as Markdown requires.
+func wrapMarkdown(s string, marker string) string {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	i := strings.Index(s, trimmed)
+	return s[:i] + marker + trimmed + marker + s[i+len(trimmed):]
+}
+
+// codeSpan returns s as an inline code span, delimited by more backticks
+// than any run of backticks in s. s is padded with spaces if it starts or
+// ends with a backtick, or with spaces that would be stripped.
+func codeSpan(s string) string {
+	fence := strings.Repeat("`", longestBacktickRun(s)+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") ||
+		strings.HasPrefix(s, " ") && strings.HasSuffix(s, " ") && strings.TrimSpace(s) != "" {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+// longestBacktickRun returns the length of the longest run of backticks in
+// s.
+func longestBacktickRun(s string) int {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	return longest
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`<`, `\<`,
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// PrintAsMarkdown renders a notion page as Markdown. Discussions are rendered
+// as footnotes.
+func PrintAsMarkdown(page *notiontypes.Block, opts ...PrintOption) ([]byte, error) {
+	m := &markdownPrinter{buf: new(bytes.Buffer), cfg: newPrintConfig(opts), root: page}
+	m.printPage(page)
+	return m.buf.Bytes(), nil
+}
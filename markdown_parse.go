@@ -0,0 +1,435 @@
+package notion
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+var (
+	mdHeading    = regexp.MustCompile(`^(#{1,6})\s+(.*?)(\s+#+)?\s*$`)
+	mdFence      = regexp.MustCompile("^(```+|~~~+)\\s*([^`\\s]*)")
+	mdRule       = regexp.MustCompile(`^(\*\s*){3,}$|^(-\s*){3,}$|^(_\s*){3,}$`)
+	mdListItem   = regexp.MustCompile(`^([-*+]|\d{1,9}[.)])(\s+|$)`)
+	mdTodo       = regexp.MustCompile(`^\[([ xX])\]\s+`)
+	mdImage      = regexp.MustCompile(`^!\[([^\]]*)\]\(\s*(\S+?)(?:\s+"[^"]*")?\s*\)$`)
+	mdLinkTarget = regexp.MustCompile(`^\(\s*(\S+?)(?:\s+"[^"]*")?\s*\)`)
+)
+
+// codeLanguages maps names of languages in fenced code blocks to names of
+// languages of notion.so code blocks.
+var codeLanguages = map[string]string{
+	"":           "Plain Text",
+	"text":       "Plain Text",
+	"bash":       "Bash",
+	"c":          "C",
+	"c#":         "C#",
+	"c++":        "C++",
+	"cpp":        "C++",
+	"cs":         "C#",
+	"csharp":     "C#",
+	"css":        "CSS",
+	"diff":       "Diff",
+	"docker":     "Docker",
+	"dockerfile": "Docker",
+	"go":         "Go",
+	"golang":     "Go",
+	"graphql":    "GraphQL",
+	"html":       "HTML",
+	"java":       "Java",
+	"javascript": "JavaScript",
+	"js":         "JavaScript",
+	"json":       "JSON",
+	"kotlin":     "Kotlin",
+	"latex":      "LaTeX",
+	"makefile":   "Makefile",
+	"markdown":   "Markdown",
+	"md":         "Markdown",
+	"php":        "PHP",
+	"py":         "Python",
+	"python":     "Python",
+	"rb":         "Ruby",
+	"ruby":       "Ruby",
+	"rs":         "Rust",
+	"rust":       "Rust",
+	"scala":      "Scala",
+	"sh":         "Shell",
+	"shell":      "Shell",
+	"sql":        "SQL",
+	"swift":      "Swift",
+	"toml":       "TOML",
+	"ts":         "TypeScript",
+	"typescript": "TypeScript",
+	"xml":        "XML",
+	"yaml":       "YAML",
+	"yml":        "YAML",
+}
+
+// codeLanguage returns the notion.so name of a language of a fenced code block.
+func codeLanguage(lang string) string {
+	if l, ok := codeLanguages[strings.ToLower(lang)]; ok {
+		return l
+	}
+	return strings.Title(lang)
+}
+
+// ParseMarkdown parses a Markdown document into blocks: headings, paragraphs,
+// bulleted, numbered and to-do lists, fenced and indented code, quotes,
+// images and horizontal rules. Nested list items become children of their
+// parent item. Inline text supports bold, italic, strikethrough, code and
+// links.
+//
+// The blocks have no ids, they can be created with Client.AppendBlocks or
+// Client.CreatePage.
+func ParseMarkdown(src []byte) []*notiontypes.Block {
+	text := strings.Replace(string(src), "\r\n", "\n", -1)
+	text = strings.Replace(text, "\t", "    ", -1)
+	return parseMarkdownLines(strings.Split(text, "\n"))
+}
+
+// indentation returns the number of leading spaces of a line.
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// startsBlock returns true if a line starts a block other than a paragraph.
+func startsBlock(line string) bool {
+	l := strings.TrimSpace(line)
+	return mdHeading.MatchString(l) || mdFence.MatchString(l) || mdRule.MatchString(l) ||
+		strings.HasPrefix(l, ">") || mdListItem.MatchString(l) || mdImage.MatchString(l)
+}
+
+func parseMarkdownLines(lines []string) []*notiontypes.Block {
+	var blocks []*notiontypes.Block
+	add := func(typ string, inline []*notiontypes.InlineBlock) *notiontypes.Block {
+		b := &notiontypes.Block{Type: typ, InlineContent: inline, Alive: true}
+		blocks = append(blocks, b)
+		return b
+	}
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if isBlank(line) {
+			i++
+			continue
+		}
+		if indentation(line) >= 4 {
+			// indented code
+			var code []string
+			for ; i < len(lines) && (isBlank(lines[i]) || indentation(lines[i]) >= 4); i++ {
+				code = append(code, strings.TrimPrefix(lines[i], "    "))
+			}
+			b := add(notiontypes.BlockCode, nil)
+			b.Code = strings.TrimRight(strings.Join(code, "\n"), "\n ")
+			b.CodeLanguage = codeLanguage("")
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if m := mdFence.FindStringSubmatch(trimmed); m != nil {
+			indent := indentation(line)
+			var code []string
+			for i++; i < len(lines); i++ {
+				if closesFence(lines[i], m[1]) {
+					i++
+					break
+				}
+				l := lines[i]
+				if n := indentation(l); n < indent {
+					l = l[n:]
+				} else {
+					l = l[indent:]
+				}
+				code = append(code, l)
+			}
+			b := add(notiontypes.BlockCode, nil)
+			b.Code = strings.Join(code, "\n")
+			b.CodeLanguage = codeLanguage(m[2])
+			continue
+		}
+		if m := mdHeading.FindStringSubmatch(trimmed); m != nil {
+			typ := notiontypes.BlockSubSubHeader
+			switch len(m[1]) {
+			case 1:
+				typ = notiontypes.BlockHeader
+			case 2:
+				typ = notiontypes.BlockSubHeader
+			}
+			add(typ, parseMarkdownInline(m[2]))
+			i++
+			continue
+		}
+		if mdRule.MatchString(trimmed) {
+			add(notiontypes.BlockDivider, nil)
+			i++
+			continue
+		}
+		if m := mdImage.FindStringSubmatch(trimmed); m != nil {
+			b := add(notiontypes.BlockImage, nil)
+			b.Source = m[2]
+			if m[1] != "" {
+				b.Properties = map[string]interface{}{
+					"caption": notiontypes.RawInlineBlocks([]*notiontypes.InlineBlock{{Text: m[1]}}),
+				}
+			}
+			i++
+			continue
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			var quoted []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				l := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quoted = append(quoted, strings.TrimPrefix(l, " "))
+			}
+			add(notiontypes.BlockQuote, parseMarkdownInline(joinParagraphs(quoted)))
+			continue
+		}
+		if m := mdListItem.FindStringSubmatch(trimmed); m != nil {
+			indent := indentation(line)
+			contentIndent := indent + len(m[0])
+			if m[2] == "" {
+				contentIndent++
+			}
+			text := trimmed[len(m[0]):]
+			typ := notiontypes.BlockBulletedList
+			if unicode.IsDigit(rune(m[1][0])) {
+				typ = notiontypes.BlockNumberedList
+			}
+			checked := false
+			if t := mdTodo.FindStringSubmatch(text); t != nil && typ == notiontypes.BlockBulletedList {
+				typ = notiontypes.BlockTodo
+				checked = t[1] != " "
+				text = text[len(t[0]):]
+			}
+			// lazy continuation lines of the item's text
+			i++
+			for ; i < len(lines) && !isBlank(lines[i]) && !startsBlock(lines[i]); i++ {
+				text += " " + strings.TrimSpace(lines[i])
+			}
+			// nested content is indented to the content of the item
+			var nested []string
+			for ; i < len(lines); i++ {
+				l := lines[i]
+				if isBlank(l) {
+					// blank lines belong to the item if indented content follows
+					j := i
+					for j < len(lines) && isBlank(lines[j]) {
+						j++
+					}
+					if j == len(lines) || indentation(lines[j]) < contentIndent {
+						break
+					}
+					nested = append(nested, "")
+					continue
+				}
+				if indentation(l) < contentIndent {
+					break
+				}
+				nested = append(nested, l[contentIndent:])
+			}
+			b := add(typ, parseMarkdownInline(text))
+			b.IsChecked = checked
+			b.Content = parseMarkdownLines(nested)
+			continue
+		}
+		// paragraph
+		var para []string
+		for ; i < len(lines) && !isBlank(lines[i]) && (len(para) == 0 || !startsBlock(lines[i])); i++ {
+			para = append(para, strings.TrimSpace(lines[i]))
+		}
+		add(notiontypes.BlockText, parseMarkdownInline(strings.Join(para, " ")))
+	}
+	return blocks
+}
+
+// joinParagraphs joins lines of paragraphs with spaces, and paragraphs with
+// newlines.
+func joinParagraphs(lines []string) string {
+	var paras []string
+	var cur []string
+	for _, l := range lines {
+		if isBlank(l) {
+			if len(cur) > 0 {
+				paras = append(paras, strings.Join(cur, " "))
+				cur = nil
+			}
+			continue
+		}
+		cur = append(cur, strings.TrimSpace(l))
+	}
+	if len(cur) > 0 {
+		paras = append(paras, strings.Join(cur, " "))
+	}
+	return strings.Join(paras, "\n")
+}
+
+// inlineParser parses Markdown inline text into runs of InlineBlocks.
+type inlineParser struct {
+	flags  notiontypes.AttrFlag
+	link   string
+	result []*notiontypes.InlineBlock
+}
+
+// emit appends text with the current attributes, merging it with the
+// previous run if attributes are the same.
+func (p *inlineParser) emit(text string, flags notiontypes.AttrFlag) {
+	if text == "" {
+		return
+	}
+	if n := len(p.result); n > 0 {
+		last := p.result[n-1]
+		if last.AttrFlags == flags && last.Link == p.link {
+			last.Text += text
+			return
+		}
+	}
+	p.result = append(p.result, &notiontypes.InlineBlock{Text: text, AttrFlags: flags, Link: p.link})
+}
+
+// inlineMarker is a delimiter of emphasis in inline Markdown.
+type inlineMarker struct {
+	marker string
+	flag   notiontypes.AttrFlag
+}
+
+var inlineMarkers = []inlineMarker{
+	{"**", notiontypes.AttrBold},
+	{"__", notiontypes.AttrBold},
+	{"~~", notiontypes.AttrStrikeThrought},
+	{"*", notiontypes.AttrItalic},
+	{"_", notiontypes.AttrItalic},
+}
+
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func (p *inlineParser) parse(s string) {
+	var text strings.Builder
+	flush := func() {
+		p.emit(text.String(), p.flags)
+		text.Reset()
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\\`*_{}[]()#+-.!~<>|", s[i+1]) >= 0:
+			text.WriteByte(s[i+1])
+			i += 2
+			continue
+		case c == '`':
+			n := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+			fence := s[i : i+n]
+			if end := strings.Index(s[i+n:], fence); end >= 0 {
+				flush()
+				code := s[i+n : i+n+end]
+				if strings.HasPrefix(code, " ") && strings.HasSuffix(code, " ") && strings.TrimSpace(code) != "" {
+					code = code[1 : len(code)-1]
+				}
+				p.emit(code, p.flags|notiontypes.AttrCode)
+				i += n + end + n
+				continue
+			}
+			text.WriteString(fence)
+			i += n
+			continue
+		case c == '[' && p.link == "":
+			if end := matchingBracket(s[i:]); end > 0 {
+				if m := mdLinkTarget.FindStringSubmatch(s[i+end+1:]); m != nil {
+					flush()
+					p.link = m[1]
+					p.parse(s[i+1 : i+end])
+					p.link = ""
+					i += end + 1 + len(m[0])
+					continue
+				}
+			}
+		case c == '<':
+			if end := strings.IndexByte(s[i:], '>'); end > 0 {
+				u := s[i+1 : i+end]
+				if (strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://")) && !strings.ContainsAny(u, " <") {
+					flush()
+					p.link = u
+					p.emit(u, p.flags)
+					p.link = ""
+					i += end + 1
+					continue
+				}
+			}
+		case c == '*' || c == '_' || c == '~':
+			if m, ok := p.marker(s, i); ok {
+				flush()
+				p.flags ^= m.flag
+				i += len(m.marker)
+				continue
+			}
+		}
+		text.WriteByte(c)
+		i++
+	}
+	flush()
+}
+
+// marker returns the emphasis marker at s[i] if it opens or closes emphasis.
+// An opening marker must be closed later in s, and underscores only count
+// at word boundaries.
+func (p *inlineParser) marker(s string, i int) (inlineMarker, bool) {
+	for _, m := range inlineMarkers {
+		if !strings.HasPrefix(s[i:], m.marker) {
+			continue
+		}
+		before, _ := utf8.DecodeLastRuneInString(s[:i])
+		after, _ := utf8.DecodeRuneInString(s[i+len(m.marker):])
+		if m.marker[0] == '_' && isWordChar(before) && isWordChar(after) {
+			return m, false
+		}
+		if p.flags&m.flag != 0 {
+			// closing
+			if i > 0 && unicode.IsSpace(before) {
+				return m, false
+			}
+			return m, true
+		}
+		// opening
+		if i+len(m.marker) >= len(s) || unicode.IsSpace(after) {
+			return m, false
+		}
+		if !strings.Contains(s[i+len(m.marker)+1:], m.marker) {
+			return m, false
+		}
+		return m, true
+	}
+	return inlineMarker{}, false
+}
+
+// matchingBracket returns the index of the "]" matching the "[" at s[0], or
+// -1.
+func matchingBracket(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseMarkdownInline parses inline Markdown text into InlineBlocks.
+func parseMarkdownInline(s string) []*notiontypes.InlineBlock {
+	p := &inlineParser{}
+	p.parse(s)
+	return p.result
+}
@@ -0,0 +1,126 @@
+package notion
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	src := "# Title\n" +
+		"\n" +
+		"Some **bold** and *italic* text with `code`, snake_case and a [~~link~~](https://example.com)\n" +
+		"on two lines.\n" +
+		"\n" +
+		"## Section\n" +
+		"\n" +
+		"- one\n" +
+		"- two\n" +
+		"  - nested\n" +
+		"- [ ] todo\n" +
+		"- [x] done\n" +
+		"\n" +
+		"1. first\n" +
+		"2. second\n" +
+		"\n" +
+		"> quoted\n" +
+		"\n" +
+		"```go\n" +
+		"fmt.Println(\"hi\")\n" +
+		"```\n" +
+		"\n" +
+		"![alt](https://example.com/img.png)\n" +
+		"\n" +
+		"---\n"
+	blocks := ParseMarkdown([]byte(src))
+	if got := blocks[len(blocks)-3].CodeLanguage; got != "Go" {
+		t.Errorf("CodeLanguage: %q", got)
+	}
+	// headers are rendered one level lower, below the page title
+	want := "## Title\n" +
+		"\n" +
+		"Some **bold** and _italic_ text with `code`, snake\\_case and a [~~link~~](https://example.com) on two lines.\n" +
+		"\n" +
+		"### Section\n" +
+		"\n" +
+		"- one\n" +
+		"- two\n" +
+		"  - nested\n" +
+		"\n" +
+		"- [ ] todo\n" +
+		"- [x] done\n" +
+		"\n" +
+		"1. first\n" +
+		"2. second\n" +
+		"\n" +
+		"> quoted\n" +
+		"\n" +
+		"```go\n" +
+		"fmt.Println(\"hi\")\n" +
+		"```\n" +
+		"\n" +
+		"![](https://example.com/img.png)\n" +
+		"\n" +
+		"---\n"
+	page := &notiontypes.Block{ID: "p", Type: notiontypes.BlockPage, Content: blocks}
+	got, err := PrintAsMarkdown(page, WithoutTitle())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestParseMarkdownNestedFences(t *testing.T) {
+	src := "````markdown\n" +
+		"```go\n" +
+		"x := 1\n" +
+		"```\n" +
+		"````\n" +
+		"~~~\n" +
+		"```\n" +
+		"~~~~\n" +
+		"\n" +
+		"after\n"
+	blocks := ParseMarkdown([]byte(src))
+	if len(blocks) != 3 {
+		t.Fatalf("got %d blocks, want 3", len(blocks))
+	}
+	if got, want := blocks[0].Code, "```go\nx := 1\n```"; got != want {
+		t.Errorf("first code = %q, want %q", got, want)
+	}
+	if got, want := blocks[1].Code, "```"; got != want {
+		t.Errorf("second code = %q, want %q", got, want)
+	}
+	if blocks[2].Type != notiontypes.BlockText {
+		t.Errorf("last block is %s, want text", blocks[2].Type)
+	}
+}
+
+func TestAppendBlocksChunks(t *testing.T) {
+	c, requests := newTestClient(t, map[string]string{
+		"loadUserContent":   `{"recordMap":{"notion_user":{"u1":{"value":{"id":"u1"}}}}}`,
+		"submitTransaction": `{}`,
+	})
+	var blocks []*notiontypes.Block
+	for i := 0; i < maxTransactionOperations/2+1; i++ {
+		blocks = append(blocks, &notiontypes.Block{Type: notiontypes.BlockText, InlineContent: []*notiontypes.InlineBlock{{Text: "x"}}})
+	}
+	if err := c.AppendBlocks("parent", blocks); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(requests["submitTransaction"]); n != 2 {
+		t.Fatalf("expected 2 transactions, got %d", n)
+	}
+	var tx submitTransactionRequest
+	if err := json.Unmarshal([]byte(requests["submitTransaction"][1]), &tx); err != nil {
+		t.Fatal(err)
+	}
+	last := tx.Operations[len(tx.Operations)-1]
+	args := last.Args.(map[string]interface{})
+	if last.ID != "parent" || args["after"] != blocks[len(blocks)-2].ID {
+		t.Errorf("unexpected last operation: %+v", last)
+	}
+}
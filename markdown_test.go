@@ -0,0 +1,104 @@
+package notion
+
+import "testing"
+
+func TestPrintAsMarkdown(t *testing.T) {
+	page := testPage(t, `[
+	{"id":"p","type":"page","properties":{"title":[["Notes"]]},"content":["h","t","b1","b2","n1","n2","c","sub"]},
+	{"id":"h","type":"header","properties":{"title":[["Intro"]]}},
+	{"id":"t","type":"text","properties":{"title":[["see "],["docs ",[["b"],["a","https://example.com"]]],["and ⁍",[["e","x_1"]]]]}},
+	{"id":"b1","type":"bulleted_list","properties":{"title":[["one"]]},"content":["b1a"]},
+	{"id":"b1a","type":"to_do","properties":{"title":[["nested"]],"checked":[["Yes"]]}},
+	{"id":"b2","type":"bulleted_list","properties":{"title":[["two"]]}},
+	{"id":"n1","type":"numbered_list","properties":{"title":[["first"]]}},
+	{"id":"n2","type":"numbered_list","properties":{"title":[["second"]]}},
+	{"id":"c","type":"code","properties":{"title":[["fmt.Println()"]],"language":[["Go"]]}},
+	{"id":"sub","type":"page","properties":{"title":[["Child"]]}}
+	]`)
+	got, err := PrintAsMarkdown(page.Block, WithPageURLs(func(id string) string { return id + ".md" }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Notes\n" +
+		"\n" +
+		"## Intro\n" +
+		"\n" +
+		"see [**docs** ](https://example.com)$x_1$\n" +
+		"\n" +
+		"- one\n" +
+		"  - [x] nested\n" +
+		"- two\n" +
+		"\n" +
+		"1. first\n" +
+		"2. second\n" +
+		"\n" +
+		"```go\n" +
+		"fmt.Println()\n" +
+		"```\n" +
+		"\n" +
+		"[Child](sub.md)\n"
+	if string(got) != want {
+		t.Errorf("PrintAsMarkdown() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPrintAsMarkdownWikiLinks(t *testing.T) {
+	other := "0b6a8f5e-4c3d-4a2b-9e1f-123456789abc"
+	self := "1c2d3e4f-0000-4000-8000-000000000001"
+	header := "1c2d3e4f-0000-4000-8000-000000000002"
+	page := testPage(t, `[
+	{"id":"`+self+`","type":"page","properties":{"title":[["Notes"]]},"content":["`+header+`","t","l","c","sub"]},
+	{"id":"`+header+`","type":"header","properties":{"title":[["Setup"]]}},
+	{"id":"t","type":"text","properties":{"title":[["see "],["‣",[["p","`+other+`"]]],[" and "],["this",[["a","/0b6a8f5e4c3d4a2b9e1f123456789abc#aaaaaaaabbbbccccddddeeeeeeeeeeee"]]]]}},
+	{"id":"l","type":"bulleted_list","properties":{"title":[["item "],["setup",[["a","/1c2d3e4f000040008000000000000001#1c2d3e4f000040008000000000000002"]]]]}},
+	{"id":"c","type":"code","properties":{"title":[["x"]],"language":[["Go"]]}},
+	{"id":"sub","type":"page","properties":{"title":[["Child"]]}}
+	]`)
+	paths := map[string]string{self: "Notes", other: "Notes/Other", "sub": "Notes/Child"}
+	got, err := PrintAsMarkdown(page.Block, WithoutTitle(), WithBlockIDs(), WithWikiLinks(func(id string) string { return paths[id] }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "## Setup ^" + header + "\n" +
+		"\n" +
+		"see [[Notes/Other|" + other + "]] and [[Notes/Other#^aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee|this]] ^t\n" +
+		"\n" +
+		"- item [[Notes#^" + header + "|setup]] ^l\n" +
+		"\n" +
+		"```go\n" +
+		"x\n" +
+		"```\n" +
+		"\n" +
+		"^c\n" +
+		"\n" +
+		"[[Notes/Child]] ^sub\n"
+	if string(got) != want {
+		t.Errorf("PrintAsMarkdown() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPrintAsMarkdownCode(t *testing.T) {
+	page := testPage(t, `[
+	{"id":"p","type":"page","properties":{"title":[["Code"]]},"content":["l","t"]},
+	{"id":"l","type":"bulleted_list","properties":{"title":[["item"]]},"content":["c"]},
+	{"id":"c","type":"code","properties":{"title":[["`+"```"+`md\nx  \n\n`+"```"+`"]],"language":[["Markdown"]]}},
+	{"id":"t","type":"text","properties":{"title":[["a`+"`"+`b",[["c"]]],[" and "],["`+"`"+`x`+"`"+`",[["c"]]]]}}
+	]`)
+	got, err := PrintAsMarkdown(page.Block, WithoutTitle())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "- item\n" +
+		"\n" +
+		"  ````markdown\n" +
+		"  ```md\n" +
+		"  x  \n" +
+		"\n" +
+		"  ```\n" +
+		"  ````\n" +
+		"\n" +
+		"``a`b`` and `` `x` ``\n"
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,375 @@
+package notion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// MirrorStateName is the file in a mirror that records the mirrored pages
+// and the versions of their blocks.
+const MirrorStateName = ".notion-mirror.json"
+
+// mirrorState records the mirrored pages, to tell which blocks changed.
+type mirrorState struct {
+	Pages map[string]*mirrorPage `json:"pages"`
+}
+
+type mirrorPage struct {
+	Title string `json:"title"`
+	// Path of the Markdown file, the JSON file has the same path with
+	// extension .json.
+	Path string `json:"path"`
+	// Versions of the blocks of the page, by id.
+	Versions map[string]int64 `json:"versions"`
+}
+
+// mirrorEditor is an author of changes of a sync.
+type mirrorEditor struct {
+	name, email string
+	edited      int64
+}
+
+// GitMirror mirrors page trees into a git working tree, as Markdown and raw
+// JSON files that follow the page hierarchy: the sub-pages of "a.md" are in
+// the directory "a". Links between mirrored pages are relative links to
+// their Markdown files.
+//
+// Each sync is a commit of the changed files only. The editors of the blocks
+// that changed are its authors: the one who edited last is the author and
+// the others are co-authors, and the time of the last edit is the time of
+// the commit.
+type GitMirror struct {
+	// Dir is the git working tree. It is created and initialized if needed.
+	Dir string
+
+	// paths of Markdown files by page id, relative to Dir
+	paths map[string]string
+}
+
+// NewGitMirror initializes a new GitMirror writing to the working tree dir.
+func NewGitMirror(dir string) *GitMirror {
+	return &GitMirror{Dir: dir}
+}
+
+// Sync writes the pages of a tree, removes files of pages that were removed
+// or moved since the last sync and commits the changes. Other files of the
+// working tree are left alone. Sync returns a summary of the changes, one
+// per page, and nothing if no file changed.
+func (m *GitMirror) Sync(tree *PageTree) ([]string, error) {
+	if err := os.MkdirAll(m.Dir, 0755); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(m.Dir, ".git")); os.IsNotExist(err) {
+		if _, err := m.git(nil, "init", "-q"); err != nil {
+			return nil, err
+		}
+	}
+	prev := &mirrorState{Pages: map[string]*mirrorPage{}}
+	if b, err := ioutil.ReadFile(filepath.Join(m.Dir, MirrorStateName)); err == nil {
+		if err := json.Unmarshal(b, prev); err != nil {
+			return nil, errors.Wrapf(err, "reading %s", MirrorStateName)
+		}
+	}
+
+	m.paths = map[string]string{}
+	m.assignPaths(tree)
+	assigned := map[string]bool{}
+	for _, p := range m.paths {
+		assigned[p] = true
+	}
+
+	// files of removed and moved pages are removed first, unless another
+	// page now has their path, so that they don't remove new files
+	var changes, removed []string
+	var ids []string
+	for id := range prev.Pages {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		old := prev.Pages[id]
+		p, ok := m.paths[id]
+		if ok && p == old.Path {
+			continue
+		}
+		if !ok {
+			changes = append(changes, "Remove "+old.Path)
+		}
+		if !assigned[old.Path] {
+			removed = append(removed, m.remove(old.Path)...)
+		}
+	}
+
+	state := &mirrorState{Pages: map[string]*mirrorPage{}}
+	editors := map[string]*mirrorEditor{}
+	var written []string
+	for _, t := range tree.Pages() {
+		id := t.Page.ID
+		p := &mirrorPage{Title: t.Page.Title, Path: m.paths[id], Versions: map[string]int64{}}
+		state.Pages[id] = p
+		old := prev.Pages[id]
+		var changed []*notiontypes.Block
+		notiontypes.InspectSynced(t.Page.Block, func(b *notiontypes.Block) bool {
+			if b != t.Page.Block && b.IsPage() {
+				return false
+			}
+			p.Versions[b.ID] = b.Version
+			if old == nil || old.Versions[b.ID] != b.Version {
+				changed = append(changed, b)
+			}
+			return true
+		})
+		// pages are written even if their blocks didn't change, links to
+		// pages that moved change
+		files, err := m.writePage(t)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case old == nil:
+			changes = append(changes, "Add "+p.Path)
+		case old.Path != p.Path:
+			changes = append(changes, "Move "+old.Path+" to "+p.Path)
+		case len(files) > 0:
+			changes = append(changes, "Update "+p.Path)
+		}
+		if len(files) > 0 {
+			written = append(written, files...)
+			for _, b := range changed {
+				addMirrorEditor(editors, b)
+			}
+		}
+	}
+	if len(written) == 0 && len(removed) == 0 {
+		return nil, nil
+	}
+	b, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if changed, err := m.writeFile(MirrorStateName, append(b, '\n')); err != nil {
+		return nil, err
+	} else if changed {
+		written = append(written, MirrorStateName)
+	}
+	if err := m.commit(written, removed, changes, editors); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// addMirrorEditor records the editor of a changed block.
+func addMirrorEditor(editors map[string]*mirrorEditor, b *notiontypes.Block) {
+	if b.LastEditedBy == "" {
+		return
+	}
+	e := editors[b.LastEditedBy]
+	if e == nil {
+		e = &mirrorEditor{name: b.LastEditedBy}
+		if u := b.LastEditedByUser; u != nil {
+			e.name, e.email = u.Name(), u.Email
+		}
+		editors[b.LastEditedBy] = e
+	}
+	if b.LastEditedTime > e.edited {
+		e.edited = b.LastEditedTime
+	}
+}
+
+// assignPaths assigns paths to the Markdown files of the pages of a tree.
+// Sub-pages whose titles have the same slug get numbered paths.
+func (m *GitMirror) assignPaths(t *PageTree) {
+	if t.Parent == nil {
+		m.paths[t.Page.ID] = mirrorSlug(t.Page) + ".md"
+	}
+	dir := strings.TrimSuffix(m.paths[t.Page.ID], ".md") + "/"
+	used := map[string]bool{}
+	for _, c := range t.Children {
+		slug := mirrorSlug(c.Page)
+		unique := slug
+		for i := 2; used[unique]; i++ {
+			unique = fmt.Sprintf("%s-%d", slug, i)
+		}
+		used[unique] = true
+		m.paths[c.Page.ID] = dir + unique + ".md"
+		m.assignPaths(c)
+	}
+}
+
+func mirrorSlug(page *Page) string {
+	if slug := Slugify(page.Title); slug != "" {
+		return slug
+	}
+	return strings.Replace(page.ID, "-", "", -1)
+}
+
+// link returns the path of the Markdown file of a mirrored page relative to
+// the directory of the file at from, or the notion.so url of other pages.
+func (m *GitMirror) link(from string, pageID string) string {
+	p, ok := m.paths[pageID]
+	if !ok {
+		return PageURL(pageID)
+	}
+	rel, err := filepath.Rel(filepath.FromSlash(path.Dir(from)), filepath.FromSlash(p))
+	if err != nil {
+		return p
+	}
+	return filepath.ToSlash(rel)
+}
+
+// writePage writes the Markdown and JSON files of a page and returns the
+// paths of the files that changed.
+func (m *GitMirror) writePage(t *PageTree) ([]string, error) {
+	p := m.paths[t.Page.ID]
+	md, err := PrintAsMarkdown(t.Page.Block, WithPageURLs(func(id string) string { return m.link(p, id) }))
+	if err != nil {
+		return nil, err
+	}
+	js, err := json.MarshalIndent(t.Page.Block, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range []struct {
+		path    string
+		content []byte
+	}{
+		{p, md},
+		{strings.TrimSuffix(p, ".md") + ".json", append(js, '\n')},
+	} {
+		changed, err := m.writeFile(f.path, f.content)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			files = append(files, f.path)
+		}
+	}
+	return files, nil
+}
+
+// writeFile writes a file of the mirror unless it already has the content,
+// and returns whether it was written.
+func (m *GitMirror) writeFile(p string, content []byte) (bool, error) {
+	dst := filepath.Join(m.Dir, filepath.FromSlash(p))
+	if old, err := ioutil.ReadFile(dst); err == nil && bytes.Equal(old, content) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return false, err
+	}
+	return true, ioutil.WriteFile(dst, content, 0644)
+}
+
+// remove removes the files of a page and directories that became empty, and
+// returns the paths of the removed files.
+func (m *GitMirror) remove(p string) []string {
+	var removed []string
+	for _, f := range []string{p, strings.TrimSuffix(p, ".md") + ".json"} {
+		if os.Remove(filepath.Join(m.Dir, filepath.FromSlash(f))) == nil {
+			removed = append(removed, f)
+		}
+	}
+	root := filepath.Clean(m.Dir)
+	for dir := filepath.Dir(filepath.Join(root, filepath.FromSlash(p))); dir != root; dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return removed
+}
+
+// commit commits the written and removed files.
+func (m *GitMirror) commit(written, removed []string, changes []string, editors map[string]*mirrorEditor) error {
+	paths := written
+	if len(written) > 0 {
+		if _, err := m.git(nil, append([]string{"add", "--"}, written...)...); err != nil {
+			return err
+		}
+	}
+	if len(removed) > 0 {
+		// only files that were committed can be committed as removed
+		out, err := m.git(nil, append([]string{"ls-tree", "-r", "-z", "--name-only", "HEAD", "--"}, removed...)...)
+		if err == nil {
+			for _, p := range strings.Split(string(out), "\x00") {
+				if p != "" {
+					paths = append(paths, p)
+				}
+			}
+		}
+		if _, err := m.git(nil, append([]string{"rm", "-q", "--cached", "--ignore-unmatch", "--"}, removed...)...); err != nil {
+			return err
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	var list []*mirrorEditor
+	for _, e := range editors {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].edited != list[j].edited {
+			return list[i].edited > list[j].edited
+		}
+		return list[i].name < list[j].name
+	})
+	var msg strings.Builder
+	switch len(changes) {
+	case 0:
+		msg.WriteString("Sync from Notion\n")
+	case 1:
+		msg.WriteString(changes[0] + "\n")
+	default:
+		fmt.Fprintf(&msg, "Sync %d pages from Notion\n\n", len(changes))
+		for _, c := range changes {
+			msg.WriteString(c + "\n")
+		}
+	}
+	var env []string
+	if len(list) > 0 {
+		author := list[0]
+		date := fmt.Sprintf("%d +0000", author.edited/1000)
+		env = []string{
+			"GIT_AUTHOR_NAME=" + author.name,
+			"GIT_AUTHOR_EMAIL=" + author.email,
+			"GIT_AUTHOR_DATE=" + date,
+			"GIT_COMMITTER_DATE=" + date,
+		}
+		if len(list) > 1 {
+			msg.WriteString("\n")
+			for _, e := range list[1:] {
+				fmt.Fprintf(&msg, "Co-authored-by: %s <%s>\n", e.name, e.email)
+			}
+		}
+	}
+	// only the mirrored files are committed, not other changes of the index
+	args := append([]string{"commit", "-q", "-m", msg.String(), "--"}, paths...)
+	_, err := m.git(env, args...)
+	return err
+}
+
+func (m *GitMirror) git(env []string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = m.Dir
+	cmd.Env = append(os.Environ(), env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
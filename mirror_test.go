@@ -0,0 +1,163 @@
+package notion
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// mirrorTree returns a tree of a home page with two sub-pages titled "Foo",
+// the second of which is linked from the sub-page "Other". The first "Foo"
+// is left out if withFirst is false.
+func mirrorTree(t *testing.T, withFirst bool) *PageTree {
+	t.Helper()
+	content, version := `"a","b","o"`, "1"
+	if !withFirst {
+		content, version = `"b","o"`, "2"
+	}
+	users := map[string]*notiontypes.User{
+		"u1": {ID: "u1", GivenName: "Ann", Email: "ann@example.com"},
+		"u2": {ID: "u2", GivenName: "Bo", Email: "bo@example.com"},
+	}
+	page := func(blocks string) *Page {
+		p := testPage(t, blocks)
+		notiontypes.ResolveUsers(p.Block, users)
+		return p
+	}
+	root := &PageTree{Page: page(`[
+	{"id":"r","type":"page","version":` + version + `,"last_edited_by":"u1","last_edited_time":1600000000000,"properties":{"title":[["Home"]]},"content":[` + content + `]},
+	{"id":"a","type":"page","parent_id":"r","properties":{"title":[["Foo"]]}},
+	{"id":"b","type":"page","parent_id":"r","properties":{"title":[["Foo"]]}},
+	{"id":"o","type":"page","parent_id":"r","properties":{"title":[["Other"]]}}
+	]`)}
+	if withFirst {
+		root.Children = append(root.Children, &PageTree{Page: page(`[
+		{"id":"a","type":"page","version":1,"properties":{"title":[["Foo"]]}}
+		]`), Parent: root})
+	}
+	root.Children = append(root.Children, &PageTree{Page: page(`[
+	{"id":"b","type":"page","version":1,"properties":{"title":[["Foo"]]},"content":["bt"]},
+	{"id":"bt","type":"text","version":3,"last_edited_by":"u2","last_edited_time":1600000100000,"properties":{"title":[["second"]]}}
+	]`), Parent: root}, &PageTree{Page: page(`[
+	{"id":"o","type":"page","version":1,"properties":{"title":[["Other"]]},"content":["ot"]},
+	{"id":"ot","type":"text","version":1,"properties":{"title":[["see "],["‣",[["p","b"]]]]}}
+	]`), Parent: root})
+	return root
+}
+
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git %s: %v", strings.Join(args, " "), err)
+	}
+	return string(out)
+}
+
+func TestGitMirrorAssignPaths(t *testing.T) {
+	m := NewGitMirror(t.TempDir())
+	m.paths = map[string]string{}
+	m.assignPaths(mirrorTree(t, true))
+	want := map[string]string{
+		"r": "home.md",
+		"a": "home/foo.md",
+		"b": "home/foo-2.md",
+		"o": "home/other.md",
+	}
+	if !reflect.DeepEqual(m.paths, want) {
+		t.Errorf("paths = %v, want %v", m.paths, want)
+	}
+}
+
+func TestGitMirrorSync(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "mirror")
+	t.Setenv("GIT_AUTHOR_EMAIL", "mirror@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "mirror")
+	t.Setenv("GIT_COMMITTER_EMAIL", "mirror@example.com")
+
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("mine"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := NewGitMirror(dir)
+	changes, err := m.Sync(mirrorTree(t, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 4 {
+		t.Errorf("changes = %q", changes)
+	}
+	files := "home.json\nhome.md\nhome/foo-2.json\nhome/foo-2.md\nhome/foo.json\nhome/foo.md\nhome/other.json\nhome/other.md\n"
+	if got := gitOutput(t, dir, "ls-tree", "-r", "--name-only", "HEAD"); got != MirrorStateName+"\n"+files {
+		t.Errorf("committed files:\n%s", got)
+	}
+	if got, want := gitOutput(t, dir, "log", "-1", "--format=%an <%ae> %at%n%(trailers)"), "Bo <bo@example.com> 1600000100\nCo-authored-by: Ann <ann@example.com>\n\n"; got != want {
+		t.Errorf("commit = %q, want %q", got, want)
+	}
+	if b, _ := ioutil.ReadFile(filepath.Join(dir, "home/other.md")); !strings.Contains(string(b), "(foo-2.md)") {
+		t.Errorf("home/other.md doesn't link to foo-2.md:\n%s", b)
+	}
+
+	// nothing changed
+	changes, err = m.Sync(mirrorTree(t, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes != nil {
+		t.Errorf("changes = %q, want none", changes)
+	}
+	if got := gitOutput(t, dir, "rev-list", "--count", "HEAD"); got != "1\n" {
+		t.Errorf("%s commits, want 1", strings.TrimSpace(got))
+	}
+
+	// the second "Foo" takes the path of the removed first one, and the
+	// unchanged "Other" links to its new path; a staged file of the user is
+	// not committed
+	if err := ioutil.WriteFile(filepath.Join(dir, "staged.txt"), []byte("mine"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitOutput(t, dir, "add", "staged.txt")
+	changes, err = m.Sync(mirrorTree(t, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"Remove home/foo.md",
+		"Update home.md",
+		"Move home/foo-2.md to home/foo.md",
+		"Update home/other.md",
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %q, want %q", changes, want)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "home/foo.md"))
+	if err != nil || !strings.Contains(string(b), "second") {
+		t.Errorf("home/foo.md = %q, %v", b, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "home/foo-2.md")); !os.IsNotExist(err) {
+		t.Errorf("home/foo-2.md not removed: %v", err)
+	}
+	if b, _ := ioutil.ReadFile(filepath.Join(dir, "home/other.md")); !strings.Contains(string(b), "(foo.md)") {
+		t.Errorf("home/other.md doesn't link to foo.md:\n%s", b)
+	}
+	files = "home.json\nhome.md\nhome/foo.json\nhome/foo.md\nhome/other.json\nhome/other.md\n"
+	if got := gitOutput(t, dir, "ls-tree", "-r", "--name-only", "HEAD"); got != MirrorStateName+"\n"+files {
+		t.Errorf("committed files:\n%s", got)
+	}
+	if got := gitOutput(t, dir, "status", "--porcelain"); got != "A  staged.txt\n?? notes.txt\n" {
+		t.Errorf("status:\n%s", got)
+	}
+}
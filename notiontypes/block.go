@@ -83,6 +83,11 @@ type Block struct {
 	Content []*Block `json:"content_resolved,omitempty"`
 	// this is for some types like TypePage, TypeText, TypeHeader etc.
 	InlineContent []*InlineBlock `json:"inline_content,omitempty"`
+	// maps DiscussionIDs array, set by ResolveDiscussions
+	Discussions []*Discussion `json:"discussions_resolved,omitempty"`
+	// maps CreatedBy and LastEditedBy, set by ResolveUsers
+	CreatedByUser    *User `json:"created_by_user,omitempty"`
+	LastEditedByUser *User `json:"last_edited_by_user,omitempty"`
 
 	// for BlockPage
 	Title string `json:"title,omitempty"`
@@ -111,6 +116,16 @@ type Block struct {
 	Code         string `json:"code,omitempty"`
 	CodeLanguage string `json:"code_language,omitempty"`
 
+	// for BlockEquation, the TeX source of the equation
+	Equation string `json:"equation,omitempty"`
+
+	// for BlockTableRow, cell contents in the column order of the parent table
+	Cells [][]*InlineBlock `json:"cells,omitempty"`
+
+	// for BlockAlias and BlockTransclusionReference, the block that is
+	// pointed to (if it was part of the loaded record map)
+	Reference *Block `json:"-"`
+
 	// for BlockCollectionView
 	// It looks like the info about which view is selected is stored in browser
 	CollectionViews []*CollectionViewInfo `json:"collection_views,omitempty"`
@@ -122,6 +137,11 @@ type Block struct {
 	FormatText     *FormatText     `json:"format_text,omitempty"`
 	FormatTable    *FormatTable    `json:"format_table,omitempty"`
 	FormatVideo    *FormatVideo    `json:"format_video,omitempty"`
+	FormatCallout  *FormatCallout  `json:"format_callout,omitempty"`
+	FormatEmbed    *FormatEmbed    `json:"format_embed,omitempty"`
+	FormatAlias    *FormatAlias    `json:"format_alias,omitempty"`
+
+	FormatTransclusion *FormatTransclusion `json:"format_transclusion,omitempty"`
 }
 
 // CollectionViewInfo describes a particular view of the collection
@@ -166,6 +186,57 @@ func (b *Block) IsCode() bool {
 	return b.Type == BlockCode
 }
 
+// IsHeader returns true if block is a header of any level
+func (b *Block) IsHeader() bool {
+	return b.HeaderLevel() > 0
+}
+
+// HeaderLevel returns 1, 2 or 3 for BlockHeader, BlockSubHeader and
+// BlockSubSubHeader and 0 for all other blocks
+func (b *Block) HeaderLevel() int {
+	switch b.Type {
+	case BlockHeader:
+		return 1
+	case BlockSubHeader:
+		return 2
+	case BlockSubSubHeader:
+		return 3
+	}
+	return 0
+}
+
+// IsToggleable returns true if block content can be collapsed, i.e. it's
+// a BlockToggle or a toggleable header
+func (b *Block) IsToggleable() bool {
+	if b.Type == BlockToggle {
+		return true
+	}
+	return b.IsHeader() && b.FormatText != nil && b.FormatText.Toggleable
+}
+
+// IsEmbed returns true if block embeds external content displayed
+// in a frame (BlockEmbed, BlockAudio, BlockPDF, BlockVideo)
+func (b *Block) IsEmbed() bool {
+	switch b.Type {
+	case BlockEmbed, BlockAudio, BlockPDF, BlockVideo:
+		return true
+	}
+	return false
+}
+
+// SyncedContent returns the content of a synced block. For
+// BlockTransclusionReference it's the content of the referenced
+// BlockTransclusionContainer, for all other blocks it's Content.
+func (b *Block) SyncedContent() []*Block {
+	if b.Type == BlockTransclusionReference {
+		if b.Reference == nil {
+			return nil
+		}
+		return b.Reference.Content
+	}
+	return b.Content
+}
+
 // FormatPage describes format for TypePage
 type FormatPage struct {
 	// /images/page-cover/gradients_11.jpg
@@ -213,16 +284,59 @@ type FormatVideo struct {
 	BlockPreserveScale bool    `json:"block_preserve_scale"`
 }
 
-// FormatText describes format for TypeText
+// FormatText describes format for TypeText, headers and list items
 // TODO: possibly more?
 type FormatText struct {
 	BlockColor *string `json:"block_color,omitempty"`
+	// for headers, true if the header can be collapsed like a toggle
+	Toggleable bool `json:"toggleable,omitempty"`
 }
 
 // FormatTable describes format for TypeTable
 type FormatTable struct {
 	TableWrap       bool             `json:"table_wrap"`
 	TableProperties []*TableProperty `json:"table_properties"`
+
+	// for simple tables, the ids of columns in display order.
+	// Cells of BlockTableRow are keyed by those ids
+	TableBlockColumnOrder  []string `json:"table_block_column_order,omitempty"`
+	TableBlockColumnHeader bool     `json:"table_block_column_header,omitempty"`
+	TableBlockRowHeader    bool     `json:"table_block_row_header,omitempty"`
+}
+
+// FormatCallout describes format for BlockCallout
+type FormatCallout struct {
+	// emoji or url, like FormatPage.PageIcon
+	PageIcon   string  `json:"page_icon"`
+	BlockColor *string `json:"block_color,omitempty"`
+}
+
+// FormatEmbed describes format for BlockEmbed, BlockAudio and BlockPDF
+type FormatEmbed struct {
+	BlockWidth         int64   `json:"block_width"`
+	BlockHeight        int64   `json:"block_height"`
+	DisplaySource      string  `json:"display_source"`
+	BlockFullWidth     bool    `json:"block_full_width"`
+	BlockPageWidth     bool    `json:"block_page_width"`
+	BlockAspectRatio   float64 `json:"block_aspect_ratio"`
+	BlockPreserveScale bool    `json:"block_preserve_scale"`
+}
+
+// Pointer identifies a record in a given table
+type Pointer struct {
+	ID      string `json:"id"`
+	Table   string `json:"table"`
+	SpaceID string `json:"spaceId,omitempty"`
+}
+
+// FormatAlias describes format for BlockAlias
+type FormatAlias struct {
+	AliasPointer *Pointer `json:"alias_pointer"`
+}
+
+// FormatTransclusion describes format for BlockTransclusionReference
+type FormatTransclusion struct {
+	TransclusionReferencePointer *Pointer `json:"transclusion_reference_pointer"`
 }
 
 // TableProperty describes property of a table
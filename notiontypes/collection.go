@@ -0,0 +1,186 @@
+package notiontypes
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Title returns the name of the collection as plain text.
+func (c *Collection) Title() string {
+	var sb strings.Builder
+	for _, part := range c.Name {
+		if len(part) > 0 {
+			sb.WriteString(part[0])
+		}
+	}
+	return sb.String()
+}
+
+// Property is a typed value of a property of a collection row.
+//
+// Value depends on Type:
+//
+//	title, text, url, email, phone_number, select: string
+//	number: float64
+//	checkbox: bool
+//	multi_select: []string
+//	date: *Date
+//	person: []string with user ids
+//	relation: []string with page ids
+//	file: []string with urls
+//	created_time, last_edited_time: time.Time
+//	created_by, last_edited_by: string with user id
+//
+// Value is nil if the property is not set, except for checkboxes, which are
+// false. Values of formula and rollup
+// properties are computed by notion.so and are not available.
+type Property struct {
+	ID    string
+	Name  string
+	Type  string
+	Value interface{}
+}
+
+// PropertyIDs returns ids of properties of the collection, starting with the
+// title, in the order they are displayed on row pages.
+func (c *Collection) PropertyIDs() []string {
+	var ids []string
+	seen := map[string]bool{}
+	add := func(id string) {
+		if _, ok := c.CollectionSchema[id]; ok && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	add("title")
+	if c.Format != nil {
+		for _, p := range c.Format.CollectionPageProperties {
+			add(p.Property)
+		}
+	}
+	var rest []string
+	for id := range c.CollectionSchema {
+		if !seen[id] {
+			rest = append(rest, id)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool {
+		return c.CollectionSchema[rest[i]].Name < c.CollectionSchema[rest[j]].Name
+	})
+	for _, id := range rest {
+		add(id)
+	}
+	return ids
+}
+
+// RowProperties returns typed properties of a row of the collection, in the
+// order of PropertyIDs.
+func (c *Collection) RowProperties(row *Block) []*Property {
+	var props []*Property
+	for _, id := range c.PropertyIDs() {
+		col := c.CollectionSchema[id]
+		props = append(props, &Property{
+			ID:    id,
+			Name:  col.Name,
+			Type:  col.Type,
+			Value: PropertyValue(row, id, col.Type),
+		})
+	}
+	return props
+}
+
+// PropertyValue returns the typed value of property id of a collection row,
+// given the type of the column. See Property for types of values.
+func PropertyValue(row *Block, id string, columnType string) interface{} {
+	switch columnType {
+	case ColumnTypeCreatedTime:
+		return row.CreatedOn()
+	case ColumnTypeLastEditedTime:
+		return row.UpdatedOn()
+	case ColumnTypeCreatedBy:
+		return row.CreatedBy
+	case ColumnTypeLastEditedBy:
+		return row.LastEditedBy
+	}
+	var inline []*InlineBlock
+	if raw, ok := row.Properties[id]; ok {
+		inline, _ = parseInlineBlocks(raw)
+	}
+	if len(inline) == 0 {
+		// unchecked checkboxes have no property
+		if columnType == ColumnTypeCheckbox {
+			return false
+		}
+		return nil
+	}
+	var text strings.Builder
+	for _, b := range inline {
+		text.WriteString(b.Text)
+	}
+	switch columnType {
+	case ColumnTypeNumber:
+		f, err := strconv.ParseFloat(strings.TrimSpace(text.String()), 64)
+		if err != nil {
+			return nil
+		}
+		return f
+	case ColumnTypeCheckbox:
+		return strings.EqualFold(text.String(), "Yes")
+	case ColumnMultiSelect:
+		var values []string
+		for _, v := range strings.Split(text.String(), ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		return values
+	case ColumnTypeDate:
+		for _, b := range inline {
+			if b.Date != nil {
+				return b.Date
+			}
+		}
+		return nil
+	case ColumnTypePerson, ColumnTypeRelation:
+		var ids []string
+		for _, b := range inline {
+			if b.UserID != "" {
+				ids = append(ids, b.UserID)
+			} else if b.PageID != "" {
+				ids = append(ids, b.PageID)
+			}
+		}
+		return ids
+	case ColumnTypeFile:
+		var urls []string
+		for _, b := range inline {
+			switch {
+			case b.Link != "":
+				urls = append(urls, b.Link)
+			case strings.TrimSpace(b.Text) != "" && b.Text != ",":
+				urls = append(urls, b.Text)
+			}
+		}
+		return urls
+	case ColumnTypeFormula, ColumnTypeRollup:
+		return nil
+	}
+	return text.String()
+}
+
+// ViewPropertyIDs returns ids of properties displayed by a table view of the
+// collection, in the order of the view. Hidden properties are skipped. If the
+// view doesn't specify properties, PropertyIDs is returned.
+func (c *Collection) ViewPropertyIDs(view *CollectionView) []string {
+	if view == nil || view.Format == nil || len(view.Format.TableProperties) == 0 {
+		return c.PropertyIDs()
+	}
+	var ids []string
+	for _, p := range view.Format.TableProperties {
+		if _, ok := c.CollectionSchema[p.Property]; ok && p.Visible {
+			ids = append(ids, p.Property)
+		}
+	}
+	return ids
+}
@@ -58,6 +58,8 @@ const (
 	BlockHeader = "header"
 	// BlockSubHeader is a header block
 	BlockSubHeader = "sub_header"
+	// BlockSubSubHeader is a third-level header block
+	BlockSubSubHeader = "sub_sub_header"
 	// BlockQuote is a quote block
 	BlockQuote = "quote"
 	// BlockComment is a comment block
@@ -73,20 +75,59 @@ const (
 	BlockTable = "table"
 	// BlockCollectionView is a collection view block
 	BlockCollectionView = "collection_view"
+	// BlockCollectionViewPage is a page that is a collection view (full page database)
+	BlockCollectionViewPage = "collection_view_page"
 	// BlockVideo is youtube video embed
 	BlockVideo = "video"
 	// BlockFile is an embedded file
 	BlockFile = "file"
+	// BlockCallout is a callout block with an icon
+	BlockCallout = "callout"
+	// BlockEquation is a block-level TeX equation
+	BlockEquation = "equation"
+	// BlockEmbed is a generic embed (iframe)
+	BlockEmbed = "embed"
+	// BlockAudio is an embedded audio file
+	BlockAudio = "audio"
+	// BlockPDF is an embedded pdf file
+	BlockPDF = "pdf"
+	// BlockBreadcrumb shows the path to the current page
+	BlockBreadcrumb = "breadcrumb"
+	// BlockTableOfContents is a generated table of contents
+	BlockTableOfContents = "table_of_contents"
+	// BlockAlias is a link to an existing page
+	BlockAlias = "alias"
+	// BlockTransclusionContainer is the original of a synced block
+	BlockTransclusionContainer = "transclusion_container"
+	// BlockTransclusionReference is a copy of a synced block. Its content
+	// lives in the BlockTransclusionContainer it points to
+	BlockTransclusionReference = "transclusion_reference"
+	// BlockTableRow is a row of a simple (non-collection) BlockTable
+	BlockTableRow = "table_row"
 )
 
 // for CollectionColumnInfo.Type
 const (
 	// ColumnMultiSelect is multi-select column
-	ColumnMultiSelect = "multi_select"
-	ColumnTypeNumber  = "number"
-	ColumnTypeTitle   = "title"
-	// TODO: text, select, date, person, Files&Media, checkbox, URL, Email, phone
-	// formula, relaion, created time, created by, last edited time, last edited by
+	ColumnMultiSelect        = "multi_select"
+	ColumnTypeNumber         = "number"
+	ColumnTypeTitle          = "title"
+	ColumnTypeText           = "text"
+	ColumnTypeSelect         = "select"
+	ColumnTypeDate           = "date"
+	ColumnTypePerson         = "person"
+	ColumnTypeFile           = "file"
+	ColumnTypeCheckbox       = "checkbox"
+	ColumnTypeURL            = "url"
+	ColumnTypeEmail          = "email"
+	ColumnTypePhoneNumber    = "phone_number"
+	ColumnTypeFormula        = "formula"
+	ColumnTypeRelation       = "relation"
+	ColumnTypeRollup         = "rollup"
+	ColumnTypeCreatedTime    = "created_time"
+	ColumnTypeCreatedBy      = "created_by"
+	ColumnTypeLastEditedTime = "last_edited_time"
+	ColumnTypeLastEditedBy   = "last_edited_by"
 )
 
 const (
@@ -94,6 +135,10 @@ const (
 	TableSpace = "space"
 	// TableBlock represents a Notion block
 	TableBlock = "block"
+	// TableCollection represents a Notion collection (database)
+	TableCollection = "collection"
+	// TableCollectionView represents a view of a Notion collection
+	TableCollectionView = "collection_view"
 )
 
 const (
@@ -108,4 +153,8 @@ const (
 	DateTypeDate = "date"
 	// DateTypeDateTime represents a datetime in Date.Type
 	DateTypeDateTime = "datetime"
+	// DateTypeDateRange represents a range of dates in Date.Type
+	DateTypeDateRange = "daterange"
+	// DateTypeDateTimeRange represents a range of datetimes in Date.Type
+	DateTypeDateTimeRange = "datetimerange"
 )
@@ -0,0 +1,158 @@
+package notiontypes
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02 15:04"
+)
+
+// maps Date.DateFormat to a Go time layout
+var dateFormats = map[string]string{
+	"MMM DD, YYYY": "Jan 2, 2006",
+	"MM/DD/YYYY":   "01/02/2006",
+	"DD/MM/YYYY":   "02/01/2006",
+	"YYYY/MM/DD":   "2006/01/02",
+}
+
+// HasTime returns true if the date includes a time of day
+func (d *Date) HasTime() bool {
+	return d.Type == DateTypeDateTime || d.Type == DateTypeDateTimeRange
+}
+
+// IsRange returns true if the date has an end
+func (d *Date) IsRange() bool {
+	return d.Type == DateTypeDateRange || d.Type == DateTypeDateTimeRange
+}
+
+// Location returns the time zone of the date. Dates without a time zone
+// are in UTC.
+func (d *Date) Location() (*time.Location, error) {
+	if d.TimeZone == nil || *d.TimeZone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(*d.TimeZone)
+}
+
+// Start returns the start of the date in its time zone. For dates without
+// time it's midnight.
+func (d *Date) Start() (time.Time, error) {
+	return d.parse(d.StartDate, d.StartTime)
+}
+
+// End returns the end of a date range. It returns zero time if the date
+// is not a range.
+func (d *Date) End() (time.Time, error) {
+	if !d.IsRange() || d.EndDate == "" {
+		return time.Time{}, nil
+	}
+	return d.parse(d.EndDate, d.EndTime)
+}
+
+func (d *Date) parse(date string, tm *string) (time.Time, error) {
+	loc, err := d.Location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if d.HasTime() && tm != nil && *tm != "" {
+		return time.ParseInLocation(dateTimeLayout, date+" "+*tm, loc)
+	}
+	return time.ParseInLocation(dateLayout, date, loc)
+}
+
+// ReminderTime returns the time at which the reminder of the date fires.
+// It returns zero time if the date has no reminder.
+func (d *Date) ReminderTime() (time.Time, error) {
+	r := d.Reminder
+	if r == nil {
+		return time.Time{}, nil
+	}
+	start, err := d.Start()
+	if err != nil {
+		return time.Time{}, err
+	}
+	var t time.Time
+	switch r.Unit {
+	case "minute":
+		t = start.Add(-time.Duration(r.Value) * time.Minute)
+	case "hour":
+		t = start.Add(-time.Duration(r.Value) * time.Hour)
+	case "day":
+		t = start.AddDate(0, 0, -int(r.Value))
+	case "week":
+		t = start.AddDate(0, 0, -7*int(r.Value))
+	default:
+		return time.Time{}, fmt.Errorf("unknown reminder unit '%s'", r.Unit)
+	}
+	// reminders for dates without time fire at a given time of that day
+	if !d.HasTime() && r.Time != "" {
+		tm, err := time.Parse("15:04", r.Time)
+		if err != nil {
+			return time.Time{}, err
+		}
+		t = time.Date(t.Year(), t.Month(), t.Day(), tm.Hour(), tm.Minute(), 0, 0, t.Location())
+	}
+	return t, nil
+}
+
+// Format returns the date formatted according to DateFormat and TimeFormat,
+// the way Notion displays it.
+func (d *Date) Format() string {
+	return d.format(time.Now())
+}
+
+func (d *Date) format(now time.Time) string {
+	start, err := d.Start()
+	if err != nil {
+		return d.StartDate
+	}
+	s := d.formatTime(start, now, true)
+	end, err := d.End()
+	if err != nil || end.IsZero() {
+		return s
+	}
+	sameDay := start.Year() == end.Year() && start.YearDay() == end.YearDay()
+	return s + " → " + d.formatTime(end, now, !sameDay)
+}
+
+func (d *Date) formatTime(t time.Time, now time.Time, withDate bool) string {
+	var s string
+	if withDate {
+		s = d.formatDate(t, now)
+	}
+	if !d.HasTime() {
+		return s
+	}
+	layout := "3:04 PM"
+	if d.TimeFormat != nil && *d.TimeFormat == "H:mm" {
+		layout = "15:04"
+	}
+	if s == "" {
+		return t.Format(layout)
+	}
+	return s + " " + t.Format(layout)
+}
+
+func (d *Date) formatDate(t time.Time, now time.Time) string {
+	if d.DateFormat == "relative" {
+		now = now.In(t.Location())
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, t.Location())
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		switch {
+		case day.Equal(today.AddDate(0, 0, -1)):
+			return "Yesterday"
+		case day.Equal(today):
+			return "Today"
+		case day.Equal(today.AddDate(0, 0, 1)):
+			return "Tomorrow"
+		}
+	}
+	layout, ok := dateFormats[d.DateFormat]
+	if !ok {
+		layout = "Jan 2, 2006"
+	}
+	return t.Format(layout)
+}
@@ -0,0 +1,59 @@
+package notiontypes
+
+import (
+	"testing"
+	"time"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestDateRange(t *testing.T) {
+	d := &Date{
+		Type:       DateTypeDateTimeRange,
+		DateFormat: "MM/DD/YYYY",
+		StartDate:  "2018-07-12",
+		StartTime:  strPtr("09:00"),
+		EndDate:    "2018-07-12",
+		EndTime:    strPtr("17:30"),
+		TimeZone:   strPtr("America/Los_Angeles"),
+		TimeFormat: strPtr("H:mm"),
+		Reminder:   &Reminder{Unit: "minute", Value: 30},
+	}
+	start, err := d.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2018, 7, 12, 16, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("Start() = %v, want %v", start, want)
+	}
+	end, _ := d.End()
+	if got := end.Sub(start); got != 8*time.Hour+30*time.Minute {
+		t.Errorf("End()-Start() = %v", got)
+	}
+	reminder, _ := d.ReminderTime()
+	if got := start.Sub(reminder); got != 30*time.Minute {
+		t.Errorf("reminder fires %v before start", got)
+	}
+	if got, want := d.format(start), "07/12/2018 09:00 → 17:30"; got != want {
+		t.Errorf("format() = %q, want %q", got, want)
+	}
+}
+
+func TestDateReminderAtTimeOfDay(t *testing.T) {
+	d := &Date{
+		Type:       DateTypeDate,
+		DateFormat: "relative",
+		StartDate:  "2018-07-12",
+		Reminder:   &Reminder{Unit: "day", Value: 1, Time: "09:00"},
+	}
+	reminder, err := d.ReminderTime()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2018, 7, 11, 9, 0, 0, 0, time.UTC); !reminder.Equal(want) {
+		t.Errorf("ReminderTime() = %v, want %v", reminder, want)
+	}
+	if got := d.format(reminder); got != "Tomorrow" {
+		t.Errorf("format() = %q, want Tomorrow", got)
+	}
+}
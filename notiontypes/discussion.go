@@ -0,0 +1,101 @@
+package notiontypes
+
+// DiscussionWithRole holds a user's role associated with a discussion and a discussion.
+type DiscussionWithRole struct {
+	Role  string      `json:"role"`
+	Value *Discussion `json:"value"`
+}
+
+// Discussion is a thread of comments attached to a block.
+type Discussion struct {
+	ID          string `json:"id"`
+	Alive       bool   `json:"alive"`
+	Version     int64  `json:"version"`
+	ParentID    string `json:"parent_id"`
+	ParentTable string `json:"parent_table"`
+	Resolved    bool   `json:"resolved"`
+	// the text the discussion is about, in the same format as Block.Properties values
+	ContextRaw interface{} `json:"context,omitempty"`
+	// ids of comments, in order
+	CommentIDs []string `json:"comments,omitempty"`
+
+	// Values calculated by us
+
+	// maps CommentIDs array
+	Comments []*Comment `json:"comments_resolved,omitempty"`
+	// parsed ContextRaw
+	Context []*InlineBlock `json:"context_resolved,omitempty"`
+}
+
+// CommentWithRole holds a user's role associated with a comment and a comment.
+type CommentWithRole struct {
+	Role  string   `json:"role"`
+	Value *Comment `json:"value"`
+}
+
+// Comment is a single comment in a Discussion.
+type Comment struct {
+	ID             string      `json:"id"`
+	Alive          bool        `json:"alive"`
+	Version        int64       `json:"version"`
+	ParentID       string      `json:"parent_id"`
+	ParentTable    string      `json:"parent_table"`
+	TextRaw        interface{} `json:"text,omitempty"`
+	CreatedBy      string      `json:"created_by"`
+	CreatedTime    int64       `json:"created_time"`
+	LastEditedTime int64       `json:"last_edited_time"`
+
+	// Values calculated by us
+
+	// parsed TextRaw
+	InlineContent []*InlineBlock `json:"inline_content,omitempty"`
+	// the user that wrote the comment, if known
+	Author *User `json:"author,omitempty"`
+}
+
+// ResolveDiscussions attaches discussions to blocks in idToBlock that refer to
+// them and populates comments of those discussions. Discussions and comments
+// that are missing from the maps or are deleted are skipped. idToUser is used
+// to set Comment.Author and may be nil.
+func ResolveDiscussions(idToBlock map[string]*Block, idToDiscussion map[string]*Discussion, idToComment map[string]*Comment, idToUser map[string]*User) error {
+	for _, block := range idToBlock {
+		block.Discussions = nil
+		for _, id := range block.DiscussionIDs {
+			d := idToDiscussion[id]
+			if d == nil || !d.Alive {
+				continue
+			}
+			if err := resolveDiscussion(d, idToComment, idToUser); err != nil {
+				return err
+			}
+			block.Discussions = append(block.Discussions, d)
+		}
+	}
+	return nil
+}
+
+func resolveDiscussion(d *Discussion, idToComment map[string]*Comment, idToUser map[string]*User) error {
+	var err error
+	if d.ContextRaw != nil {
+		d.Context, err = parseInlineBlocks(d.ContextRaw)
+		if err != nil {
+			return err
+		}
+	}
+	d.Comments = nil
+	for _, id := range d.CommentIDs {
+		c := idToComment[id]
+		if c == nil || !c.Alive {
+			continue
+		}
+		if c.TextRaw != nil {
+			c.InlineContent, err = parseInlineBlocks(c.TextRaw)
+			if err != nil {
+				return err
+			}
+		}
+		c.Author = idToUser[c.CreatedBy]
+		d.Comments = append(d.Comments, c)
+	}
+	return nil
+}
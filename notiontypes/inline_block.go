@@ -29,11 +29,14 @@ package notiontypes
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 const (
 	// InlineAt is what Notion uses for text to represent @user and @date blocks
 	InlineAt = "‣"
+	// InlineEquation is what Notion uses for text of inline equations
+	InlineEquation = "⁍"
 )
 
 // AttrFlag is a compact description of some flags
@@ -48,6 +51,8 @@ const (
 	AttrItalic
 	// AttrStrikeThrought represents strikethrough block
 	AttrStrikeThrought
+	// AttrUnderline represents underlined block
+	AttrUnderline
 )
 
 // InlineBlock describes a nested inline block
@@ -56,15 +61,41 @@ type InlineBlock struct {
 	Text string `json:"Text"`
 	// compact representation of attribute flags
 	AttrFlags AttrFlag `json:"AttrFlags,omitempty"`
+	// text and background color, e.g. "red", "gray"
+	Color           string `json:"Color,omitempty"`
+	BackgroundColor string `json:"BackgroundColor,omitempty"`
+	// ids of discussions this text is highlighted for
+	DiscussionIDs []string `json:"DiscussionIDs,omitempty"`
 	// only one of those is set on a given InlineBlock
-	Link   string `json:"Link,omitempty"`   // represents link attribute
-	UserID string `json:"UserID,omitempty"` // represents user attribute
-	Date   *Date  `json:"Date,omitempty"`   // represents date attribute
+	Link     string `json:"Link,omitempty"`     // represents link attribute
+	UserID   string `json:"UserID,omitempty"`   // represents user attribute
+	Date     *Date  `json:"Date,omitempty"`     // represents date attribute
+	PageID   string `json:"PageID,omitempty"`   // represents page mention attribute
+	Equation string `json:"Equation,omitempty"` // TeX source of inline equation
+
+	// calculated by us
+
+	// for page mentions, title of the page if it was loaded
+	PageTitle string `json:"PageTitle,omitempty"`
+	// for user mentions, the user, set by ResolveUsers
+	User *User `json:"User,omitempty"`
 }
 
 // IsPlain returns true if this InlineBlock is plain text i.e. has no attributes
 func (b *InlineBlock) IsPlain() bool {
-	return b.AttrFlags == 0 && b.Link == "" && b.UserID == "" && b.Date == nil
+	return b.AttrFlags == 0 && b.Link == "" && b.UserID == "" && b.Date == nil &&
+		b.PageID == "" && b.Equation == "" && b.Color == "" &&
+		b.BackgroundColor == "" && len(b.DiscussionIDs) == 0
+}
+
+// IsEquation returns true if this InlineBlock is an inline equation
+func (b *InlineBlock) IsEquation() bool {
+	return b.Equation != ""
+}
+
+// IsPageMention returns true if this InlineBlock is a mention of a page
+func (b *InlineBlock) IsPageMention() bool {
+	return b.PageID != ""
 }
 
 func parseAttribute(b *InlineBlock, a []interface{}) error {
@@ -86,6 +117,8 @@ func parseAttribute(b *InlineBlock, a []interface{}) error {
 			b.AttrFlags |= AttrStrikeThrought
 		case "c":
 			b.AttrFlags |= AttrCode
+		case "_":
+			b.AttrFlags |= AttrUnderline
 		default:
 			return fmt.Errorf("unexpected attribute '%s'", s)
 		}
@@ -97,15 +130,29 @@ func parseAttribute(b *InlineBlock, a []interface{}) error {
 	}
 
 	switch s {
-	case "a", "u":
+	case "a", "u", "p", "e", "m", "h":
 		v, ok := a[1].(string)
 		if !ok {
-			return fmt.Errorf("value for 'a' attribute is not string. Type: %T, value: %#v", v, v)
+			return fmt.Errorf("value for '%s' attribute is not string. Type: %T, value: %#v", s, a[1], a[1])
 		}
-		if s == "a" {
+		switch s {
+		case "a":
 			b.Link = v
-		} else if s == "u" {
+		case "u":
 			b.UserID = v
+		case "p":
+			b.PageID = v
+		case "e":
+			b.Equation = v
+		case "m":
+			b.DiscussionIDs = append(b.DiscussionIDs, v)
+		case "h":
+			// colors are "red" for text and "red_background" for background
+			if strings.HasSuffix(v, "_background") {
+				b.BackgroundColor = strings.TrimSuffix(v, "_background")
+			} else {
+				b.Color = v
+			}
 		}
 	case "d":
 		v := a[1].(map[string]interface{})
@@ -0,0 +1,54 @@
+package notiontypes
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseInlineBlocksAnnotations(t *testing.T) {
+	var raw interface{}
+	js := `[
+	["colored",[["h","red"],["h","yellow_background"],["_"]]],
+	["‣",[["p","page-id"]]],
+	["⁍",[["e","x^2"]]],
+	["commented",[["m","d1"],["m","d2"],["b"]]]
+	]`
+	if err := json.Unmarshal([]byte(js), &raw); err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := parseInlineBlocks(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := blocks[0]; b.Color != "red" || b.BackgroundColor != "yellow" || b.AttrFlags != AttrUnderline {
+		t.Errorf("colors: %+v", b)
+	}
+	if b := blocks[1]; !b.IsPageMention() || b.PageID != "page-id" {
+		t.Errorf("page mention: %+v", b)
+	}
+	if b := blocks[2]; !b.IsEquation() || b.Equation != "x^2" {
+		t.Errorf("equation: %+v", b)
+	}
+	if b := blocks[3]; len(b.DiscussionIDs) != 2 || b.AttrFlags != AttrBold || b.IsPlain() {
+		t.Errorf("comments: %+v", b)
+	}
+}
+
+func TestRawInlineBlocks(t *testing.T) {
+	js := `[["colored",[["_"],["h","red"],["h","yellow_background"]]],["‣",[["p","page-id"]]],["⁍",[["e","x^2"]]],["bold link",[["b"],["a","https://example.com"],["m","d1"]]],["plain"]]`
+	var raw interface{}
+	if err := json.Unmarshal([]byte(js), &raw); err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := parseInlineBlocks(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(RawInlineBlocks(blocks))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != js {
+		t.Errorf("got  %s\nwant %s", got, js)
+	}
+}
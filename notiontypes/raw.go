@@ -0,0 +1,168 @@
+package notiontypes
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RawInlineBlocks converts inline blocks to the format of text properties
+// used by notion.so, e.g. [["bold", [["b"]]], [" text"]]. It's the inverse of
+// parsing InlineContent of a block.
+func RawInlineBlocks(blocks []*InlineBlock) []interface{} {
+	raw := []interface{}{}
+	for _, b := range blocks {
+		var attrs []interface{}
+		add := func(a ...interface{}) {
+			attrs = append(attrs, a)
+		}
+		flags := []struct {
+			flag AttrFlag
+			name string
+		}{
+			{AttrBold, "b"}, {AttrItalic, "i"}, {AttrStrikeThrought, "s"}, {AttrCode, "c"}, {AttrUnderline, "_"},
+		}
+		for _, f := range flags {
+			if b.AttrFlags&f.flag != 0 {
+				add(f.name)
+			}
+		}
+		if b.Link != "" {
+			add("a", b.Link)
+		}
+		if b.Color != "" {
+			add("h", b.Color)
+		}
+		if b.BackgroundColor != "" {
+			add("h", b.BackgroundColor+"_background")
+		}
+		for _, id := range b.DiscussionIDs {
+			add("m", id)
+		}
+		text := b.Text
+		switch {
+		case b.UserID != "":
+			text = InlineAt
+			add("u", b.UserID)
+		case b.PageID != "":
+			text = InlineAt
+			add("p", b.PageID)
+		case b.Date != nil:
+			text = InlineAt
+			add("d", b.Date)
+		case b.Equation != "":
+			text = InlineEquation
+			add("e", b.Equation)
+		}
+		if len(attrs) == 0 {
+			raw = append(raw, []interface{}{text})
+		} else {
+			raw = append(raw, []interface{}{text, attrs})
+		}
+	}
+	return raw
+}
+
+// rawText returns a text property with a single plain value.
+func rawText(s string) []interface{} {
+	return []interface{}{[]interface{}{s}}
+}
+
+// RawProperties returns properties of a block in the format used by
+// notion.so, built from the values parsed from them: Title, InlineContent,
+// Code, CodeLanguage, IsChecked etc. It's used to create or update blocks.
+// Properties of collection rows other than the title are taken from
+// Properties as they are.
+func (b *Block) RawProperties() map[string]interface{} {
+	props := map[string]interface{}{}
+	for k, v := range b.Properties {
+		props[k] = v
+	}
+	switch b.Type {
+	case BlockPage:
+		if b.Title != "" {
+			props["title"] = rawText(b.Title)
+		}
+	case BlockCode:
+		props["title"] = rawText(b.Code)
+		if b.CodeLanguage != "" {
+			props["language"] = rawText(b.CodeLanguage)
+		}
+	case BlockEquation:
+		props["title"] = rawText(b.Equation)
+	default:
+		if len(b.InlineContent) > 0 {
+			props["title"] = RawInlineBlocks(b.InlineContent)
+		} else {
+			delete(props, "title")
+		}
+	}
+	if b.Type == BlockTodo {
+		checked := "No"
+		if b.IsChecked {
+			checked = "Yes"
+		}
+		props["checked"] = rawText(checked)
+	}
+	if b.Source != "" {
+		props["source"] = rawText(b.Source)
+	}
+	if b.Link != "" {
+		props["link"] = rawText(b.Link)
+	}
+	if b.Description != "" {
+		props["description"] = rawText(b.Description)
+	}
+	return props
+}
+
+// RawPropertyValue converts a typed value of a property of a collection row
+// to the format used by notion.so. It's the inverse of PropertyValue, see
+// Property for types of values. It returns nil for nil values and for values
+// of properties computed by notion.so, like created_time.
+func RawPropertyValue(columnType string, value interface{}) interface{} {
+	// mentions and links separated by commas
+	list := func(attr string, values []string) []interface{} {
+		raw := []interface{}{}
+		for i, v := range values {
+			if i > 0 {
+				raw = append(raw, []interface{}{","})
+			}
+			text := InlineAt
+			if attr == "a" {
+				text = v
+			}
+			raw = append(raw, []interface{}{text, []interface{}{[]interface{}{attr, v}}})
+		}
+		return raw
+	}
+	switch v := value.(type) {
+	case string:
+		switch columnType {
+		case ColumnTypeCreatedBy, ColumnTypeLastEditedBy:
+			return nil
+		case ColumnTypeURL:
+			return list("a", []string{v})
+		}
+		return rawText(v)
+	case float64:
+		return rawText(strconv.FormatFloat(v, 'f', -1, 64))
+	case bool:
+		if v {
+			return rawText("Yes")
+		}
+		return rawText("No")
+	case []string:
+		switch columnType {
+		case ColumnTypePerson:
+			return list("u", v)
+		case ColumnTypeRelation:
+			return list("p", v)
+		case ColumnTypeFile:
+			return list("a", v)
+		}
+		return rawText(strings.Join(v, ","))
+	case *Date:
+		return []interface{}{[]interface{}{InlineAt, []interface{}{[]interface{}{"d", v}}}}
+	}
+	return nil
+}
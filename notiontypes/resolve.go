@@ -44,6 +44,9 @@ func ResolveBlock(block *Block, idToBlock map[string]*Block) error {
 		return err
 	}
 
+	resolveMentions(block.InlineContent, idToBlock)
+	resolveReference(block, idToBlock)
+
 	if block.Content != nil || len(block.ContentIDs) == 0 {
 		return nil
 	}
@@ -73,6 +76,79 @@ func ResolveBlock(block *Block, idToBlock map[string]*Block) error {
 			block.Content = append(a[:i], a[i+1:]...)
 		}
 	}
+	if block.Type == BlockTable {
+		return resolveTableRows(block, idToBlock)
+	}
+	return nil
+}
+
+// resolveReference sets Reference for blocks that point to other blocks
+func resolveReference(block *Block, idToBlock map[string]*Block) {
+	var ptr *Pointer
+	switch {
+	case block.FormatAlias != nil:
+		ptr = block.FormatAlias.AliasPointer
+	case block.FormatTransclusion != nil:
+		ptr = block.FormatTransclusion.TransclusionReferencePointer
+	}
+	if ptr == nil || block.Reference != nil {
+		return
+	}
+	ref := idToBlock[ptr.ID]
+	if ref == nil {
+		return
+	}
+	block.Reference = ref
+	if block.Type == BlockAlias {
+		// only need the title of linked page, not its content
+		parseProperties(ref)
+		parseFormat(ref)
+		return
+	}
+	ResolveBlock(ref, idToBlock)
+}
+
+// resolveMentions sets PageTitle of page mentions
+func resolveMentions(inline []*InlineBlock, idToBlock map[string]*Block) {
+	for _, b := range inline {
+		if b.PageID == "" {
+			continue
+		}
+		page := idToBlock[b.PageID]
+		if page == nil {
+			continue
+		}
+		if page.Title == "" {
+			parseProperties(page)
+		}
+		b.PageTitle = page.Title
+	}
+}
+
+// resolveTableRows sets Cells of BlockTableRow children of a simple table
+func resolveTableRows(table *Block, idToBlock map[string]*Block) error {
+	if table.FormatTable == nil {
+		return nil
+	}
+	columns := table.FormatTable.TableBlockColumnOrder
+	for _, row := range table.Content {
+		if row.Type != BlockTableRow {
+			continue
+		}
+		row.Cells = make([][]*InlineBlock, len(columns))
+		for i, col := range columns {
+			v, ok := row.Properties[col]
+			if a, isList := v.([]interface{}); !ok || isList && len(a) == 0 {
+				continue
+			}
+			cell, err := parseInlineBlocks(v)
+			if err != nil {
+				return fmt.Errorf("table row %s, column %s: %v", row.ID, col, err)
+			}
+			row.Cells[i] = cell
+			resolveMentions(cell, idToBlock)
+		}
+	}
 	return nil
 }
 
@@ -113,6 +189,8 @@ func parseProperties(block *Block) error {
 			block.Title, err = getFirstInlineBlock(title)
 		} else if block.Type == BlockCode {
 			block.Code, err = getFirstInlineBlock(title)
+		} else if block.Type == BlockEquation {
+			block.Equation, err = getFirstInlineBlock(title)
 		} else {
 			block.InlineContent, err = parseInlineBlocks(title)
 		}
@@ -134,9 +212,10 @@ func parseProperties(block *Block) error {
 	// for BlockBookmark
 	getProp(block, "link", &block.Link)
 
-	// for BlockBookmark, BlockImage, BlockGist, BlockFile
+	// for BlockBookmark, BlockImage, BlockGist, BlockFile, BlockEmbed,
+	// BlockAudio, BlockPDF
 	// don't over-write if was already set from "source" json field
-	if block.Source != "" {
+	if block.Source == "" {
 		getProp(block, "source", &block.Source)
 	}
 
@@ -215,7 +294,7 @@ func parseFormat(block *Block) error {
 		if err == nil {
 			block.FormatTable = &format
 		}
-	case BlockText:
+	case BlockText, BlockHeader, BlockSubHeader, BlockSubSubHeader:
 		var format FormatText
 		err = json.Unmarshal(block.FormatRaw, &format)
 		if err == nil {
@@ -227,6 +306,30 @@ func parseFormat(block *Block) error {
 		if err == nil {
 			block.FormatVideo = &format
 		}
+	case BlockCallout:
+		var format FormatCallout
+		err = json.Unmarshal(block.FormatRaw, &format)
+		if err == nil {
+			block.FormatCallout = &format
+		}
+	case BlockEmbed, BlockAudio, BlockPDF:
+		var format FormatEmbed
+		err = json.Unmarshal(block.FormatRaw, &format)
+		if err == nil {
+			block.FormatEmbed = &format
+		}
+	case BlockAlias:
+		var format FormatAlias
+		err = json.Unmarshal(block.FormatRaw, &format)
+		if err == nil {
+			block.FormatAlias = &format
+		}
+	case BlockTransclusionReference:
+		var format FormatTransclusion
+		err = json.Unmarshal(block.FormatRaw, &format)
+		if err == nil {
+			block.FormatTransclusion = &format
+		}
 	}
 
 	if err != nil {
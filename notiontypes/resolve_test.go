@@ -0,0 +1,68 @@
+package notiontypes
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func mustBlocks(t *testing.T, js string) map[string]*Block {
+	t.Helper()
+	var blocks []*Block
+	if err := json.Unmarshal([]byte(js), &blocks); err != nil {
+		t.Fatal(err)
+	}
+	m := make(map[string]*Block, len(blocks))
+	for _, b := range blocks {
+		m[b.ID] = b
+	}
+	return m
+}
+
+func TestResolveBlockNewTypes(t *testing.T) {
+	blocks := mustBlocks(t, `[
+	{"id":"p","type":"page","properties":{"title":[["Root"]]},"content":["h","eq","emb","tbl","ref","al"]},
+	{"id":"h","type":"sub_sub_header","properties":{"title":[["Small"]]},"format":{"toggleable":true}},
+	{"id":"eq","type":"equation","properties":{"title":[["e=mc^2"]]}},
+	{"id":"emb","type":"pdf","properties":{"source":[["https://example.com/a.pdf"]]},"format":{"block_width":300}},
+	{"id":"tbl","type":"table","format":{"table_block_column_order":["b","a"]},"content":["r1"]},
+	{"id":"r1","type":"table_row","properties":{"a":[["A"]],"b":[["B"]]}},
+	{"id":"ref","type":"transclusion_reference","format":{"transclusion_reference_pointer":{"id":"sc","table":"block"}}},
+	{"id":"sc","type":"transclusion_container","content":["t"]},
+	{"id":"t","type":"text","properties":{"title":[["synced"]]}},
+	{"id":"al","type":"alias","format":{"alias_pointer":{"id":"other","table":"block"}}},
+	{"id":"other","type":"page","properties":{"title":[["Other"]]}}
+	]`)
+	root := blocks["p"]
+	if err := ResolveBlock(root, blocks); err != nil {
+		t.Fatal(err)
+	}
+	h := blocks["h"]
+	if h.HeaderLevel() != 3 || !h.IsToggleable() {
+		t.Errorf("header: level=%d toggleable=%v", h.HeaderLevel(), h.IsToggleable())
+	}
+	if got := blocks["eq"].Equation; got != "e=mc^2" {
+		t.Errorf("equation: %q", got)
+	}
+	if emb := blocks["emb"]; emb.Source != "https://example.com/a.pdf" || emb.FormatEmbed == nil || emb.FormatEmbed.BlockWidth != 300 {
+		t.Errorf("pdf: source=%q format=%+v", emb.Source, emb.FormatEmbed)
+	}
+	if cells := blocks["r1"].Cells; len(cells) != 2 || cells[0][0].Text != "B" || cells[1][0].Text != "A" {
+		t.Errorf("table row cells not in column order: %+v", cells)
+	}
+	if c := blocks["ref"].SyncedContent(); len(c) != 1 || c[0].InlineContent[0].Text != "synced" {
+		t.Errorf("synced content: %+v", c)
+	}
+	if ref := blocks["al"].Reference; ref == nil || ref.Title != "Other" {
+		t.Errorf("alias reference: %+v", ref)
+	}
+}
+
+func TestResolveTableRowsMalformedCell(t *testing.T) {
+	blocks := mustBlocks(t, `[
+	{"id":"tbl","type":"table","format":{"table_block_column_order":["a","b"]},"content":["r1"]},
+	{"id":"r1","type":"table_row","properties":{"a":[["A"]],"b":"not a cell"}}
+	]`)
+	if err := ResolveBlock(blocks["tbl"], blocks); err == nil {
+		t.Error("expected an error for a malformed cell")
+	}
+}
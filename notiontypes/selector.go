@@ -0,0 +1,175 @@
+package notiontypes
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Selector selects blocks of a tree, similar to CSS selectors. A selector
+// is a comma separated list of alternatives. Each alternative is a sequence
+// of block types, or * for any type, joined by combinators:
+//
+//	a b    b is a descendant of a
+//	a > b  b is a child of a
+//	a + b  b immediately follows a
+//	a ~ b  b follows a, with the same parent
+//
+// Types can be followed by pseudo-classes: :checked and :unchecked for to-do
+// blocks, :empty for blocks without text or content, and :first and :last
+// for the first and last block of its parent. For example,
+// "header + bulleted_list" selects lists directly following a header and
+// "to_do:unchecked" selects open tasks.
+type Selector struct {
+	alternatives [][]*selectorStep
+}
+
+// selectorStep is a block type with pseudo-classes, and the combinator that
+// relates it to the previous step.
+type selectorStep struct {
+	combinator byte
+	typ        string
+	pseudo     []string
+}
+
+var selectorPseudoClasses = map[string]func(n *selectorNode) bool{
+	"checked": func(n *selectorNode) bool {
+		return n.b.Type == BlockTodo && n.b.IsChecked
+	},
+	"unchecked": func(n *selectorNode) bool {
+		return n.b.Type == BlockTodo && !n.b.IsChecked
+	},
+	"empty": func(n *selectorNode) bool {
+		return len(n.b.Content) == 0 && len(n.b.InlineContent) == 0 && n.b.Title == "" && n.b.Code == "" && n.b.Source == ""
+	},
+	"first": func(n *selectorNode) bool {
+		return n.parent != nil && n.index == 0
+	},
+	"last": func(n *selectorNode) bool {
+		return n.parent != nil && n.index == len(n.parent.b.Content)-1
+	},
+}
+
+// CompileSelector parses a selector.
+func CompileSelector(s string) (*Selector, error) {
+	sel := &Selector{}
+	for _, alt := range strings.Split(s, ",") {
+		var steps []*selectorStep
+		combinator := byte(0)
+		fields := strings.Fields(strings.NewReplacer(">", " > ", "+", " + ", "~", " ~ ").Replace(alt))
+		for _, f := range fields {
+			switch f {
+			case ">", "+", "~":
+				if combinator != 0 && combinator != ' ' || len(steps) == 0 {
+					return nil, fmt.Errorf("selector %q: unexpected %q", s, f)
+				}
+				combinator = f[0]
+				continue
+			}
+			parts := strings.Split(f, ":")
+			step := &selectorStep{combinator: combinator, typ: parts[0], pseudo: parts[1:]}
+			if step.typ == "" {
+				return nil, fmt.Errorf("selector %q: missing block type in %q", s, f)
+			}
+			for _, p := range step.pseudo {
+				if selectorPseudoClasses[p] == nil {
+					return nil, fmt.Errorf("selector %q: unknown pseudo-class %q", s, p)
+				}
+			}
+			steps = append(steps, step)
+			combinator = ' '
+		}
+		if len(steps) == 0 || combinator != ' ' {
+			return nil, fmt.Errorf("selector %q: incomplete selector", s)
+		}
+		sel.alternatives = append(sel.alternatives, steps)
+	}
+	return sel, nil
+}
+
+// MustCompileSelector is like CompileSelector but panics if the selector
+// cannot be parsed.
+func MustCompileSelector(s string) *Selector {
+	sel, err := CompileSelector(s)
+	if err != nil {
+		panic(err)
+	}
+	return sel
+}
+
+// Select returns root and its descendants matched by the selector, in
+// document order.
+func (s *Selector) Select(root *Block) []*Block {
+	var found []*Block
+	nodes := map[*Block]*selectorNode{}
+	Walk(root, func(c *Cursor) error {
+		n := &selectorNode{b: c.Block, index: c.Index, parent: nodes[c.Parent()]}
+		nodes[c.Block] = n
+		for _, steps := range s.alternatives {
+			if n.matches(steps, len(steps)-1) {
+				found = append(found, c.Block)
+				break
+			}
+		}
+		return nil
+	}, nil)
+	return found
+}
+
+// Select returns root and its descendants matched by a selector. See
+// Selector for the syntax.
+func Select(root *Block, selector string) ([]*Block, error) {
+	sel, err := CompileSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	return sel.Select(root), nil
+}
+
+// selectorNode is a block with links to its parent, for matching
+// combinators.
+type selectorNode struct {
+	b      *Block
+	parent *selectorNode
+	index  int
+}
+
+// sibling returns the node of the i-th block of the parent's content.
+func (n *selectorNode) sibling(i int) *selectorNode {
+	return &selectorNode{b: n.parent.b.Content[i], parent: n.parent, index: i}
+}
+
+// matches returns true if n matches steps[k] and its ancestors or siblings
+// match the preceding steps.
+func (n *selectorNode) matches(steps []*selectorStep, k int) bool {
+	step := steps[k]
+	if step.typ != "*" && step.typ != n.b.Type {
+		return false
+	}
+	for _, p := range step.pseudo {
+		if !selectorPseudoClasses[p](n) {
+			return false
+		}
+	}
+	if k == 0 {
+		return true
+	}
+	switch step.combinator {
+	case ' ':
+		for a := n.parent; a != nil; a = a.parent {
+			if a.matches(steps, k-1) {
+				return true
+			}
+		}
+	case '>':
+		return n.parent != nil && n.parent.matches(steps, k-1)
+	case '+':
+		return n.parent != nil && n.index > 0 && n.sibling(n.index-1).matches(steps, k-1)
+	case '~':
+		for i := n.index - 1; n.parent != nil && i >= 0; i-- {
+			if n.sibling(i).matches(steps, k-1) {
+				return true
+			}
+		}
+	}
+	return false
+}
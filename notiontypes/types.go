@@ -26,6 +26,11 @@
 
 package notiontypes
 
+import (
+	"encoding/json"
+	"strings"
+)
+
 // RecordMap contains a collections of blocks, a space, users, and collections.
 type RecordMap struct {
 	Blocks          map[string]*BlockWithRole          `json:"block"`
@@ -33,6 +38,8 @@ type RecordMap struct {
 	Users           map[string]*UserWithRole           `json:"notion_user"`
 	Collections     map[string]*CollectionWithRole     `json:"collection"`
 	CollectionViews map[string]*CollectionViewWithRole `json:"collection_view"`
+	Discussions     map[string]*DiscussionWithRole     `json:"discussion"`
+	Comments        map[string]*CommentWithRole        `json:"comment"`
 }
 
 // CollectionViewWithRole describes a role and a collection view
@@ -64,6 +71,9 @@ type CollectionViewFormat struct {
 // CollectionViewQuery describes a query
 type CollectionViewQuery struct {
 	Aggregate []*AggregateQuery `json:"aggregate"`
+	// filter and sort are passed as-is to queryCollection
+	Filter json.RawMessage `json:"filter,omitempty"`
+	Sort   json.RawMessage `json:"sort,omitempty"`
 }
 
 // AggregateQuery describes an aggregate query
@@ -138,6 +148,15 @@ type User struct {
 	Version                   int    `json:"version"`
 }
 
+// Name returns the full name of the user, or the email if the name is not set.
+func (u *User) Name() string {
+	name := strings.TrimSpace(u.GivenName + " " + u.FamilyName)
+	if name == "" {
+		return u.Email
+	}
+	return name
+}
+
 // Date describes a date
 type Date struct {
 	// "MMM DD, YYYY", "MM/DD/YYYY", "DD/MM/YYYY", "YYYY/MM/DD", "relative"
@@ -147,17 +166,20 @@ type Date struct {
 	StartDate string `json:"start_date"`
 	// "09:00"
 	StartTime *string `json:"start_time,omitempty"`
+	// for "daterange" and "datetimerange", same format as StartDate and StartTime
+	EndDate string  `json:"end_date,omitempty"`
+	EndTime *string `json:"end_time,omitempty"`
 	// "America/Los_Angeles"
 	TimeZone *string `json:"time_zone,omitempty"`
 	// "H:mm" for 24hr, not given for 12hr
 	TimeFormat *string `json:"time_format,omitempty"`
-	// "date", "datetime"
+	// "date", "datetime", "daterange", "datetimerange"
 	Type string `json:"type"`
 }
 
 // Reminder describes date reminder
 type Reminder struct {
-	Time  string `json:"time"` // e.g. "09:00"
-	Unit  string `json:"unit"` // e.g. "day"
+	Time  string `json:"time"` // e.g. "09:00", only for dates without time
+	Unit  string `json:"unit"` // "minute", "hour", "day" or "week"
 	Value int64  `json:"value"`
 }
@@ -0,0 +1,69 @@
+package notiontypes
+
+// forEachInline calls fn for all inline blocks of a block, including
+// table cells and comments.
+func forEachInline(b *Block, fn func(*InlineBlock)) {
+	for _, ib := range b.InlineContent {
+		fn(ib)
+	}
+	for _, cell := range b.Cells {
+		for _, ib := range cell {
+			fn(ib)
+		}
+	}
+	for _, d := range b.Discussions {
+		for _, c := range d.Comments {
+			for _, ib := range c.InlineContent {
+				fn(ib)
+			}
+		}
+	}
+}
+
+// UserIDs returns ids of all users referred to by block and its descendants:
+// creators, last editors, mentioned users and comment authors.
+func UserIDs(block *Block) []string {
+	var ids []string
+	seen := map[string]bool{}
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	InspectSynced(block, func(b *Block) bool {
+		add(b.CreatedBy)
+		add(b.LastEditedBy)
+		forEachInline(b, func(ib *InlineBlock) {
+			add(ib.UserID)
+		})
+		for _, d := range b.Discussions {
+			for _, c := range d.Comments {
+				add(c.CreatedBy)
+			}
+		}
+		return true
+	})
+	return ids
+}
+
+// ResolveUsers sets CreatedByUser, LastEditedByUser, InlineBlock.User and
+// Comment.Author for block and its descendants. Users missing from idToUser
+// are left unresolved.
+func ResolveUsers(block *Block, idToUser map[string]*User) {
+	InspectSynced(block, func(b *Block) bool {
+		b.CreatedByUser = idToUser[b.CreatedBy]
+		b.LastEditedByUser = idToUser[b.LastEditedBy]
+		forEachInline(b, func(ib *InlineBlock) {
+			if ib.UserID != "" {
+				ib.User = idToUser[ib.UserID]
+			}
+		})
+		for _, d := range b.Discussions {
+			for _, c := range d.Comments {
+				c.Author = idToUser[c.CreatedBy]
+			}
+		}
+		return true
+	})
+}
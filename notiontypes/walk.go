@@ -0,0 +1,164 @@
+package notiontypes
+
+import "errors"
+
+// SkipChildren can be returned by the pre-order function passed to Walk to
+// skip the content of a block.
+var SkipChildren = errors.New("skip children")
+
+// Cursor describes a block visited by Walk and its position in the tree.
+type Cursor struct {
+	Block *Block
+	// Path lists the ancestors of Block, from the root to its parent
+	Path []*Block
+	// Index is the position of Block in the content of its parent
+	Index int
+}
+
+// Parent returns the parent of the block, or nil for the root.
+func (c *Cursor) Parent() *Block {
+	if len(c.Path) == 0 {
+		return nil
+	}
+	return c.Path[len(c.Path)-1]
+}
+
+// Depth returns the depth of the block, 0 for the root.
+func (c *Cursor) Depth() int {
+	return len(c.Path)
+}
+
+// Walk traverses the tree rooted at root in depth-first order, following
+// Content. pre is called before the content of a block is visited and post
+// after; either may be nil. If pre returns SkipChildren, the content of the
+// block is skipped but post is still called. Any other error stops the walk
+// and is returned.
+//
+// The Cursor and its Path are reused between calls and must be copied to be
+// retained.
+func Walk(root *Block, pre, post func(c *Cursor) error) error {
+	return walk(root, pre, post, false)
+}
+
+// WalkSynced is like Walk, but follows SyncedContent instead of Content, so
+// that the content of synced blocks is visited under the blocks referring to
+// it. Each block is visited once, even if it is synced to several places.
+func WalkSynced(root *Block, pre, post func(c *Cursor) error) error {
+	return walk(root, pre, post, true)
+}
+
+func walk(root *Block, pre, post func(c *Cursor) error, synced bool) error {
+	c := &Cursor{}
+	seen := map[*Block]bool{}
+	var visit func(b *Block, index int) error
+	visit = func(b *Block, index int) error {
+		if synced {
+			if seen[b] {
+				return nil
+			}
+			seen[b] = true
+		}
+		c.Block, c.Index = b, index
+		skip := false
+		if pre != nil {
+			err := pre(c)
+			if err == SkipChildren {
+				skip = true
+			} else if err != nil {
+				return err
+			}
+		}
+		if !skip {
+			content := b.Content
+			if synced {
+				content = b.SyncedContent()
+			}
+			c.Path = append(c.Path, b)
+			for i, child := range content {
+				if child == nil {
+					continue
+				}
+				if err := visit(child, i); err != nil {
+					return err
+				}
+			}
+			c.Path = c.Path[:len(c.Path)-1]
+		}
+		if post != nil {
+			c.Block, c.Index = b, index
+			return post(c)
+		}
+		return nil
+	}
+	if root == nil {
+		return nil
+	}
+	return visit(root, 0)
+}
+
+// Inspect calls f for root and its descendants in pre-order. If f returns
+// false, the content of the block is skipped.
+func Inspect(root *Block, f func(b *Block) bool) {
+	Walk(root, inspector(f), nil)
+}
+
+// InspectSynced is like Inspect, but follows SyncedContent like WalkSynced.
+func InspectSynced(root *Block, f func(b *Block) bool) {
+	WalkSynced(root, inspector(f), nil)
+}
+
+func inspector(f func(b *Block) bool) func(c *Cursor) error {
+	return func(c *Cursor) error {
+		if !f(c.Block) {
+			return SkipChildren
+		}
+		return nil
+	}
+}
+
+// FindByType returns root and its descendants with one of the given types,
+// in document order.
+func FindByType(root *Block, types ...string) []*Block {
+	var found []*Block
+	Inspect(root, func(b *Block) bool {
+		for _, t := range types {
+			if b.Type == t {
+				found = append(found, b)
+				break
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// FindByID returns the block with the given id in the tree rooted at root,
+// or nil.
+func FindByID(root *Block, id string) *Block {
+	var found *Block
+	Inspect(root, func(b *Block) bool {
+		if b.ID == id {
+			found = b
+		}
+		return found == nil
+	})
+	return found
+}
+
+// Ancestors returns the ancestors of the block with the given id, from root
+// to its parent. It returns nil if the block is root or is not in the tree.
+func Ancestors(root *Block, id string) []*Block {
+	var path []*Block
+	errFound := errors.New("found")
+	err := Walk(root, func(c *Cursor) error {
+		if c.Block.ID == id {
+			path = append([]*Block(nil), c.Path...)
+			return errFound
+		}
+		return nil
+	}, nil)
+	if err != errFound || len(path) == 0 {
+		return nil
+	}
+	return path
+}
@@ -0,0 +1,129 @@
+package notiontypes
+
+import (
+	"strings"
+	"testing"
+)
+
+func testTree(t *testing.T) *Block {
+	t.Helper()
+	blocks := mustBlocks(t, `[
+	{"id":"p","type":"page","properties":{"title":[["Root"]]},"content":["h","l1","l2","t1","tg"]},
+	{"id":"h","type":"header","properties":{"title":[["Tasks"]]}},
+	{"id":"l1","type":"bulleted_list","properties":{"title":[["one"]]}},
+	{"id":"l2","type":"bulleted_list","properties":{"title":[["two"]]}},
+	{"id":"t1","type":"to_do","properties":{"title":[["done"]],"checked":[["Yes"]]}},
+	{"id":"tg","type":"toggle","properties":{"title":[["more"]]},"content":["t2","e"]},
+	{"id":"t2","type":"to_do","properties":{"title":[["open"]]}},
+	{"id":"e","type":"text"}
+	]`)
+	root := blocks["p"]
+	if err := ResolveBlock(root, blocks); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func ids(blocks []*Block) string {
+	var s []string
+	for _, b := range blocks {
+		s = append(s, b.ID)
+	}
+	return strings.Join(s, " ")
+}
+
+func TestWalk(t *testing.T) {
+	root := testTree(t)
+	var got []string
+	err := Walk(root, func(c *Cursor) error {
+		got = append(got, "+"+c.Block.ID)
+		if c.Block.ID == "tg" {
+			return SkipChildren
+		}
+		return nil
+	}, func(c *Cursor) error {
+		if c.Depth() > 0 {
+			got = append(got, "-"+c.Block.ID+"@"+c.Parent().ID)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "+p +h -h@p +l1 -l1@p +l2 -l2@p +t1 -t1@p +tg -tg@p"
+	if s := strings.Join(got, " "); s != want {
+		t.Errorf("got %s\nwant %s", s, want)
+	}
+
+	if got := ids(FindByType(root, BlockTodo)); got != "t1 t2" {
+		t.Errorf("FindByType: %s", got)
+	}
+	if b := FindByID(root, "e"); b == nil || b.ID != "e" {
+		t.Errorf("FindByID: %v", b)
+	}
+	if got := ids(Ancestors(root, "t2")); got != "p tg" {
+		t.Errorf("Ancestors: %s", got)
+	}
+}
+
+func TestWalkSynced(t *testing.T) {
+	blocks := mustBlocks(t, `[
+	{"id":"p","type":"page","properties":{"title":[["Root"]]},"content":["ref","sc"]},
+	{"id":"ref","type":"transclusion_reference","format":{"transclusion_reference_pointer":{"id":"sc","table":"block"}}},
+	{"id":"sc","type":"transclusion_container","content":["t"]},
+	{"id":"t","type":"text","properties":{"title":[["synced"]]}}
+	]`)
+	root := blocks["p"]
+	if err := ResolveBlock(root, blocks); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	err := WalkSynced(root, func(c *Cursor) error {
+		got = append(got, c.Block.ID+"@"+ids(c.Path))
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the synced text is visited once, under the first block showing it
+	if s := strings.Join(got, " "); s != "p@ ref@p t@p ref sc@p" {
+		t.Errorf("WalkSynced: %s", s)
+	}
+	var inspected []*Block
+	Inspect(root, func(b *Block) bool {
+		inspected = append(inspected, b)
+		return true
+	})
+	if s := ids(inspected); s != "p ref sc t" {
+		t.Errorf("Inspect: %s", s)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	root := testTree(t)
+	tests := []struct {
+		selector, want string
+	}{
+		{"header + bulleted_list", "l1"},
+		{"header ~ bulleted_list", "l1 l2"},
+		{"to_do:unchecked", "t2"},
+		{"to_do:checked, text:empty", "t1 e"},
+		{"page > to_do", "t1"},
+		{"page toggle *:last", "e"},
+	}
+	for _, tt := range tests {
+		got, err := Select(root, tt.selector)
+		if err != nil {
+			t.Errorf("%s: %v", tt.selector, err)
+			continue
+		}
+		if ids(got) != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.selector, ids(got), tt.want)
+		}
+	}
+	for _, s := range []string{"", "header +", "> text", "text:bogus", "a,,b"} {
+		if _, err := CompileSelector(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
@@ -0,0 +1,477 @@
+package notion
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+type orgPrinter struct {
+	buf       *bytes.Buffer
+	cfg       *printConfig
+	page      *Page
+	prefix    string
+	footnotes []string
+	// level of the page headline, 0 without title
+	base int
+	// level of the current headline
+	level int
+	// quote is set inside quotes, where headlines can't be used
+	quote bool
+	// tail is set if the blocks being printed are followed by nothing but
+	// to-dos until the next header, so that a to-do can be a headline
+	// without taking the following content as its own
+	tail bool
+}
+
+// line writes s prefixed with the current prefix. Each line of a
+// multi-line s is prefixed.
+func (o *orgPrinter) line(s string) {
+	for _, l := range strings.Split(s, "\n") {
+		o.buf.WriteString(strings.TrimRight(o.prefix+l, " "))
+		o.buf.WriteString("\n")
+	}
+}
+
+// item writes a list item with marker before the first line of text and
+// continuation lines indented under it, so that they stay in the item.
+func (o *orgPrinter) item(marker string, text string) {
+	indent := strings.Repeat(" ", len(marker))
+	for i, l := range strings.Split(orgEscapeText(text), "\n") {
+		if i == 0 {
+			o.line(marker + l)
+		} else {
+			o.line(indent + l)
+		}
+	}
+}
+
+// nested prints blocks with prefix added to the current prefix. Nested
+// lists are kept tight, other content is separated by an empty line.
+func (o *orgPrinter) nested(prefix string, blocks []*notiontypes.Block) {
+	if len(blocks) == 0 {
+		return
+	}
+	old := o.prefix
+	o.prefix += prefix
+	if !isListItem(blocks[0]) {
+		o.line("")
+	}
+	o.printBlocks(blocks)
+	o.prefix = old
+}
+
+// canHeadline returns true if a headline can be written at the current
+// position, which is not the case in lists and quotes.
+func (o *orgPrinter) canHeadline() bool {
+	return o.prefix == "" && !o.quote
+}
+
+// headline writes a headline of the given level followed by a property
+// drawer.
+func (o *orgPrinter) headline(level int, text string, props [][2]string) {
+	o.level = level
+	o.line(strings.Repeat("*", level) + " " + text)
+	o.line(":PROPERTIES:")
+	for _, p := range props {
+		o.line(fmt.Sprintf(":%s: %s", p[0], p[1]))
+	}
+	o.line(":END:")
+}
+
+// orgTimestamp formats t as an inactive Org timestamp.
+func orgTimestamp(t time.Time, withTime bool) string {
+	if withTime {
+		return t.Format("[2006-01-02 Mon 15:04]")
+	}
+	return t.Format("[2006-01-02 Mon]")
+}
+
+// orgValue formats the value of a row property for a property drawer.
+func orgValue(p *notiontypes.Property, users map[string]*notiontypes.User) string {
+	switch v := p.Value.(type) {
+	case *notiontypes.Date:
+		start, err := v.Start()
+		if err != nil {
+			return v.StartDate
+		}
+		s := orgTimestamp(start, v.HasTime())
+		if end, err := v.End(); err == nil && v.IsRange() {
+			s += "--" + orgTimestamp(end, v.HasTime())
+		}
+		return s
+	case time.Time:
+		return orgTimestamp(v.UTC(), true)
+	}
+	return strings.Replace(exportText(exportValue(p, users)), "\n", " ", -1)
+}
+
+// orgKey converts a property name like "Published Date" to "PUBLISHED_DATE".
+func orgKey(name string) string {
+	return strings.ToUpper(snakeCase(name))
+}
+
+func (o *orgPrinter) printPage() {
+	page := o.page
+	if !o.cfg.noTitle {
+		o.base = 1
+		title := page.Title
+		if icon := pageIcon(page.Block); icon != "" && !isURLIcon(icon) {
+			title = icon + " " + title
+		}
+		props := [][2]string{
+			{"ID", page.ID},
+			{"CREATED", orgTimestamp(page.CreatedOn().UTC(), true)},
+			{"LAST_EDITED", orgTimestamp(page.UpdatedOn().UTC(), true)},
+		}
+		if page.Collection != nil {
+			for _, p := range page.Collection.RowProperties(page.Block) {
+				if p.Type == notiontypes.ColumnTypeTitle {
+					continue
+				}
+				if v := orgValue(p, page.Users); v != "" {
+					props = append(props, [2]string{orgKey(p.Name), v})
+				}
+			}
+		}
+		o.headline(1, title+o.footnoteRefs(page.Block), props)
+		if cover := o.cfg.coverURL(page.Block); cover != "" {
+			o.line("")
+			o.line("[[" + cover + "]]")
+		}
+		if len(page.Content) > 0 {
+			o.line("")
+		}
+	}
+	o.level = o.base
+	o.tail = true
+	o.printBlocks(page.Content)
+	o.printFootnotes()
+}
+
+func (o *orgPrinter) printFootnotes() {
+	if len(o.footnotes) > 0 {
+		o.line("")
+		for i, f := range o.footnotes {
+			o.line(fmt.Sprintf("[fn:%d] %s", i+1, f))
+		}
+	}
+}
+
+func (o *orgPrinter) printBlocks(blocks []*notiontypes.Block) {
+	num := 0
+	for i, b := range blocks {
+		tail := o.tail
+		o.tail = o.followedByTodos(blocks[i+1:])
+		// to-dos that are headlines aren't list items
+		headline := b.Type == notiontypes.BlockTodo && o.canHeadline() && o.tail
+		if i > 0 && !(isListItem(b) && blocks[i-1].Type == b.Type && !headline) {
+			o.line("")
+		}
+		if b.Type == notiontypes.BlockNumberedList {
+			num++
+		} else {
+			num = 0
+		}
+		o.printBlock(b, num)
+		o.tail = tail
+	}
+}
+
+// followedByTodos returns true if blocks, which follow the block being
+// printed, and the blocks after them are only to-dos up to the next header.
+func (o *orgPrinter) followedByTodos(blocks []*notiontypes.Block) bool {
+	for _, b := range blocks {
+		if b.IsHeader() {
+			return true
+		}
+		if b.Type != notiontypes.BlockTodo {
+			return false
+		}
+	}
+	return o.tail
+}
+
+func (o *orgPrinter) printBlock(b *notiontypes.Block, num int) {
+	text := o.inline(b.InlineContent) + o.footnoteRefs(b)
+	switch b.Type {
+	case notiontypes.BlockPage, notiontypes.BlockAlias:
+		id, title := pageTitle(b)
+		o.line(orgLink(o.cfg.pageURL(id), title))
+	case notiontypes.BlockHeader, notiontypes.BlockSubHeader, notiontypes.BlockSubSubHeader:
+		if !o.canHeadline() {
+			o.line(wrapMarkdown(text, "*"))
+			o.nested("", b.Content)
+			break
+		}
+		o.headline(o.base+b.HeaderLevel(), text, [][2]string{{"ID", b.ID}})
+		o.nested("", b.Content)
+	case notiontypes.BlockBulletedList, notiontypes.BlockToggle:
+		o.item("- ", text)
+		o.nested("  ", b.Content)
+	case notiontypes.BlockNumberedList:
+		marker := fmt.Sprintf("%d. ", num)
+		o.item(marker, text)
+		o.nested(strings.Repeat(" ", len(marker)), b.Content)
+	case notiontypes.BlockTodo:
+		if !o.canHeadline() || !o.tail {
+			check := " "
+			if b.IsChecked {
+				check = "X"
+			}
+			o.item("- ", fmt.Sprintf("[%s] %s", check, text))
+			o.nested("  ", b.Content)
+			break
+		}
+		keyword := "TODO"
+		if b.IsChecked {
+			keyword = "DONE"
+		}
+		level := o.level
+		o.headline(level+1, keyword+" "+text, [][2]string{{"ID", b.ID}})
+		o.nested("", b.Content)
+		o.level = level
+	case notiontypes.BlockQuote, notiontypes.BlockCallout:
+		if icon := pageIcon(b); icon != "" && !isURLIcon(icon) {
+			text = icon + " " + text
+		}
+		quote := o.quote
+		o.quote = true
+		o.line("#+BEGIN_QUOTE")
+		o.line(orgEscapeText(text))
+		o.nested("", b.Content)
+		o.line("#+END_QUOTE")
+		o.quote = quote
+	case notiontypes.BlockCode:
+		o.line(strings.TrimSpace("#+BEGIN_SRC " + orgLanguage(b.CodeLanguage)))
+		o.line(orgEscapeSrc(b.Code))
+		o.line("#+END_SRC")
+	case notiontypes.BlockEquation:
+		o.line(`\[`)
+		o.line(b.Equation)
+		o.line(`\]`)
+	case notiontypes.BlockDivider:
+		o.line("-----")
+	case notiontypes.BlockImage:
+		if text != "" {
+			o.line("#+CAPTION: " + text)
+		}
+		o.line("[[" + o.cfg.imageURL(b) + "]]")
+	case notiontypes.BlockBookmark:
+		o.line(orgLink(b.Link, text))
+	case notiontypes.BlockFile, notiontypes.BlockPDF, notiontypes.BlockAudio,
+		notiontypes.BlockVideo, notiontypes.BlockEmbed, notiontypes.BlockGist:
+		o.line(orgLink(o.cfg.fileURL(b), text))
+	case notiontypes.BlockTable:
+		o.printTable(b)
+	case notiontypes.BlockCollectionView, notiontypes.BlockCollectionViewPage:
+		o.printCollection(b)
+	case notiontypes.BlockTableOfContents:
+		o.line("#+TOC: headlines 3 local")
+	case notiontypes.BlockBreadcrumb:
+		// nothing to show
+	case notiontypes.BlockColumnList, notiontypes.BlockColumn,
+		notiontypes.BlockTransclusionContainer, notiontypes.BlockTransclusionReference:
+		o.printBlocks(b.SyncedContent())
+	default:
+		o.line(orgEscapeText(text))
+		o.nested("", b.Content)
+	}
+}
+
+// orgRow writes a row of an Org table.
+func (o *orgPrinter) orgRow(cells []string) {
+	for i, c := range cells {
+		cells[i] = strings.Replace(strings.Replace(c, "|", `\vert{}`, -1), "\n", " ", -1)
+	}
+	o.line("| " + strings.Join(cells, " | ") + " |")
+}
+
+func (o *orgPrinter) printTable(b *notiontypes.Block) {
+	for i, row := range b.Content {
+		var cells []string
+		for _, cell := range row.Cells {
+			cells = append(cells, o.inline(cell))
+		}
+		o.orgRow(cells)
+		if i == 0 && b.FormatTable != nil && b.FormatTable.TableBlockColumnHeader {
+			o.line("|-")
+		}
+	}
+}
+
+// printCollection prints the rows of the first view of a collection as a
+// table with the visible properties as columns.
+func (o *orgPrinter) printCollection(b *notiontypes.Block) {
+	if len(b.CollectionViews) == 0 || b.CollectionViews[0].Collection == nil {
+		return
+	}
+	info := b.CollectionViews[0]
+	coll := info.Collection
+	ids := coll.ViewPropertyIDs(info.CollectionView)
+	if name := coll.Title(); name != "" {
+		o.line("#+CAPTION: " + name)
+	}
+	var header []string
+	for _, id := range ids {
+		header = append(header, coll.CollectionSchema[id].Name)
+	}
+	o.orgRow(header)
+	o.line("|-")
+	for _, row := range info.CollectionRows {
+		props := map[string]*notiontypes.Property{}
+		for _, p := range coll.RowProperties(row) {
+			props[p.ID] = p
+		}
+		var cells []string
+		for _, id := range ids {
+			p := props[id]
+			if p == nil {
+				cells = append(cells, "")
+				continue
+			}
+			if p.Type == notiontypes.ColumnTypeTitle {
+				cells = append(cells, orgLink(o.cfg.pageURL(row.ID), exportText(p.Value)))
+				continue
+			}
+			cells = append(cells, orgValue(p, o.page.Users))
+		}
+		o.orgRow(cells)
+	}
+}
+
+// footnoteRefs records discussions of a block as footnotes and returns
+// references to them.
+func (o *orgPrinter) footnoteRefs(b *notiontypes.Block) string {
+	var refs string
+	for _, d := range b.Discussions {
+		var comments []string
+		for _, c := range d.Comments {
+			comments = append(comments, commentText(c))
+		}
+		if len(comments) == 0 {
+			continue
+		}
+		o.footnotes = append(o.footnotes, strings.Join(comments, "; "))
+		refs += fmt.Sprintf("[fn:%d]", len(o.footnotes))
+	}
+	return refs
+}
+
+func (o *orgPrinter) inline(blocks []*notiontypes.InlineBlock) string {
+	var sb strings.Builder
+	for _, b := range blocks {
+		sb.WriteString(o.inlineBlock(b))
+	}
+	return sb.String()
+}
+
+func (o *orgPrinter) inlineBlock(b *notiontypes.InlineBlock) string {
+	switch {
+	case b.IsEquation():
+		return `\(` + b.Equation + `\)`
+	case b.IsPageMention():
+		return orgLink(o.cfg.pageURL(b.PageID), plainText(b))
+	}
+	s := plainText(b)
+	if b.AttrFlags&notiontypes.AttrCode != 0 {
+		s = wrapMarkdown(s, "~")
+	}
+	if b.AttrFlags&notiontypes.AttrBold != 0 {
+		s = wrapMarkdown(s, "*")
+	}
+	if b.AttrFlags&notiontypes.AttrItalic != 0 {
+		s = wrapMarkdown(s, "/")
+	}
+	if b.AttrFlags&notiontypes.AttrStrikeThrought != 0 {
+		s = wrapMarkdown(s, "+")
+	}
+	if b.AttrFlags&notiontypes.AttrUnderline != 0 {
+		s = wrapMarkdown(s, "_")
+	}
+	if b.Link != "" {
+		s = orgLink(b.Link, s)
+	}
+	return s
+}
+
+// orgLink returns an Org link to u with a description, or a plain link if
+// the description is empty.
+func orgLink(u string, description string) string {
+	u = strings.NewReplacer("[", "%5B", "]", "%5D").Replace(u)
+	if description == "" {
+		return "[[" + u + "]]"
+	}
+	description = strings.NewReplacer("[", "{", "]", "}").Replace(description)
+	return "[[" + u + "][" + description + "]]"
+}
+
+// orgLanguages maps notion code languages to Org Babel language names,
+// where they differ from the lower-case name.
+var orgLanguages = map[string]string{
+	"plain text": "",
+	"bash":       "sh",
+	"shell":      "sh",
+	"javascript": "js",
+	"typescript": "typescript",
+	"c++":        "C++",
+	"c":          "C",
+	"c#":         "csharp",
+	"emacs lisp": "emacs-lisp",
+	"r":          "R",
+}
+
+func orgLanguage(lang string) string {
+	l := strings.ToLower(lang)
+	if o, ok := orgLanguages[l]; ok {
+		return o
+	}
+	return strings.Replace(l, " ", "-", -1)
+}
+
+// orgEscapeSrc escapes lines of source code that Org would otherwise parse
+// as headlines or keywords.
+func orgEscapeSrc(code string) string {
+	lines := strings.Split(code, "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(l, "*") || strings.HasPrefix(l, "#+") || strings.HasPrefix(l, ",*") || strings.HasPrefix(l, ",#+") {
+			lines[i] = "," + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// orgLineStart matches starts of lines that Org reads as headlines, comments
+// or keywords.
+var orgLineStart = regexp.MustCompile(`^(\*+ |\*+$|#\+|# |#$)`)
+
+// orgEscapeText escapes lines of text that would be read as headlines,
+// comments or keywords by starting them with a zero width space, the escape
+// character recommended by the Org manual.
+func orgEscapeText(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		if orgLineStart.MatchString(l) {
+			lines[i] = "\u200b" + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// PrintAsOrg renders a notion page as an Org document. The page is the top
+// headline, with headers as nested headlines. To-dos followed only by other
+// to-dos up to the next header are TODO and DONE headlines, other to-dos are
+// check boxes, so that headlines don't take following content as theirs.
+// Headlines have property drawers with the Notion block id, the
+// page also with its times and properties of database rows. Databases are
+// rendered as tables of the rows of their first view and discussions as
+// footnotes.
+func PrintAsOrg(page *Page, opts ...PrintOption) ([]byte, error) {
+	o := &orgPrinter{buf: new(bytes.Buffer), cfg: newPrintConfig(opts), page: page}
+	o.printPage()
+	return o.buf.Bytes(), nil
+}
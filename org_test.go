@@ -0,0 +1,116 @@
+package notion
+
+import "testing"
+
+func TestPrintAsOrg(t *testing.T) {
+	page := testPage(t, `[
+	{"id":"p","type":"page","created_time":1577836800000,"last_edited_time":1577923200000,"properties":{"title":[["Notes"]]},"content":["h","t","td","l","c"]},
+	{"id":"h","type":"header","properties":{"title":[["Intro"]]}},
+	{"id":"t","type":"text","properties":{"title":[["see "],["docs",[["b"],["a","https://example.com"]]]]}},
+	{"id":"td","type":"to_do","properties":{"title":[["ship it"]],"checked":[["Yes"]]}},
+	{"id":"l","type":"bulleted_list","properties":{"title":[["item"]]},"content":["td2"]},
+	{"id":"td2","type":"to_do","properties":{"title":[["nested"]]}},
+	{"id":"c","type":"code","properties":{"title":[["* not a headline"]],"language":[["Shell"]]}}
+	]`)
+	got, err := PrintAsOrg(page, WithPageURLs(func(id string) string { return id + ".org" }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "* Notes\n" +
+		":PROPERTIES:\n" +
+		":ID: p\n" +
+		":CREATED: [2020-01-01 Wed 00:00]\n" +
+		":LAST_EDITED: [2020-01-02 Thu 00:00]\n" +
+		":END:\n" +
+		"\n" +
+		"** Intro\n" +
+		":PROPERTIES:\n" +
+		":ID: h\n" +
+		":END:\n" +
+		"\n" +
+		"see [[https://example.com][*docs*]]\n" +
+		"\n" +
+		"- [X] ship it\n" +
+		"\n" +
+		"- item\n" +
+		"  - [ ] nested\n" +
+		"\n" +
+		"#+BEGIN_SRC sh\n" +
+		",* not a headline\n" +
+		"#+END_SRC\n"
+	if string(got) != want {
+		t.Errorf("PrintAsOrg() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPrintAsOrgTodoHeadlines(t *testing.T) {
+	page := testPage(t, `[
+	{"id":"p","type":"page","properties":{"title":[["Tasks"]]},"content":["a","b","h","t","c"]},
+	{"id":"a","type":"to_do","properties":{"title":[["first"]]}},
+	{"id":"b","type":"to_do","properties":{"title":[["second"]],"checked":[["Yes"]]},"content":["n"]},
+	{"id":"n","type":"text","properties":{"title":[["# note\n* star\n#+TITLE: x"]]}},
+	{"id":"h","type":"header","properties":{"title":[["Later"]]}},
+	{"id":"t","type":"text","properties":{"title":[["*bold* text"]]}},
+	{"id":"c","type":"to_do","properties":{"title":[["last"]]}}
+	]`)
+	got, err := PrintAsOrg(page, WithoutTitle())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "* TODO first\n" +
+		":PROPERTIES:\n" +
+		":ID: a\n" +
+		":END:\n" +
+		"\n" +
+		"* DONE second\n" +
+		":PROPERTIES:\n" +
+		":ID: b\n" +
+		":END:\n" +
+		"\n" +
+		"\u200b# note\n" +
+		"\u200b* star\n" +
+		"\u200b#+TITLE: x\n" +
+		"\n" +
+		"* Later\n" +
+		":PROPERTIES:\n" +
+		":ID: h\n" +
+		":END:\n" +
+		"\n" +
+		"*bold* text\n" +
+		"\n" +
+		"** TODO last\n" +
+		":PROPERTIES:\n" +
+		":ID: c\n" +
+		":END:\n"
+	if string(got) != want {
+		t.Errorf("PrintAsOrg() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPrintAsOrgMultilineItems(t *testing.T) {
+	page := testPage(t, `[
+	{"id":"p","type":"page","properties":{"title":[["Items"]]},"content":["b","n","t","x"]},
+	{"id":"b","type":"bulleted_list","properties":{"title":[["one\n* x\nthree"]]}},
+	{"id":"n","type":"numbered_list","properties":{"title":[["first\n#+BEGIN_SRC"]]}},
+	{"id":"t","type":"to_do","properties":{"title":[["task\nmore"]]}},
+	{"id":"x","type":"text","properties":{"title":[["after"]]}}
+	]`)
+	got, err := PrintAsOrg(page, WithoutTitle())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "- one\n" +
+		"  \u200b* x\n" +
+		"  three\n" +
+		"\n" +
+		"1. first\n" +
+		"   \u200b#+BEGIN_SRC\n" +
+		"\n" +
+		"- [ ] task\n" +
+		"  more\n" +
+		"\n" +
+		"after\n"
+	if string(got) != want {
+		t.Errorf("PrintAsOrg() =\n%s\nwant:\n%s", got, want)
+	}
+}
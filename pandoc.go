@@ -0,0 +1,323 @@
+package notion
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// pandocAPIVersion is the version of the Pandoc AST written by
+// PrintAsPandocJSON, as of pandoc 3.
+var pandocAPIVersion = []int{1, 23, 1}
+
+// pandocNode is an element of the Pandoc AST, e.g. a block or an inline. C is
+// omitted for constructors without arguments like Space.
+type pandocNode struct {
+	T string      `json:"t"`
+	C interface{} `json:"c,omitempty"`
+}
+
+// pandocDoc is a Pandoc document.
+type pandocDoc struct {
+	APIVersion []int                 `json:"pandoc-api-version"`
+	Meta       map[string]pandocNode `json:"meta"`
+	Blocks     []pandocNode          `json:"blocks"`
+}
+
+// pandocElem returns an element with constructor t and arguments c.
+func pandocElem(t string, c ...interface{}) pandocNode {
+	switch len(c) {
+	case 0:
+		return pandocNode{T: t}
+	case 1:
+		return pandocNode{T: t, C: c[0]}
+	}
+	return pandocNode{T: t, C: c}
+}
+
+// pandocAttr returns the attributes of an element with an id and classes.
+func pandocAttr(id string, classes ...string) []interface{} {
+	if classes == nil {
+		classes = []string{}
+	}
+	return []interface{}{id, classes, [][]string{}}
+}
+
+type pandocPrinter struct {
+	cfg *printConfig
+}
+
+func (p *pandocPrinter) printPage(page *notiontypes.Block) *pandocDoc {
+	doc := &pandocDoc{
+		APIVersion: pandocAPIVersion,
+		Meta:       map[string]pandocNode{},
+		Blocks:     p.blocks(page.Content),
+	}
+	if !p.cfg.noTitle {
+		title := append(p.text(page.Title), p.notes(page)...)
+		doc.Meta["title"] = pandocElem("MetaInlines", title)
+	}
+	return doc
+}
+
+// blocks converts blocks, grouping consecutive list items into lists.
+func (p *pandocPrinter) blocks(blocks []*notiontypes.Block) []pandocNode {
+	out := []pandocNode{}
+	for i := 0; i < len(blocks); {
+		b := blocks[i]
+		if !isListItem(b) {
+			out = append(out, p.block(b)...)
+			i++
+			continue
+		}
+		var items []interface{}
+		for ; i < len(blocks) && pandocListType(blocks[i]) == pandocListType(b); i++ {
+			items = append(items, p.listItem(blocks[i]))
+		}
+		if b.Type == notiontypes.BlockNumberedList {
+			attrs := []interface{}{1, pandocElem("Decimal"), pandocElem("Period")}
+			out = append(out, pandocElem("OrderedList", attrs, items))
+		} else {
+			out = append(out, pandocElem("BulletList", items))
+		}
+	}
+	return out
+}
+
+// pandocListType returns the type of list that a list item belongs to.
+// Bullets, to-dos and toggles share bullet lists.
+func pandocListType(b *notiontypes.Block) string {
+	if !isListItem(b) {
+		return ""
+	}
+	if b.Type == notiontypes.BlockNumberedList {
+		return "ordered"
+	}
+	return "bullet"
+}
+
+// listItem returns the blocks of a list item. To-dos start with a ballot
+// box, as in Pandoc's task lists.
+func (p *pandocPrinter) listItem(b *notiontypes.Block) []pandocNode {
+	text := append(p.inlines(b.InlineContent), p.notes(b)...)
+	if b.Type == notiontypes.BlockTodo {
+		box := "☐"
+		if b.IsChecked {
+			box = "☒"
+		}
+		text = append([]pandocNode{pandocElem("Str", box), pandocElem("Space")}, text...)
+	}
+	item := []pandocNode{pandocElem("Plain", text)}
+	return append(item, p.blocks(b.Content)...)
+}
+
+// para returns a paragraph of inlines, or nothing if there are none.
+func para(inlines []pandocNode) []pandocNode {
+	if len(inlines) == 0 {
+		return nil
+	}
+	return []pandocNode{pandocElem("Para", inlines)}
+}
+
+func (p *pandocPrinter) block(b *notiontypes.Block) []pandocNode {
+	text := append(p.inlines(b.InlineContent), p.notes(b)...)
+	switch b.Type {
+	case notiontypes.BlockPage, notiontypes.BlockAlias:
+		id, title := pageTitle(b)
+		return para([]pandocNode{p.link(p.cfg.pageURL(id), p.text(title))})
+	case notiontypes.BlockHeader, notiontypes.BlockSubHeader, notiontypes.BlockSubSubHeader:
+		out := []pandocNode{pandocElem("Header", b.HeaderLevel(), pandocAttr(b.ID), text)}
+		return append(out, p.blocks(b.Content)...)
+	case notiontypes.BlockQuote:
+		content := append(append([]pandocNode{}, para(text)...), p.blocks(b.Content)...)
+		return []pandocNode{pandocElem("BlockQuote", content)}
+	case notiontypes.BlockCallout:
+		if icon := pageIcon(b); icon != "" && !isURLIcon(icon) {
+			text = append([]pandocNode{pandocElem("Str", icon), pandocElem("Space")}, text...)
+		}
+		content := append(append([]pandocNode{}, para(text)...), p.blocks(b.Content)...)
+		return []pandocNode{pandocElem("Div", pandocAttr("", "callout"), content)}
+	case notiontypes.BlockCode:
+		var classes []string
+		if b.CodeLanguage != "" {
+			classes = append(classes, strings.ToLower(b.CodeLanguage))
+		}
+		return []pandocNode{pandocElem("CodeBlock", pandocAttr("", classes...), b.Code)}
+	case notiontypes.BlockEquation:
+		return para([]pandocNode{pandocElem("Math", pandocElem("DisplayMath"), b.Equation)})
+	case notiontypes.BlockDivider:
+		return []pandocNode{pandocElem("HorizontalRule")}
+	case notiontypes.BlockImage:
+		image := pandocElem("Image", pandocAttr(""), text, []string{p.cfg.imageURL(b), ""})
+		return para([]pandocNode{image})
+	case notiontypes.BlockBookmark:
+		if len(text) == 0 {
+			text = p.text(b.Link)
+		}
+		return para([]pandocNode{p.link(b.Link, text)})
+	case notiontypes.BlockFile, notiontypes.BlockPDF, notiontypes.BlockAudio,
+		notiontypes.BlockVideo, notiontypes.BlockEmbed, notiontypes.BlockGist:
+		u := p.cfg.fileURL(b)
+		if len(text) == 0 {
+			text = p.text(u)
+		}
+		return para([]pandocNode{p.link(u, text)})
+	case notiontypes.BlockTable:
+		return p.table(b)
+	case notiontypes.BlockTableOfContents, notiontypes.BlockBreadcrumb, notiontypes.BlockCollectionView:
+		// nothing to show
+		return nil
+	case notiontypes.BlockColumnList, notiontypes.BlockColumn,
+		notiontypes.BlockTransclusionContainer, notiontypes.BlockTransclusionReference:
+		return p.blocks(b.SyncedContent())
+	}
+	return append(para(text), p.blocks(b.Content)...)
+}
+
+// table converts a table block. The first row becomes the table head if the
+// table has a column header.
+func (p *pandocPrinter) table(b *notiontypes.Block) []pandocNode {
+	cols := 0
+	for _, row := range b.Content {
+		if len(row.Cells) > cols {
+			cols = len(row.Cells)
+		}
+	}
+	if cols == 0 {
+		return nil
+	}
+	colSpecs := make([]interface{}, cols)
+	for i := range colSpecs {
+		colSpecs[i] = []interface{}{pandocElem("AlignDefault"), pandocElem("ColWidthDefault")}
+	}
+	row := func(r *notiontypes.Block) []interface{} {
+		cells := make([]interface{}, cols)
+		for i := range cells {
+			var content []pandocNode
+			if i < len(r.Cells) {
+				if text := p.inlines(r.Cells[i]); len(text) > 0 {
+					content = []pandocNode{pandocElem("Plain", text)}
+				}
+			}
+			if content == nil {
+				content = []pandocNode{}
+			}
+			cells[i] = []interface{}{pandocAttr(""), pandocElem("AlignDefault"), 1, 1, content}
+		}
+		return []interface{}{pandocAttr(""), cells}
+	}
+	head, body := []interface{}{}, []interface{}{}
+	for i, r := range b.Content {
+		if i == 0 && b.FormatTable != nil && b.FormatTable.TableBlockColumnHeader {
+			head = append(head, row(r))
+		} else {
+			body = append(body, row(r))
+		}
+	}
+	caption := []interface{}{nil, []pandocNode{}}
+	return []pandocNode{pandocElem("Table",
+		pandocAttr(b.ID),
+		caption,
+		colSpecs,
+		[]interface{}{pandocAttr(""), head},
+		[]interface{}{[]interface{}{pandocAttr(""), 0, []interface{}{}, body}},
+		[]interface{}{pandocAttr(""), []interface{}{}},
+	)}
+}
+
+// notes returns discussions of a block as footnotes.
+func (p *pandocPrinter) notes(b *notiontypes.Block) []pandocNode {
+	var out []pandocNode
+	for _, d := range b.Discussions {
+		var content []pandocNode
+		for _, c := range d.Comments {
+			content = append(content, para(p.text(commentText(c)))...)
+		}
+		if len(content) > 0 {
+			out = append(out, pandocElem("Note", content))
+		}
+	}
+	return out
+}
+
+func (p *pandocPrinter) link(u string, text []pandocNode) pandocNode {
+	return pandocElem("Link", pandocAttr(""), text, []string{u, ""})
+}
+
+func (p *pandocPrinter) inlines(blocks []*notiontypes.InlineBlock) []pandocNode {
+	out := []pandocNode{}
+	for _, b := range blocks {
+		out = append(out, p.inline(b)...)
+	}
+	return out
+}
+
+func (p *pandocPrinter) inline(b *notiontypes.InlineBlock) []pandocNode {
+	var out []pandocNode
+	switch {
+	case b.IsEquation():
+		return []pandocNode{pandocElem("Math", pandocElem("InlineMath"), b.Equation)}
+	case b.IsPageMention():
+		return []pandocNode{p.link(p.cfg.pageURL(b.PageID), p.text(plainText(b)))}
+	case b.AttrFlags&notiontypes.AttrCode != 0:
+		out = []pandocNode{pandocElem("Code", pandocAttr(""), plainText(b))}
+	default:
+		out = p.text(plainText(b))
+	}
+	wrap := func(attr notiontypes.AttrFlag, t string) {
+		if b.AttrFlags&attr != 0 && len(out) > 0 {
+			out = []pandocNode{pandocElem(t, out)}
+		}
+	}
+	wrap(notiontypes.AttrBold, "Strong")
+	wrap(notiontypes.AttrItalic, "Emph")
+	wrap(notiontypes.AttrStrikeThrought, "Strikeout")
+	wrap(notiontypes.AttrUnderline, "Underline")
+	if b.Link != "" {
+		out = []pandocNode{p.link(b.Link, out)}
+	}
+	return out
+}
+
+// text splits s into words separated by spaces and line breaks.
+func (p *pandocPrinter) text(s string) []pandocNode {
+	out := []pandocNode{}
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			out = append(out, pandocElem("LineBreak"))
+		}
+		word := strings.Builder{}
+		flush := func() {
+			if word.Len() > 0 {
+				out = append(out, pandocElem("Str", word.String()))
+				word.Reset()
+			}
+		}
+		for _, r := range line {
+			if r == ' ' || r == '\t' {
+				flush()
+				if n := len(out); n == 0 || out[n-1].T != "Space" {
+					out = append(out, pandocElem("Space"))
+				}
+				continue
+			}
+			word.WriteRune(r)
+		}
+		flush()
+	}
+	return out
+}
+
+// PrintAsPandocJSON renders a notion page as a Pandoc JSON AST, which pandoc
+// reads with -f json to convert pages to any of its output formats. The
+// title of the page becomes the title in the metadata and discussions
+// become footnotes.
+func PrintAsPandocJSON(page *notiontypes.Block, opts ...PrintOption) ([]byte, error) {
+	p := &pandocPrinter{cfg: newPrintConfig(opts)}
+	out, err := json.Marshal(p.printPage(page))
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
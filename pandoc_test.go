@@ -0,0 +1,53 @@
+package notion
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPrintAsPandocJSON(t *testing.T) {
+	page := testPage(t, `[
+	{"id":"p","type":"page","properties":{"title":[["Notes"]]},"content":["h","t","b1","b2","n","c","q"]},
+	{"id":"h","type":"header","properties":{"title":[["Intro"]]}},
+	{"id":"t","type":"text","properties":{"title":[["a "],["bold",[["b"]]],[" "],["site",[["a","https://example.com"]]]]}},
+	{"id":"b1","type":"bulleted_list","properties":{"title":[["one"]]}},
+	{"id":"b2","type":"to_do","properties":{"title":[["two"]],"checked":[["Yes"]]}},
+	{"id":"n","type":"numbered_list","properties":{"title":[["first"]]}},
+	{"id":"c","type":"code","properties":{"title":[["x := 1"]],"language":[["Go"]]}},
+	{"id":"q","type":"quote","properties":{"title":[["said"]]}}
+	]`)
+	out, err := PrintAsPandocJSON(page.Block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		APIVersion []int `json:"pandoc-api-version"`
+		Meta       map[string]json.RawMessage
+		Blocks     []json.RawMessage
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.APIVersion) != 3 {
+		t.Errorf("api version = %v", doc.APIVersion)
+	}
+	if got, want := string(doc.Meta["title"]), `{"t":"MetaInlines","c":[{"t":"Str","c":"Notes"}]}`; got != want {
+		t.Errorf("title = %s, want %s", got, want)
+	}
+	want := []string{
+		`{"t":"Header","c":[1,["h",[],[]],[{"t":"Str","c":"Intro"}]]}`,
+		`{"t":"Para","c":[{"t":"Str","c":"a"},{"t":"Space"},{"t":"Strong","c":[{"t":"Str","c":"bold"}]},{"t":"Space"},{"t":"Link","c":[["",[],[]],[{"t":"Str","c":"site"}],["https://example.com",""]]}]}`,
+		`{"t":"BulletList","c":[[{"t":"Plain","c":[{"t":"Str","c":"one"}]}],[{"t":"Plain","c":[{"t":"Str","c":"☒"},{"t":"Space"},{"t":"Str","c":"two"}]}]]}`,
+		`{"t":"OrderedList","c":[[1,{"t":"Decimal"},{"t":"Period"}],[[{"t":"Plain","c":[{"t":"Str","c":"first"}]}]]]}`,
+		`{"t":"CodeBlock","c":[["",["go"],[]],"x := 1"]}`,
+		`{"t":"BlockQuote","c":[{"t":"Para","c":[{"t":"Str","c":"said"}]}]}`,
+	}
+	if len(doc.Blocks) != len(want) {
+		t.Fatalf("got %d blocks, want %d:\n%s", len(doc.Blocks), len(want), out)
+	}
+	for i, b := range doc.Blocks {
+		if string(b) != want[i] {
+			t.Errorf("block %d = %s\nwant %s", i, b, want[i])
+		}
+	}
+}
@@ -0,0 +1,366 @@
+package notion
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// defaultTextWidth is the line width of PrintAsText unless changed by
+// WithWidth.
+const defaultTextWidth = 80
+
+type textPrinter struct {
+	buf       *bytes.Buffer
+	cfg       *printConfig
+	root      *notiontypes.Block
+	prefix    string
+	footnotes []string
+	// ansi enables styles, see PrintAsANSI
+	ansi bool
+}
+
+// line writes s prefixed with the current prefix. Each line of a
+// multi-line s is prefixed.
+func (p *textPrinter) line(s string) {
+	for _, l := range strings.Split(s, "\n") {
+		p.buf.WriteString(strings.TrimRight(p.prefix+l, " "))
+		p.buf.WriteString("\n")
+	}
+}
+
+// wrapped writes text wrapped to the configured width, with marker before
+// the first line and continuation lines aligned after it.
+func (p *textPrinter) wrapped(marker string, text string) {
+	indent := strings.Repeat(" ", textWidth(marker))
+	width := 0
+	if p.cfg.width > 0 {
+		width = p.cfg.width - textWidth(p.prefix+marker)
+	}
+	for i, l := range wrapText(text, width) {
+		if i == 0 {
+			p.line(marker + l)
+		} else {
+			p.line(indent + l)
+		}
+	}
+}
+
+// nested prints blocks with prefix added to the current prefix. Nested
+// lists are kept tight, other content is separated by an empty line.
+func (p *textPrinter) nested(prefix string, blocks []*notiontypes.Block) {
+	if len(blocks) == 0 {
+		return
+	}
+	old := p.prefix
+	p.prefix += prefix
+	if !isListItem(blocks[0]) {
+		p.line("")
+	}
+	p.printBlocks(blocks)
+	p.prefix = old
+}
+
+// gutter returns the prefix of lines of quotes and callouts, which is drawn
+// as a styled bar with ANSI styles enabled.
+func (p *textPrinter) gutter(plain string, bar string, codes ...string) string {
+	if !p.ansi {
+		return plain
+	}
+	return p.style(bar, codes...)
+}
+
+// rule returns a horizontal line of c as wide as the text, or the
+// configured width.
+func (p *textPrinter) rule(c string, text string) string {
+	n := textWidth(text)
+	if text == "" {
+		n = p.cfg.width - textWidth(p.prefix)
+		if n <= 0 {
+			n = defaultTextWidth
+		}
+	}
+	return strings.Repeat(c, n)
+}
+
+func (p *textPrinter) printPage(page *notiontypes.Block) {
+	if !p.cfg.noTitle {
+		title := stripControl(page.Title)
+		if icon := pageIcon(page); icon != "" && !isURLIcon(icon) {
+			title = stripControl(icon) + " " + title
+		}
+		title += p.footnoteRefs(page)
+		p.line(p.style(title, ansiBold))
+		p.line(p.style(p.rule("=", title), ansiBold))
+		if len(page.Content) > 0 {
+			p.line("")
+		}
+	}
+	p.printBlocks(page.Content)
+	p.printFootnotes()
+}
+
+func (p *textPrinter) printFootnotes() {
+	if len(p.footnotes) == 0 {
+		return
+	}
+	p.line("")
+	for i, f := range p.footnotes {
+		p.wrapped(fmt.Sprintf("[%d] ", i+1), f)
+	}
+}
+
+func (p *textPrinter) printBlocks(blocks []*notiontypes.Block) {
+	num := 0
+	for i, b := range blocks {
+		if i > 0 && !(isListItem(b) && blocks[i-1].Type == b.Type) {
+			p.line("")
+		}
+		if b.Type == notiontypes.BlockNumberedList {
+			num++
+		} else {
+			num = 0
+		}
+		p.printBlock(b, num)
+	}
+}
+
+func (p *textPrinter) printBlock(b *notiontypes.Block, num int) {
+	text := p.inline(b.InlineContent, blockStyle(b)...) + p.footnoteRefs(b)
+	switch b.Type {
+	case notiontypes.BlockPage, notiontypes.BlockAlias:
+		id, title := pageTitle(b)
+		p.wrapped("", p.style(stripControl(title), ansiUnderline)+p.footnote(p.cfg.pageURL(id)))
+	case notiontypes.BlockHeader, notiontypes.BlockSubHeader, notiontypes.BlockSubSubHeader:
+		p.line(text)
+		switch b.HeaderLevel() {
+		case 1:
+			p.line(p.style(p.rule("=", text), blockStyle(b)...))
+		case 2:
+			p.line(p.style(p.rule("-", text), blockStyle(b)...))
+		}
+		p.nested("", b.Content)
+	case notiontypes.BlockBulletedList, notiontypes.BlockToggle:
+		p.wrapped("* ", text)
+		p.nested("  ", b.Content)
+	case notiontypes.BlockNumberedList:
+		marker := fmt.Sprintf("%d. ", num)
+		p.wrapped(marker, text)
+		p.nested(strings.Repeat(" ", len(marker)), b.Content)
+	case notiontypes.BlockTodo:
+		marker := "[ ] "
+		if b.IsChecked {
+			marker = p.style("[x]", "32") + " "
+		}
+		p.wrapped(marker, text)
+		p.nested("    ", b.Content)
+	case notiontypes.BlockQuote:
+		old := p.prefix
+		p.prefix += p.gutter("> ", "│ ", ansiDim)
+		p.wrapped("", text)
+		p.nested("", b.Content)
+		p.prefix = old
+	case notiontypes.BlockCallout:
+		marker := ""
+		if icon := pageIcon(b); icon != "" && !isURLIcon(icon) {
+			marker = stripControl(icon) + " "
+		}
+		gutter := ansiColor(blockColor(b))
+		if len(gutter) == 0 {
+			gutter = []string{"33"}
+		}
+		old := p.prefix
+		p.prefix += p.gutter("  ", "┃ ", gutter...)
+		p.wrapped(marker, text)
+		p.nested("", b.Content)
+		p.prefix = old
+	case notiontypes.BlockCode:
+		p.line("    " + strings.Replace(p.highlight(stripControl(b.Code), b.CodeLanguage), "\n", "\n    ", -1))
+	case notiontypes.BlockEquation:
+		p.line("    " + strings.Replace(stripControl(b.Equation), "\n", "\n    ", -1))
+	case notiontypes.BlockDivider:
+		p.line(p.rule("-", ""))
+	case notiontypes.BlockImage:
+		caption := "Image"
+		if text != "" {
+			caption = "Image: " + text
+		}
+		p.wrapped("", "["+caption+"]"+p.footnote(p.cfg.imageURL(b)))
+	case notiontypes.BlockBookmark:
+		if text == "" {
+			p.wrapped("", stripControl(b.Link))
+			break
+		}
+		p.wrapped("", text+p.footnote(b.Link))
+	case notiontypes.BlockFile, notiontypes.BlockPDF, notiontypes.BlockAudio,
+		notiontypes.BlockVideo, notiontypes.BlockEmbed, notiontypes.BlockGist:
+		u := p.cfg.fileURL(b)
+		if text == "" {
+			p.wrapped("", stripControl(u))
+			break
+		}
+		p.wrapped("", text+p.footnote(u))
+	case notiontypes.BlockTable:
+		p.printTable(b)
+	case notiontypes.BlockTableOfContents:
+		for _, h := range headers(p.root) {
+			indent := strings.Repeat("  ", h.HeaderLevel()-1)
+			p.wrapped(indent+"* ", stripControl(inlineText(h.InlineContent)))
+		}
+	case notiontypes.BlockBreadcrumb, notiontypes.BlockCollectionView:
+		// nothing to show
+	case notiontypes.BlockColumnList, notiontypes.BlockColumn,
+		notiontypes.BlockTransclusionContainer, notiontypes.BlockTransclusionReference:
+		p.printBlocks(b.SyncedContent())
+	default:
+		p.wrapped("", text)
+		p.nested("", b.Content)
+	}
+}
+
+// printTable prints a table as a grid with aligned columns.
+func (p *textPrinter) printTable(b *notiontypes.Block) {
+	var rows [][]string
+	var widths []int
+	for _, row := range b.Content {
+		var cells []string
+		for i, cell := range row.Cells {
+			s := strings.Replace(p.inline(cell), "\n", " ", -1)
+			cells = append(cells, s)
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := textWidth(s); n > widths[i] {
+				widths[i] = n
+			}
+		}
+		rows = append(rows, cells)
+	}
+	if len(rows) == 0 {
+		return
+	}
+	sep := func(c string) string {
+		var sb strings.Builder
+		for _, w := range widths {
+			sb.WriteString("+" + strings.Repeat(c, w+2))
+		}
+		return sb.String() + "+"
+	}
+	header := b.FormatTable != nil && b.FormatTable.TableBlockColumnHeader
+	p.line(sep("-"))
+	for i, r := range rows {
+		var sb strings.Builder
+		for j, w := range widths {
+			cell := ""
+			if j < len(r) {
+				cell = r[j]
+			}
+			sb.WriteString("| " + cell + strings.Repeat(" ", w-textWidth(cell)+1))
+		}
+		p.line(sb.String() + "|")
+		if i == 0 && header {
+			p.line(sep("="))
+		}
+	}
+	p.line(sep("-"))
+}
+
+// footnote records s as a footnote and returns a reference to it. Equal
+// footnotes share a number.
+func (p *textPrinter) footnote(s string) string {
+	if s == "" {
+		return ""
+	}
+	s = stripControl(s)
+	for i, f := range p.footnotes {
+		if f == s {
+			return p.style(fmt.Sprintf("[%d]", i+1), ansiDim)
+		}
+	}
+	p.footnotes = append(p.footnotes, s)
+	return p.style(fmt.Sprintf("[%d]", len(p.footnotes)), ansiDim)
+}
+
+// footnoteRefs records discussions of a block as footnotes and returns
+// references to them.
+func (p *textPrinter) footnoteRefs(b *notiontypes.Block) string {
+	var refs string
+	for _, d := range b.Discussions {
+		var comments []string
+		for _, c := range d.Comments {
+			comments = append(comments, commentText(c))
+		}
+		if len(comments) > 0 {
+			refs += p.footnote(strings.Join(comments, "; "))
+		}
+	}
+	return refs
+}
+
+// inline returns the text of inline blocks, styled with base styles in
+// addition to their own.
+func (p *textPrinter) inline(blocks []*notiontypes.InlineBlock, base ...string) string {
+	var sb strings.Builder
+	for _, b := range blocks {
+		sb.WriteString(p.style(stripControl(plainText(b)), append(base[:len(base):len(base)], inlineStyle(b)...)...))
+		switch {
+		case b.Link != "":
+			sb.WriteString(p.footnote(b.Link))
+		case b.IsPageMention():
+			sb.WriteString(p.footnote(p.cfg.pageURL(b.PageID)))
+		}
+	}
+	return sb.String()
+}
+
+// stripControl removes control characters other than line breaks and tabs
+// from text of a page, so that it can't send escape sequences to terminals.
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r != '\n' && r != '\t' && unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// wrapText splits text into lines of at most width runes, breaking at
+// spaces. Words longer than width are not broken. Line breaks in text are
+// kept. A width of 0 or less disables wrapping.
+func wrapText(text string, width int) []string {
+	if width <= 0 {
+		return strings.Split(text, "\n")
+	}
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		line, n := "", 0
+		for _, w := range strings.Fields(para) {
+			wn := textWidth(w)
+			if n > 0 && n+1+wn > width {
+				lines = append(lines, line)
+				line, n = "", 0
+			}
+			if n > 0 {
+				line += " "
+				n++
+			}
+			line += w
+			n += wn
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// PrintAsText renders a notion page as plain text. Paragraphs are wrapped to
+// the width set by WithWidth, 80 characters by default. Links and
+// discussions are rendered as numbered footnotes. Control characters other
+// than line breaks and tabs are removed, the output is safe for terminals.
+func PrintAsText(page *notiontypes.Block, opts ...PrintOption) ([]byte, error) {
+	p := &textPrinter{buf: new(bytes.Buffer), cfg: newPrintConfig(opts), root: page}
+	p.printPage(page)
+	return p.buf.Bytes(), nil
+}
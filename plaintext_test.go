@@ -0,0 +1,52 @@
+package notion
+
+import "testing"
+
+func TestPrintAsText(t *testing.T) {
+	page := testPage(t, `[
+	{"id":"p","type":"page","properties":{"title":[["Notes"]]},"content":["h","t","b1","b2","n1","c","tbl"]},
+	{"id":"h","type":"header","properties":{"title":[["Intro"]]}},
+	{"id":"t","type":"text","properties":{"title":[["a paragraph that is long enough to be wrapped, with a "],["link",[["a","https://example.com"]]]]}},
+	{"id":"b1","type":"bulleted_list","properties":{"title":[["one"]]},"content":["b1a"]},
+	{"id":"b1a","type":"to_do","properties":{"title":[["nested"]],"checked":[["Yes"]]}},
+	{"id":"b2","type":"bulleted_list","properties":{"title":[["two"]]}},
+	{"id":"n1","type":"numbered_list","properties":{"title":[["first"]]}},
+	{"id":"c","type":"code","properties":{"title":[["x := 1\nx++"]],"language":[["Go"]]}},
+	{"id":"tbl","type":"table","format":{"table_block_column_order":["a","b"],"table_block_column_header":true},"content":["r1","r2"]},
+	{"id":"r1","type":"table_row","properties":{"a":[["Name"]],"b":[["Count"]]}},
+	{"id":"r2","type":"table_row","properties":{"a":[["apples"]],"b":[["3"]]}}
+	]`)
+	got, err := PrintAsText(page.Block, WithWidth(30))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Notes\n" +
+		"=====\n" +
+		"\n" +
+		"Intro\n" +
+		"=====\n" +
+		"\n" +
+		"a paragraph that is long\n" +
+		"enough to be wrapped, with a\n" +
+		"link[1]\n" +
+		"\n" +
+		"* one\n" +
+		"  [x] nested\n" +
+		"* two\n" +
+		"\n" +
+		"1. first\n" +
+		"\n" +
+		"    x := 1\n" +
+		"    x++\n" +
+		"\n" +
+		"+--------+-------+\n" +
+		"| Name   | Count |\n" +
+		"+========+=======+\n" +
+		"| apples | 3     |\n" +
+		"+--------+-------+\n" +
+		"\n" +
+		"[1] https://example.com\n"
+	if string(got) != want {
+		t.Errorf("PrintAsText() =\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -3,6 +3,7 @@ package notion
 import (
 	"bytes"
 	"fmt"
+	"strings"
 
 	"github.com/tmc/notion/notiontypes"
 )
@@ -28,9 +29,29 @@ func (v *vimPrinter) print(block *notiontypes.Block) error {
 	v.incIndent()
 	//spew.Dump(block)
 	for _, b := range block.InlineContent {
-		v.W(b.Text)
+		v.W(plainText(b))
 	}
-	for _, b := range block.Content {
+	switch block.Type {
+	case notiontypes.BlockCode:
+		v.W(block.Code)
+	case notiontypes.BlockEquation:
+		v.W(block.Equation)
+	case notiontypes.BlockEmbed, notiontypes.BlockAudio, notiontypes.BlockPDF,
+		notiontypes.BlockVideo, notiontypes.BlockFile, notiontypes.BlockImage:
+		v.W(block.Source)
+	case notiontypes.BlockAlias:
+		if block.Reference != nil {
+			v.W(fmt.Sprintf("-> %v %v", block.Reference.Title, block.Reference.ID))
+		}
+	case notiontypes.BlockTableRow:
+		for _, cell := range block.Cells {
+			v.W(inlineText(cell))
+		}
+	}
+	for _, d := range block.Discussions {
+		v.printDiscussion(d)
+	}
+	for _, b := range block.SyncedContent() {
 		v.print(b)
 	}
 	v.decIndent()
@@ -38,6 +59,16 @@ func (v *vimPrinter) print(block *notiontypes.Block) error {
 	return nil
 }
 
+func (v *vimPrinter) printDiscussion(d *notiontypes.Discussion) {
+	v.W(fmt.Sprintf("discussion %v {{{", d.ID))
+	v.incIndent()
+	for _, c := range d.Comments {
+		v.W(commentText(c))
+	}
+	v.decIndent()
+	v.W("}}}")
+}
+
 func (v *vimPrinter) incIndent() {
 	v.indent += v.indentBy
 }
@@ -46,6 +77,42 @@ func (v *vimPrinter) decIndent() {
 	v.indent = string(v.indent[:len(v.indent)-len(v.indentBy)])
 }
 
+// inlineText returns the text of inline blocks without any formatting.
+func inlineText(blocks []*notiontypes.InlineBlock) string {
+	var sb strings.Builder
+	for _, b := range blocks {
+		sb.WriteString(plainText(b))
+	}
+	return sb.String()
+}
+
+// plainText returns the text of an inline block, substituting Notion's
+// placeholder characters for equations and page mentions.
+func plainText(b *notiontypes.InlineBlock) string {
+	switch {
+	case b.IsEquation():
+		return b.Equation
+	case b.Date != nil:
+		return b.Date.Format()
+	case b.UserID != "":
+		return mention(b)
+	case b.IsPageMention():
+		if b.PageTitle != "" {
+			return b.PageTitle
+		}
+		return b.PageID
+	}
+	return b.Text
+}
+
+// mention returns the text of a user mention, e.g. "@Given Family".
+func mention(b *notiontypes.InlineBlock) string {
+	if b.User != nil {
+		return "@" + b.User.Name()
+	}
+	return "@" + b.UserID
+}
+
 // PrintAsVim renders a notion block as a vim block.
 func PrintAsVim(block *notiontypes.Block, indent string) ([]byte, error) {
 	v := &vimPrinter{buf: new(bytes.Buffer), indentBy: indent}
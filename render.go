@@ -0,0 +1,241 @@
+package notion
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// PrintOption customizes rendering of pages by PrintAsMarkdown, PrintAsHTML,
+// PrintAsText and the other printers.
+type PrintOption func(*printConfig)
+
+type printConfig struct {
+	assetURL func(string) string
+	pageURL  func(string) string
+	noTitle  bool
+	width    int
+	xhtml    bool
+	wikiLink func(string) string
+	blockIDs bool
+}
+
+func newPrintConfig(opts []PrintOption) *printConfig {
+	cfg := &printConfig{
+		pageURL: PageURL,
+		width:   defaultTextWidth,
+	}
+	for _, o := range opts {
+		o(cfg)
+	}
+	return cfg
+}
+
+// WithAssetURLs allows rewriting urls of images, files, page covers and icons.
+// fn is called with the original url and returns the url to render instead.
+//
+// See AssetDownloader.URL for use with downloaded assets.
+func WithAssetURLs(fn func(url string) string) PrintOption {
+	return func(cfg *printConfig) {
+		cfg.assetURL = fn
+	}
+}
+
+// WithPageURLs allows customization of links to other pages, e.g. for
+// sub-pages, page mentions and links to pages. fn is called with the id of
+// the linked page. By default pages link to notion.so.
+func WithPageURLs(fn func(pageID string) string) PrintOption {
+	return func(cfg *printConfig) {
+		cfg.pageURL = fn
+	}
+}
+
+// WithoutTitle omits the title, icon and cover of the page, e.g. when they are
+// part of front matter instead.
+func WithoutTitle() PrintOption {
+	return func(cfg *printConfig) {
+		cfg.noTitle = true
+	}
+}
+
+// WithWidth sets the line width that PrintAsText wraps paragraphs to. A width
+// of 0 disables wrapping.
+func WithWidth(width int) PrintOption {
+	return func(cfg *printConfig) {
+		cfg.width = width
+	}
+}
+
+// WithWikiLinks makes PrintAsMarkdown write links to pages and blocks as
+// [[wiki links]], as used by Obsidian and Logseq. fn is called with the id of
+// the linked page and returns the link target, e.g. the path of the page in a
+// vault without extension, or "" to keep a regular link. Links to blocks
+// refer to the anchors written by WithBlockIDs.
+func WithWikiLinks(fn func(pageID string) string) PrintOption {
+	return func(cfg *printConfig) {
+		cfg.wikiLink = fn
+	}
+}
+
+// WithBlockIDs makes PrintAsMarkdown mark blocks with their ids as "^id"
+// anchors, so that links to blocks resolve in Obsidian.
+func WithBlockIDs() PrintOption {
+	return func(cfg *printConfig) {
+		cfg.blockIDs = true
+	}
+}
+
+// asXHTML makes PrintAsHTML write well-formed XML, e.g. for EPUB chapters.
+func asXHTML() PrintOption {
+	return func(cfg *printConfig) {
+		cfg.xhtml = true
+	}
+}
+
+// PageURL returns the notion.so url of a page.
+func PageURL(pageID string) string {
+	return "https://www.notion.so/" + strings.Replace(pageID, "-", "", -1)
+}
+
+// asset returns the rewritten url of an asset and true if it was rewritten.
+func (cfg *printConfig) asset(u string) (string, bool) {
+	if cfg.assetURL == nil || u == "" {
+		return u, false
+	}
+	rewritten := cfg.assetURL(u)
+	return rewritten, rewritten != u
+}
+
+// imageURL returns the url of a BlockImage.
+func (cfg *printConfig) imageURL(b *notiontypes.Block) string {
+	if u, ok := cfg.asset(b.Source); ok {
+		return u
+	}
+	if b.ImageURL != "" {
+		return b.ImageURL
+	}
+	return b.Source
+}
+
+// fileURL returns the url of a BlockFile and embeds.
+func (cfg *printConfig) fileURL(b *notiontypes.Block) string {
+	u, _ := cfg.asset(b.Source)
+	return u
+}
+
+// coverURL returns the url of a page cover or "" if the page has none.
+func (cfg *printConfig) coverURL(b *notiontypes.Block) string {
+	f := b.FormatPage
+	if f == nil || f.PageCover == "" {
+		return ""
+	}
+	if u, ok := cfg.asset(f.PageCover); ok {
+		return u
+	}
+	return f.PageCoverURL
+}
+
+// iconURL returns the url of an icon and "" if the icon is an emoji.
+func (cfg *printConfig) iconURL(icon string) string {
+	if !isURLIcon(icon) {
+		return ""
+	}
+	if u, ok := cfg.asset(icon); ok {
+		return u
+	}
+	return icon
+}
+
+// pageIcon returns the icon of a page or callout.
+func pageIcon(b *notiontypes.Block) string {
+	switch {
+	case b.FormatPage != nil:
+		return b.FormatPage.PageIcon
+	case b.FormatCallout != nil:
+		return b.FormatCallout.PageIcon
+	}
+	return ""
+}
+
+// isURLIcon returns true if icon is an url as opposed to an emoji.
+func isURLIcon(icon string) bool {
+	return strings.HasPrefix(icon, "http") || strings.HasPrefix(icon, "/")
+}
+
+// pageTitle returns the title of a sub-page or link to a page.
+func pageTitle(b *notiontypes.Block) (id string, title string) {
+	if b.Type == notiontypes.BlockAlias {
+		if b.Reference == nil {
+			if b.FormatAlias != nil && b.FormatAlias.AliasPointer != nil {
+				return b.FormatAlias.AliasPointer.ID, "Untitled"
+			}
+			return "", "Untitled"
+		}
+		b = b.Reference
+	}
+	if b.Title == "" {
+		return b.ID, "Untitled"
+	}
+	return b.ID, b.Title
+}
+
+// blockColor returns the color of a block, e.g. "red" or "gray_background".
+func blockColor(b *notiontypes.Block) string {
+	switch {
+	case b.FormatText != nil && b.FormatText.BlockColor != nil:
+		return *b.FormatText.BlockColor
+	case b.FormatCallout != nil && b.FormatCallout.BlockColor != nil:
+		return *b.FormatCallout.BlockColor
+	}
+	return ""
+}
+
+// isListItem returns true if consecutive blocks of b's type form a list.
+func isListItem(b *notiontypes.Block) bool {
+	switch b.Type {
+	case notiontypes.BlockBulletedList, notiontypes.BlockNumberedList, notiontypes.BlockTodo, notiontypes.BlockToggle:
+		return true
+	}
+	return false
+}
+
+// headers returns all headers in the content of a page, in order.
+func headers(page *notiontypes.Block) []*notiontypes.Block {
+	var result []*notiontypes.Block
+	notiontypes.InspectSynced(page, func(b *notiontypes.Block) bool {
+		if b.IsHeader() {
+			result = append(result, b)
+		}
+		return b == page || !b.IsPage()
+	})
+	return result
+}
+
+// Slugify returns a lower-case, dash-separated version of s suitable for
+// file names, urls and anchors.
+func Slugify(s string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return sb.String()
+}
+
+// commentText returns the text of a comment prefixed with its author.
+func commentText(c *notiontypes.Comment) string {
+	author := c.CreatedBy
+	if c.Author != nil {
+		author = c.Author.Name()
+	}
+	return author + ": " + inlineText(c.InlineContent)
+}
@@ -0,0 +1,190 @@
+package notion
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/notion/notiontypes"
+)
+
+// restoreRecord holds the fields of records that refer to other records.
+type restoreRecord struct {
+	Alive         bool     `json:"alive"`
+	ParentID      string   `json:"parent_id"`
+	ParentTable   string   `json:"parent_table"`
+	Content       []string `json:"content"`
+	CollectionID  string   `json:"collection_id"`
+	ViewIDs       []string `json:"view_ids"`
+	DiscussionIDs []string `json:"discussion"`
+	CommentIDs    []string `json:"comments"`
+}
+
+// uuidPattern matches ids of records, with or without dashes.
+var uuidPattern = regexp.MustCompile(`[0-9a-fA-F]{8}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{12}`)
+
+// restoreRef is a record to restore.
+type restoreRef struct {
+	table, id string
+	value     json.RawMessage
+}
+
+// Restore recreates pages of a backup with their content, sub-pages,
+// databases and discussions. Pages are restored as top-level pages of the
+// space with id spaceID, or as the last sub-pages of the page with id
+// parentID if it is not empty. If no pageIDs are given, the top-level pages
+// of all spaces of the backup are restored.
+//
+// Restored records get new ids, so that pages can be restored next to the
+// originals, and links between restored pages are updated. Users are not
+// restored, and files uploaded to notion.so are still referred to by their
+// original urls. Restore returns the new ids of restored records by their ids
+// in the backup.
+func (c *Client) Restore(b *BackupReader, spaceID string, parentID string, pageIDs ...string) (map[string]string, error) {
+	if len(pageIDs) == 0 {
+		for _, s := range b.Manifest.Spaces {
+			pageIDs = append(pageIDs, s.Pages...)
+		}
+	}
+	refs, err := collectRestoreRefs(b, pageIDs)
+	if err != nil {
+		return nil, err
+	}
+	newIDs := map[string]string{}
+	for _, r := range refs {
+		if _, ok := newIDs[r.id]; !ok {
+			newIDs[r.id] = NewID()
+		}
+	}
+	// replaceIDs replaces ids of restored records, which appear without
+	// dashes in urls of links
+	replaceIDs := func(s string) string {
+		return uuidPattern.ReplaceAllStringFunc(s, func(id string) string {
+			dashed, err := ParsePageID(id)
+			if err != nil {
+				return id
+			}
+			n, ok := newIDs[dashed]
+			if !ok {
+				return id
+			}
+			if !strings.Contains(id, "-") {
+				return strings.Replace(n, "-", "", -1)
+			}
+			return n
+		})
+	}
+
+	roots := map[string]bool{}
+	for _, id := range pageIDs {
+		roots[id] = true
+	}
+	var groups [][]*Operation
+	for _, r := range refs {
+		d := json.NewDecoder(strings.NewReader(replaceIDs(string(r.value))))
+		d.UseNumber()
+		var record map[string]interface{}
+		if err := d.Decode(&record); err != nil {
+			return nil, errors.Wrapf(err, "unmarshaling %s %s", r.table, r.id)
+		}
+		if _, ok := record["space_id"]; ok {
+			record["space_id"] = spaceID
+		}
+		ops := []*Operation{{ID: newIDs[r.id], Table: r.table, Path: []string{}, Command: CommandSet, Args: record}}
+		if r.table == notiontypes.TableBlock && roots[r.id] {
+			if parentID != "" {
+				record["parent_id"], record["parent_table"] = parentID, notiontypes.TableBlock
+				ops = append(ops, &Operation{ID: parentID, Table: notiontypes.TableBlock, Path: []string{"content"}, Command: CommandListAfter, Args: ListArgs{ID: newIDs[r.id]}})
+			} else {
+				record["parent_id"], record["parent_table"] = spaceID, notiontypes.TableSpace
+				ops = append(ops, &Operation{ID: spaceID, Table: notiontypes.TableSpace, Path: []string{"pages"}, Command: CommandListAfter, Args: ListArgs{ID: newIDs[r.id]}})
+			}
+		}
+		groups = append(groups, ops)
+	}
+	if err := c.submitInChunks(groups); err != nil {
+		return nil, errors.Wrap(err, "restoring pages")
+	}
+	return newIDs, nil
+}
+
+// collectRestoreRefs returns the records of pages of a backup and everything
+// they contain, with parents before their children.
+func collectRestoreRefs(b *BackupReader, pageIDs []string) ([]*restoreRef, error) {
+	// rows of collections, by collection id
+	rows := map[string][]string{}
+	for _, id := range b.RecordIDs(notiontypes.TableBlock) {
+		v, err := b.Record(notiontypes.TableBlock, id)
+		if err != nil {
+			return nil, err
+		}
+		var r restoreRecord
+		if err := json.Unmarshal(v, &r); err != nil {
+			return nil, errors.Wrapf(err, "unmarshaling block %s", id)
+		}
+		if r.Alive && r.ParentTable == notiontypes.TableCollection {
+			rows[r.ParentID] = append(rows[r.ParentID], id)
+		}
+	}
+
+	var refs []*restoreRef
+	seen := map[string]bool{}
+	var visit func(table string, id string) error
+	visit = func(table string, id string) error {
+		if seen[table+"/"+id] {
+			return nil
+		}
+		seen[table+"/"+id] = true
+		v, err := b.Record(table, id)
+		if err != nil || v == nil {
+			return err
+		}
+		var r restoreRecord
+		if err := json.Unmarshal(v, &r); err != nil {
+			return errors.Wrapf(err, "unmarshaling %s %s", table, id)
+		}
+		if !r.Alive {
+			return nil
+		}
+		refs = append(refs, &restoreRef{table: table, id: id, value: v})
+		var children [][2]string
+		if r.CollectionID != "" {
+			children = append(children, [2]string{notiontypes.TableCollection, r.CollectionID})
+		}
+		for _, id := range r.ViewIDs {
+			children = append(children, [2]string{notiontypes.TableCollectionView, id})
+		}
+		for _, id := range r.Content {
+			children = append(children, [2]string{notiontypes.TableBlock, id})
+		}
+		if table == notiontypes.TableCollection {
+			for _, id := range rows[id] {
+				children = append(children, [2]string{notiontypes.TableBlock, id})
+			}
+		}
+		for _, id := range r.DiscussionIDs {
+			children = append(children, [2]string{tableDiscussion, id})
+		}
+		if table == tableDiscussion {
+			for _, id := range r.CommentIDs {
+				children = append(children, [2]string{tableComment, id})
+			}
+		}
+		for _, c := range children {
+			if err := visit(c[0], c[1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, id := range pageIDs {
+		if _, ok := b.Manifest.Pages[id]; !ok {
+			return nil, errors.Errorf("notion: page %s is not in the backup", id)
+		}
+		if err := visit(notiontypes.TableBlock, id); err != nil {
+			return nil, err
+		}
+	}
+	return refs, nil
+}
@@ -0,0 +1,54 @@
+package notion
+
+import "time"
+
+// Commands used in Operation.Command.
+const (
+	// CommandSet replaces the value at Path with Args.
+	CommandSet = "set"
+	// CommandUpdate merges Args into the object at Path.
+	CommandUpdate = "update"
+	// CommandListAfter inserts Args.ID into the list at Path after Args.After
+	// (or at the end).
+	CommandListAfter = "listAfter"
+	// CommandListBefore inserts Args.ID into the list at Path before Args.Before
+	// (or at the start).
+	CommandListBefore = "listBefore"
+	// CommandListRemove removes Args.ID from the list at Path.
+	CommandListRemove = "listRemove"
+)
+
+// Operation is a single change to a record. Operations are applied atomically
+// as part of a transaction.
+type Operation struct {
+	ID      string      `json:"id"`
+	Table   string      `json:"table"`
+	Path    []string    `json:"path"`
+	Command string      `json:"command"`
+	Args    interface{} `json:"args"`
+}
+
+// ListArgs are the Args of list commands.
+type ListArgs struct {
+	ID     string `json:"id"`
+	After  string `json:"after,omitempty"`
+	Before string `json:"before,omitempty"`
+}
+
+type submitTransactionRequest struct {
+	Operations []*Operation `json:"operations"`
+}
+
+// SubmitTransaction applies the given operations.
+func (c *Client) SubmitTransaction(ops ...*Operation) error {
+	if len(ops) == 0 {
+		return nil
+	}
+	_, err := c.post(submitTransactionRequest{Operations: ops}, "submitTransaction")
+	return err
+}
+
+// now returns the current time in the format notion.so uses for timestamps.
+func now() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
@@ -5,6 +5,12 @@ import "github.com/tmc/notion/notiontypes"
 // Page is a notion.so page.
 type Page struct {
 	*notiontypes.Block
+
+	// Users referred to by blocks of the page, by id.
+	Users map[string]*notiontypes.User
+
+	// For rows of a collection (database), the collection the page belongs to.
+	Collection *notiontypes.Collection
 }
 
 // StackPosition refers to a position within a list of entities (usually blocks).
@@ -0,0 +1,51 @@
+package notion
+
+import (
+	"encoding/json"
+
+	"github.com/tmc/notion/notiontypes"
+)
+
+// GetUsers returns users with the given ids. Users that don't exist or are not
+// accessible are skipped.
+func (c *Client) GetUsers(ids ...string) ([]*notiontypes.User, error) {
+	var result []*notiontypes.User
+	err := c.getRecordsOf(tableUser, ids, func(v json.RawMessage) error {
+		u := &notiontypes.User{}
+		result = append(result, u)
+		return json.Unmarshal(v, u)
+	})
+	return result, err
+}
+
+// resolveUsers fetches users referred to by the page that are missing from rm
+// and resolves them.
+func (c *Client) resolveUsers(page *Page, rm *notiontypes.RecordMap) {
+	page.Users = make(map[string]*notiontypes.User, len(rm.Users))
+	for k, v := range rm.Users {
+		if v.Value != nil {
+			page.Users[k] = v.Value
+		}
+	}
+	ids := notiontypes.UserIDs(page.Block)
+	if page.Collection != nil {
+		// people in properties of database rows
+		ids = append(ids, collectionUserIDs(page.Collection, []*notiontypes.Block{page.Block})...)
+	}
+	var missing []string
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if _, ok := page.Users[id]; !ok && !seen[id] {
+			seen[id] = true
+			missing = append(missing, id)
+		}
+	}
+	users, err := c.GetUsers(missing...)
+	if err != nil {
+		c.logger.WithError(err).Warnln("loading users failed")
+	}
+	for _, u := range users {
+		page.Users[u.ID] = u
+	}
+	notiontypes.ResolveUsers(page.Block, page.Users)
+}